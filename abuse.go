@@ -0,0 +1,133 @@
+package privatecaptcha
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAbuseWindow is the default window of replay detection, see Configuration.AbuseThreshold
+	DefaultAbuseWindow = 1 * time.Minute
+	// maxAbuseOrigins limits the number of distinct origins tracked per window bucket
+	maxAbuseOrigins = 64
+	// topAbuseOrigins is the number of origins reported in AbuseSignal
+	topAbuseOrigins = 5
+)
+
+// OriginCount is the number of verifications of solutions obtained on the origin
+type OriginCount struct {
+	Origin string
+	Count  int
+}
+
+// AbuseSignal reports a spike of replayed solutions (codes.VerifiedBefore and codes.DuplicateSolutions), which is
+// often an early sign of replay attack, see Configuration.OnAbuseSignal
+type AbuseSignal struct {
+	// Window is the period, over which verifications were aggregated
+	Window time.Duration
+	// Count is the number of replayed solutions within Window
+	Count int
+	// Codes is the number of replayed solutions per result code
+	Codes map[VerifyCode]int
+	// TopOrigins are origins with the most replayed solutions, in descending order
+	TopOrigins []OriginCount
+}
+
+type abuseBucket struct {
+	count   int
+	codes   map[VerifyCode]int
+	origins map[string]int
+}
+
+// abuseDetector aggregates replayed solutions over a sliding window and fires a callback when they exceed threshold
+type abuseDetector struct {
+	threshold int
+	callback  func(ctx context.Context, signal AbuseSignal)
+	mu        sync.Mutex
+	window    *slidingWindow[abuseBucket]
+	firedAt   time.Time
+}
+
+func newAbuseDetector(cfg *Configuration) *abuseDetector {
+	window := cfg.AbuseWindow
+	if window <= 0 {
+		window = DefaultAbuseWindow
+	}
+
+	return &abuseDetector{
+		threshold: cfg.AbuseThreshold,
+		callback:  cfg.OnAbuseSignal,
+		window:    newSlidingWindow[abuseBucket](window),
+	}
+}
+
+func isReplayCode(code VerifyCode) bool {
+	return (code == VerifiedBeforeError) || (code == DuplicateSolutionsError)
+}
+
+// record accounts verification result and returns the signal if the threshold was exceeded
+func (d *abuseDetector) record(output *VerifyOutput, now time.Time) (AbuseSignal, bool) {
+	if (output == nil) || !isReplayCode(output.Code) {
+		return AbuseSignal{}, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	b := d.window.current(now)
+	if b.codes == nil {
+		b.codes = make(map[VerifyCode]int)
+		b.origins = make(map[string]int)
+	}
+	b.count++
+	b.codes[output.Code]++
+	if _, ok := b.origins[output.Origin]; ok || (len(b.origins) < maxAbuseOrigins) {
+		b.origins[output.Origin]++
+	}
+
+	// signal is fired at most once per window
+	if !d.firedAt.IsZero() && (now.Sub(d.firedAt) < d.window.window()) {
+		return AbuseSignal{}, false
+	}
+
+	signal := AbuseSignal{Window: d.window.window(), Codes: make(map[VerifyCode]int)}
+	origins := make(map[string]int)
+	d.window.each(now, func(b *abuseBucket) {
+		signal.Count += b.count
+		for code, count := range b.codes {
+			signal.Codes[code] += count
+		}
+		for origin, count := range b.origins {
+			origins[origin] += count
+		}
+	})
+
+	if signal.Count < d.threshold {
+		return AbuseSignal{}, false
+	}
+
+	for origin, count := range origins {
+		signal.TopOrigins = append(signal.TopOrigins, OriginCount{Origin: origin, Count: count})
+	}
+	slices.SortFunc(signal.TopOrigins, func(a, b OriginCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Origin, b.Origin)
+	})
+	signal.TopOrigins = signal.TopOrigins[:min(len(signal.TopOrigins), topAbuseOrigins)]
+
+	d.firedAt = now
+
+	return signal, true
+}
+
+// detectAbuse records verification result and notifies Configuration.OnAbuseSignal on replay spikes
+func (c *Client) detectAbuse(ctx context.Context, output *VerifyOutput) {
+	if signal, ok := c.abuse.record(output, time.Now()); ok {
+		c.abuse.callback(ctx, signal)
+	}
+}
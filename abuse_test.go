@@ -0,0 +1,75 @@
+package privatecaptcha
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAbuseDetector(t *testing.T) {
+	t.Parallel()
+
+	d := newAbuseDetector(&Configuration{AbuseThreshold: 3, AbuseWindow: 10 * time.Second})
+	now := time.Now()
+
+	outputs := []*VerifyOutput{
+		{Code: VerifiedBeforeError, Origin: "a.example.com"},
+		{Code: VerifyNoError, Origin: "b.example.com"},
+		{Code: DuplicateSolutionsError, Origin: "b.example.com"},
+		nil,
+	}
+	for i, output := range outputs {
+		if _, ok := d.record(output, now.Add(time.Duration(i)*time.Second)); ok {
+			t.Fatalf("Unexpected signal before threshold (%v)", i)
+		}
+	}
+
+	signal, ok := d.record(&VerifyOutput{Code: VerifiedBeforeError, Origin: "a.example.com"}, now.Add(5*time.Second))
+	if !ok {
+		t.Fatal("Expected signal after threshold")
+	}
+
+	if (signal.Count != 3) || (signal.Codes[VerifiedBeforeError] != 2) || (signal.Codes[DuplicateSolutionsError] != 1) {
+		t.Errorf("Unexpected signal: %+v", signal)
+	}
+
+	if (len(signal.TopOrigins) != 2) || (signal.TopOrigins[0] != OriginCount{Origin: "a.example.com", Count: 2}) {
+		t.Errorf("Unexpected top origins: %v", signal.TopOrigins)
+	}
+
+	// signal is fired once per window
+	if _, ok := d.record(&VerifyOutput{Code: VerifiedBeforeError}, now.Add(6*time.Second)); ok {
+		t.Error("Unexpected repeated signal within window")
+	}
+
+	// old replays slide out of the window
+	if _, ok := d.record(&VerifyOutput{Code: VerifiedBeforeError}, now.Add(30*time.Second)); ok {
+		t.Error("Unexpected signal after window expired")
+	}
+}
+
+func TestOnAbuseSignal(t *testing.T) {
+	t.Parallel()
+
+	signals := make(chan AbuseSignal, 1)
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&VerifyOutput{Success: false, Code: VerifiedBeforeError, Origin: "example.com"})
+	}, Configuration{AbuseThreshold: 2, OnAbuseSignal: func(ctx context.Context, signal AbuseSignal) {
+		signals <- signal
+	}})
+
+	for i := 0; i < 2; i++ {
+		client.Verify(context.TODO(), VerifyInput{Solution: "asdf"})
+	}
+
+	select {
+	case signal := <-signals:
+		if (signal.Count != 2) || (signal.Window != DefaultAbuseWindow) {
+			t.Errorf("Unexpected signal: %+v", signal)
+		}
+	default:
+		t.Error("Expected abuse signal")
+	}
+}
@@ -0,0 +1,128 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+const (
+	// adaptiveBackoffRatio is the multiplier applied to the concurrency limit when API rate limits requests
+	adaptiveBackoffRatio = 0.5
+)
+
+// AdaptiveLimiter limits the number of concurrent verify requests, adjusting the limit with AIMD (additive
+// increase, multiplicative decrease) from the API feedback: the limit grows slowly while requests succeed
+// and is cut in half when the API responds with http.StatusTooManyRequests. Share the same instance between
+// all clients using the same API key to find the sustainable rate for that key.
+type AdaptiveLimiter struct {
+	mu       sync.Mutex
+	limit    float64
+	minLimit float64
+	maxLimit float64
+	inFlight int
+	// changed is closed (and replaced) when a slot may have become available
+	changed     chan struct{}
+	rateLimited int64
+}
+
+// NewAdaptiveLimiter creates a limiter starting with initial concurrency limit, which is then adjusted between minLimit and maxLimit
+func NewAdaptiveLimiter(initial, minLimit, maxLimit int) *AdaptiveLimiter {
+	minLimit = max(1, minLimit)
+	maxLimit = max(minLimit, maxLimit)
+
+	return &AdaptiveLimiter{
+		limit:    float64(min(max(initial, minLimit), maxLimit)),
+		minLimit: float64(minLimit),
+		maxLimit: float64(maxLimit),
+		changed:  make(chan struct{}),
+	}
+}
+
+func (l *AdaptiveLimiter) notifyLocked() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// acquire waits until concurrency limit allows one more request or context is done
+func (l *AdaptiveLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	for {
+		l.mu.Lock()
+		if l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return contextError(ctx)
+		case <-changed:
+		}
+	}
+}
+
+// release frees the slot taken by acquire and adjusts the limit based on the result of the request
+func (l *AdaptiveLimiter) release(err error) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// only grow the limit if it's actually being used, otherwise it would grow unbounded under low load
+	utilized := 2*l.inFlight >= int(l.limit)
+	l.inFlight--
+
+	if code, ok := GetStatusCode(err); ok && (code == http.StatusTooManyRequests) {
+		l.rateLimited++
+		l.limit = max(l.minLimit, l.limit*adaptiveBackoffRatio)
+	} else if (err == nil) && utilized {
+		l.limit = min(l.maxLimit, l.limit+1/l.limit)
+	}
+
+	l.notifyLocked()
+}
+
+// Limit returns the current concurrency limit
+func (l *AdaptiveLimiter) Limit() int {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit)
+}
+
+// InFlight returns the number of verify requests in progress
+func (l *AdaptiveLimiter) InFlight() int {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.inFlight
+}
+
+// RateLimited returns the total number of requests rate limited by the API
+func (l *AdaptiveLimiter) RateLimited() int64 {
+	if l == nil {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.rateLimited
+}
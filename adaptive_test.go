@@ -0,0 +1,99 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveLimiterAIMD(t *testing.T) {
+	t.Parallel()
+
+	l := NewAdaptiveLimiter(8, 1, 10)
+	ctx := context.TODO()
+
+	for i := 0; i < 8; i++ {
+		if err := l.acquire(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l.release(retriableError{HTTPError{StatusCode: http.StatusTooManyRequests}})
+	if (l.Limit() != 4) || (l.RateLimited() != 1) {
+		t.Errorf("Unexpected limit after rate limiting: %v", l.Limit())
+	}
+
+	for i := 0; i < 7; i++ {
+		l.release(nil)
+	}
+
+	if l.InFlight() != 0 {
+		t.Errorf("Unexpected in flight: %v", l.InFlight())
+	}
+
+	for i := 0; i < 20; i++ {
+		n := l.Limit()
+		for j := 0; j < n; j++ {
+			l.acquire(ctx)
+		}
+		for j := 0; j < n; j++ {
+			l.release(nil)
+		}
+	}
+
+	if l.Limit() != 10 {
+		t.Errorf("Limit should grow up to the maximum: %v", l.Limit())
+	}
+}
+
+func TestAdaptiveLimiterWait(t *testing.T) {
+	t.Parallel()
+
+	l := NewAdaptiveLimiter(1, 1, 1)
+	if err := l.acquire(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		l.release(nil)
+	}()
+
+	if err := l.acquire(context.TODO()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestAdaptiveLimiterClient(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	limiter := NewAdaptiveLimiter(4, 1, 4)
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{Limiter: limiter})
+
+	output, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 2, MaxBackoffSeconds: 1})
+	if err != nil || !output.OK() {
+		t.Fatalf("Unexpected result: %v (%v)", output.Error(), err)
+	}
+
+	if (limiter.Limit() != 2) || (limiter.InFlight() != 0) {
+		t.Errorf("Unexpected limiter state: limit=%v inFlight=%v", limiter.Limit(), limiter.InFlight())
+	}
+}
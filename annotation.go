@@ -0,0 +1,118 @@
+package privatecaptcha
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAnnotationTimestampHeader is the default header with the signed timestamp of the verify attempt
+	DefaultAnnotationTimestampHeader = "X-PC-Annotation-Timestamp"
+	// DefaultAnnotationSignatureHeader is the default header with the signature of annotation
+	DefaultAnnotationSignatureHeader = "X-PC-Annotation-Signature"
+)
+
+var (
+	errAnnotationMissing   = errors.New("privatecaptcha: request annotation is missing")
+	errAnnotationSignature = errors.New("privatecaptcha: request annotation signature is invalid")
+	errAnnotationExpired   = errors.New("privatecaptcha: request annotation is expired")
+)
+
+// Annotation stamps every verify attempt with headers identifying the backend infrastructure (e.g. account ID)
+// and, optionally, a signed timestamp, so that WAF in front of the API (provider-side or self-hosted) can tell
+// legitimate backend traffic from abuse of a leaked API key. Signature is hex-encoded HMAC-SHA256 of
+// "<timestamp>\n<Header>:<value>\n..." over Headers sorted by canonical name, see VerifyAnnotation().
+type Annotation struct {
+	// (optional) Static headers added to every verify request, e.g. {"X-Account-ID": "acme"}
+	Headers map[string]string
+	// (optional) Key to sign timestamp and Headers with (requests are not signed if empty)
+	SigningKey []byte
+	// (optional) Header with the signed unix timestamp (defaults to DefaultAnnotationTimestampHeader)
+	TimestampHeader string
+	// (optional) Header with the signature (defaults to DefaultAnnotationSignatureHeader)
+	SignatureHeader string
+}
+
+func (a *Annotation) timestampHeader() string {
+	if len(a.TimestampHeader) > 0 {
+		return a.TimestampHeader
+	}
+
+	return DefaultAnnotationTimestampHeader
+}
+
+func (a *Annotation) signatureHeader() string {
+	if len(a.SignatureHeader) > 0 {
+		return a.SignatureHeader
+	}
+
+	return DefaultAnnotationSignatureHeader
+}
+
+func (a *Annotation) sign(timestamp string, header http.Header) string {
+	names := make([]string, 0, len(a.Headers))
+	for name := range a.Headers {
+		names = append(names, http.CanonicalHeaderKey(name))
+	}
+	slices.Sort(names)
+
+	var sb strings.Builder
+	sb.WriteString(timestamp)
+	for _, name := range names {
+		sb.WriteString("\n")
+		sb.WriteString(name)
+		sb.WriteString(":")
+		sb.WriteString(header.Get(name))
+	}
+
+	mac := hmac.New(sha256.New, a.SigningKey)
+	mac.Write([]byte(sb.String()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// apply adds annotation headers to the verify request
+func (a *Annotation) apply(header http.Header, now time.Time) {
+	for name, value := range a.Headers {
+		header.Set(name, value)
+	}
+
+	if len(a.SigningKey) == 0 {
+		return
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	header.Set(a.timestampHeader(), timestamp)
+	header.Set(a.signatureHeader(), a.sign(timestamp, header))
+}
+
+// VerifyAnnotation checks that request headers carry annotation signed with a.SigningKey not earlier than maxAge ago.
+// It is intended for WAF or reverse proxy in front of self-hosted version of Private Captcha.
+func (a *Annotation) VerifyAnnotation(header http.Header, maxAge time.Duration) error {
+	timestamp := header.Get(a.timestampHeader())
+	signature := header.Get(a.signatureHeader())
+	if (len(timestamp) == 0) || (len(signature) == 0) {
+		return errAnnotationMissing
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(a.sign(timestamp, header))) {
+		return errAnnotationSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errAnnotationSignature
+	}
+
+	if age := time.Since(time.Unix(unix, 0)); (age > maxAge) || (age < -maxAge) {
+		return errAnnotationExpired
+	}
+
+	return nil
+}
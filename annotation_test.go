@@ -0,0 +1,58 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAnnotation(t *testing.T) {
+	t.Parallel()
+
+	annotation := &Annotation{
+		Headers:    map[string]string{"x-account-id": "acme"},
+		SigningKey: []byte("secret"),
+	}
+
+	var requests atomic.Int32
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("X-Account-ID") != "acme" {
+			t.Errorf("Unexpected account header: %v", r.Header.Get("X-Account-ID"))
+		}
+		if err := annotation.VerifyAnnotation(r.Header, time.Minute); err != nil {
+			t.Errorf("Unexpected annotation error: %v", err)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, Configuration{Annotation: annotation})
+
+	// every attempt is annotated
+	client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 2, MaxBackoffSeconds: 1})
+	if requests.Load() != 2 {
+		t.Errorf("Unexpected requests count: %v", requests.Load())
+	}
+}
+
+func TestVerifyAnnotation(t *testing.T) {
+	t.Parallel()
+
+	annotation := &Annotation{Headers: map[string]string{"X-Account-ID": "acme"}, SigningKey: []byte("secret")}
+
+	header := http.Header{}
+	if err := annotation.VerifyAnnotation(header, time.Minute); err != errAnnotationMissing {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	annotation.apply(header, time.Now().Add(-time.Hour))
+	if err := annotation.VerifyAnnotation(header, time.Minute); err != errAnnotationExpired {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	annotation.apply(header, time.Now())
+	header.Set("X-Account-ID", "evil")
+	if err := annotation.VerifyAnnotation(header, time.Minute); err != errAnnotationSignature {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package privatecaptcha
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAttestationTTL is the default lifetime of verification attestations
+	DefaultAttestationTTL = 5 * time.Minute
+	attestationType       = "JWT"
+	attestationAlgorithm  = "HS256"
+	// attestationLeeway is the allowed clock skew between issuing and validating services
+	attestationLeeway = 30 * time.Second
+)
+
+var (
+	errAttestationFormat    = errors.New("privatecaptcha: invalid attestation format")
+	errAttestationSignature = errors.New("privatecaptcha: invalid attestation signature")
+	errAttestationExpired   = errors.New("privatecaptcha: attestation expired")
+	errAttestationClaims    = errors.New("privatecaptcha: attestation issuer or audience mismatch")
+	errAttestationKey       = errors.New("privatecaptcha: unknown attestation key")
+	errNoAttestationKeys    = errors.New("privatecaptcha: no attestation signing keys")
+)
+
+// Attestation configures signed JWT (HS256) attestations of successful verifications, see VerifyOutput.Attestation().
+// Downstream services validate them with ParseAttestation() and the same keys, so they can trust that the request
+// passed captcha without calling the API again.
+type Attestation struct {
+	// (required) Signing keys: the first one is used to sign new attestations, while all of them are accepted
+	// for validation (key ID is stored in "kid" header to support key rotation)
+	Keys []SigningKey
+	// (optional) Issuer ("iss" claim), checked by ParseAttestation() if not empty
+	Issuer string
+	// (optional) Audience ("aud" claim), checked by ParseAttestation() if not empty
+	Audience string
+	// (optional) Lifetime of attestations (defaults to DefaultAttestationTTL)
+	TTL time.Duration
+}
+
+// AttestationClaims are claims of the verification attestation
+type AttestationClaims struct {
+	Issuer   string `json:"iss,omitempty"`
+	Audience string `json:"aud,omitempty"`
+	// Subject is the sitekey of the verification (if it was provided)
+	Subject   string `json:"sub,omitempty"`
+	ID        string `json:"jti"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	// SolutionDigest is VerifyOutput.SolutionDigest() of the verification
+	SolutionDigest string `json:"sd,omitempty"`
+	Origin         string `json:"origin,omitempty"`
+}
+
+type attestationHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+func (a *Attestation) ttl() time.Duration {
+	if a.TTL > 0 {
+		return a.TTL
+	}
+
+	return DefaultAttestationTTL
+}
+
+func attestationSignature(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func encodeAttestationPart(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// issue returns a signed attestation of the successful verification
+func (a *Attestation) issue(output *VerifyOutput, sitekey string, now time.Time) (string, error) {
+	if len(a.Keys) == 0 {
+		return "", errNoAttestationKeys
+	}
+
+	key := a.Keys[0]
+
+	header, err := encodeAttestationPart(&attestationHeader{Alg: attestationAlgorithm, Typ: attestationType, Kid: key.ID})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := encodeAttestationPart(&AttestationClaims{
+		Issuer:         a.Issuer,
+		Audience:       a.Audience,
+		Subject:        sitekey,
+		ID:             rand.Text(),
+		IssuedAt:       now.Unix(),
+		ExpiresAt:      now.Add(a.ttl()).Unix(),
+		SolutionDigest: output.digest,
+		Origin:         output.Origin,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	payload := header + "." + claims
+
+	return payload + "." + attestationSignature(key.Key, payload), nil
+}
+
+func (c *Client) attest(ctx context.Context, output *VerifyOutput, sitekey string) {
+	if len(sitekey) == 0 {
+		sitekey = sitekeyFromContext(ctx)
+	}
+
+	token, err := c.attestation.issue(output, sitekey, time.Now())
+	if err != nil {
+		c.logger.Log(ctx, slog.LevelError, "Failed to issue verification attestation", errAttr(err))
+		return
+	}
+
+	output.attestation = token
+}
+
+func (a *Attestation) key(id string) ([]byte, bool) {
+	for _, key := range a.Keys {
+		if key.ID == id {
+			return key.Key, true
+		}
+	}
+
+	return nil, false
+}
+
+// ParseAttestation validates signature, expiration, issuer and audience of the attestation issued with the same
+// Attestation configuration (e.g. passed by an upstream service in a header) and returns its claims
+func ParseAttestation(token string, a *Attestation) (*AttestationClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errAttestationFormat
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errAttestationFormat
+	}
+
+	var header attestationHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, errAttestationFormat
+	}
+
+	// algorithm is fixed, so that "none" or asymmetric algorithms cannot be substituted
+	if header.Alg != attestationAlgorithm {
+		return nil, errAttestationSignature
+	}
+
+	key, ok := a.key(header.Kid)
+	if !ok {
+		return nil, errAttestationKey
+	}
+
+	expected := attestationSignature(key, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, errAttestationSignature
+	}
+
+	if data, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+		return nil, errAttestationFormat
+	}
+
+	claims := &AttestationClaims{}
+	if err := json.Unmarshal(data, claims); err != nil {
+		return nil, errAttestationFormat
+	}
+
+	now := time.Now()
+	if (now.Add(-attestationLeeway).Unix() >= claims.ExpiresAt) || (now.Add(attestationLeeway).Unix() < claims.IssuedAt) {
+		return nil, errAttestationExpired
+	}
+
+	if ((len(a.Issuer) > 0) && (claims.Issuer != a.Issuer)) || ((len(a.Audience) > 0) && (claims.Audience != a.Audience)) {
+		return nil, errAttestationClaims
+	}
+
+	return claims, nil
+}
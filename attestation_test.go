@@ -0,0 +1,97 @@
+package privatecaptcha
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAttestation(t *testing.T) {
+	t.Parallel()
+
+	current := SigningKey{ID: "k2", Key: []byte("current-key")}
+	previous := SigningKey{ID: "k1", Key: []byte("previous-key")}
+
+	issuer := &Attestation{Keys: []SigningKey{previous}, Issuer: "signup", Audience: "accounts"}
+	validator := &Attestation{Keys: []SigningKey{current, previous}, Issuer: "signup", Audience: "accounts"}
+
+	now := time.Now()
+	output := &VerifyOutput{Success: true, Origin: "example.com", digest: solutionDigest("asdf")}
+
+	token, err := issuer.issue(output, "sitekey", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := ParseAttestation(token, validator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (claims.Subject != "sitekey") || (claims.SolutionDigest != output.digest) || (claims.Origin != "example.com") || (len(claims.ID) == 0) {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+
+	expired, _ := issuer.issue(output, "", now.Add(-time.Hour))
+	parts := strings.Split(token, ".")
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"k1"}`)) + "." + parts[1] + "."
+
+	testCases := []struct {
+		token     string
+		validator *Attestation
+		err       error
+	}{
+		{"not-a-token", validator, errAttestationFormat},
+		{parts[0] + "." + parts[1] + ".AAAA", validator, errAttestationSignature},
+		{none, validator, errAttestationSignature},
+		{expired, validator, errAttestationExpired},
+		{token, &Attestation{Keys: []SigningKey{current}}, errAttestationKey},
+		{token, &Attestation{Keys: []SigningKey{previous}, Audience: "billing"}, errAttestationClaims},
+	}
+
+	for i, tc := range testCases {
+		if _, err := ParseAttestation(tc.token, tc.validator); err != tc.err {
+			t.Errorf("Unexpected error (%v): %v", i, err)
+		}
+	}
+}
+
+func TestClientAttestation(t *testing.T) {
+	t.Parallel()
+
+	var fail bool
+	attestation := &Attestation{Keys: []SigningKey{{ID: "k1", Key: []byte("secret")}}}
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.Write([]byte(`{"success":false,"code":3}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{Attestation: attestation})
+
+	output, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1, Sitekey: "sitekey"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := ParseAttestation(output.Attestation(), attestation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (claims.Subject != "sitekey") || (claims.SolutionDigest != output.SolutionDigest()) {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+
+	fail = true
+	if output, _ := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1}); len(output.Attestation()) > 0 {
+		t.Error("Unexpected attestation of failed verification")
+	}
+
+	if _, err := NewClient(Configuration{APIKey: "test-api-key", Attestation: &Attestation{}}); err != errNoAttestationKeys {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
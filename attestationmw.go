@@ -0,0 +1,112 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	// DefaultAttestationHeader is the default header carrying verification attestation between services
+	DefaultAttestationHeader = "X-PC-Attestation"
+)
+
+type attestationContextKey struct{}
+
+type attestationValue struct {
+	token  string
+	claims *AttestationClaims
+}
+
+// WithAttestation returns context carrying the attestation token (e.g. VerifyOutput.Attestation() in the edge
+// service), so that AttestationTransport forwards it with outgoing requests to internal services
+func WithAttestation(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, attestationContextKey{}, &attestationValue{token: token})
+}
+
+// AttestationFromContext returns claims of the attestation validated by Attestation.Middleware()
+func AttestationFromContext(ctx context.Context) (*AttestationClaims, bool) {
+	value, ok := ctx.Value(attestationContextKey{}).(*attestationValue)
+	if !ok || (value.claims == nil) {
+		return nil, false
+	}
+
+	return value.claims, true
+}
+
+func attestationToken(ctx context.Context) string {
+	if value, ok := ctx.Value(attestationContextKey{}).(*attestationValue); ok {
+		return value.token
+	}
+
+	return ""
+}
+
+// AttestationMiddlewareOptions configures Attestation.Middleware() and AttestationTransport()
+type AttestationMiddlewareOptions struct {
+	// (optional) Request header with the attestation, also used by AttestationTransport (defaults to DefaultAttestationHeader)
+	Header string
+	// (optional) http status to return for requests without valid attestation (defaults to http.StatusForbidden)
+	FailedStatusCode int
+}
+
+// Middleware validates attestation from the request header with ParseAttestation() and rejects requests without
+// a valid one. It is intended for internal services behind the edge service that verified captcha, so that they
+// trust the verification without calling the API again. Passed requests are marked with WithVerified() and carry
+// the attestation in context (see AttestationFromContext() and AttestationTransport).
+func (a *Attestation) Middleware(opts AttestationMiddlewareOptions) func(http.Handler) http.Handler {
+	if len(opts.Header) == 0 {
+		opts.Header = DefaultAttestationHeader
+	}
+
+	if opts.FailedStatusCode == 0 {
+		opts.FailedStatusCode = http.StatusForbidden
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := r.Header.Get(opts.Header)
+
+			claims, err := ParseAttestation(token, a)
+			if err != nil {
+				http.Error(w, http.StatusText(opts.FailedStatusCode), opts.FailedStatusCode)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), attestationContextKey{}, &attestationValue{token: token, claims: claims})
+			next.ServeHTTP(w, r.WithContext(WithVerified(ctx)))
+		})
+	}
+}
+
+type attestationTransport struct {
+	base   http.RoundTripper
+	header string
+}
+
+func (t *attestationTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	token := attestationToken(r.Context())
+	if (len(token) == 0) || (len(r.Header.Get(t.header)) > 0) {
+		return t.base.RoundTrip(r)
+	}
+
+	// RoundTripper must not modify the request
+	r = r.Clone(r.Context())
+	r.Header.Set(t.header, token)
+
+	return t.base.RoundTrip(r)
+}
+
+// AttestationTransport returns http.RoundTripper that forwards the attestation from request context (see
+// WithAttestation() and Attestation.Middleware()) in opts.Header to the next service, so the same options
+// should be used for the transport and the middleware of the next service. If base is nil, http.DefaultTransport is used.
+func AttestationTransport(base http.RoundTripper, opts AttestationMiddlewareOptions) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if len(opts.Header) == 0 {
+		opts.Header = DefaultAttestationHeader
+	}
+
+	return &attestationTransport{base: base, header: opts.Header}
+}
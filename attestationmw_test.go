@@ -0,0 +1,58 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAttestationMiddleware(t *testing.T) {
+	t.Parallel()
+
+	attestation := &Attestation{Keys: []SigningKey{{ID: "k1", Key: []byte("secret")}}, Audience: "internal"}
+	token, err := attestation.issue(&VerifyOutput{Success: true}, "sitekey", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// internal service validates the attestation in the same header the transport forwards it
+	opts := AttestationMiddlewareOptions{Header: "X-Internal-Attestation"}
+	internal := httptest.NewServer(attestation.Middleware(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := AttestationFromContext(r.Context())
+		if !ok || !IsVerified(r.Context()) || (claims.Subject != "sitekey") {
+			t.Errorf("Unexpected context: %+v", claims)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	defer internal.Close()
+
+	client := &http.Client{Transport: AttestationTransport(nil, opts)}
+
+	testCases := []struct {
+		token  string
+		status int
+	}{
+		{token, http.StatusNoContent},
+		{"", http.StatusForbidden},
+		{token + "x", http.StatusForbidden},
+	}
+
+	for i, tc := range testCases {
+		req, _ := http.NewRequestWithContext(WithAttestation(context.TODO(), tc.token), http.MethodGet, internal.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.status {
+			t.Errorf("Unexpected status (%v): %v", i, resp.StatusCode)
+		}
+
+		if len(req.Header.Get(opts.Header)) > 0 {
+			t.Error("Transport modified the original request")
+		}
+	}
+}
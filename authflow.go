@@ -0,0 +1,137 @@
+package privatecaptcha
+
+import (
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAuthStateParam is the default name of the form/query parameter with auth flow state
+	DefaultAuthStateParam = "state"
+	// DefaultAuthStateTTL is the default time during which verified auth state can be consumed
+	DefaultAuthStateTTL = 10 * time.Minute
+)
+
+var (
+	// ErrAuthStateMissing is returned when auth flow request has no state parameter
+	ErrAuthStateMissing = errors.New("privatecaptcha: auth state is missing")
+	// ErrAuthStateNotVerified is returned when auth state did not pass captcha verification, has expired or was already used
+	ErrAuthStateNotVerified = errors.New("privatecaptcha: auth state is not verified")
+)
+
+// AuthFlowGuard integrates captcha verification into login flows (login form POST of OAuth2/OIDC or SAML
+// identity providers). Successful verification is bound to the auth state parameter, which can then be
+// checked exactly once before issuing tokens, so that a captcha passed in one flow can't be replayed in another.
+// State is kept in memory, so guard should be shared by the login and token handlers of the same process.
+type AuthFlowGuard struct {
+	client     *Client
+	stateParam string
+	ttl        time.Duration
+	mu         sync.Mutex
+	verified   map[[sha256.Size]byte]time.Time
+}
+
+// AuthFlowOptions configures AuthFlowGuard
+type AuthFlowOptions struct {
+	// (optional) Name of the form or query parameter with auth state (defaults to DefaultAuthStateParam)
+	StateParam string
+	// (optional) How long verified state remains valid (defaults to DefaultAuthStateTTL)
+	TTL time.Duration
+}
+
+// NewAuthFlowGuard creates AuthFlowGuard using the client for verifications
+func NewAuthFlowGuard(client *Client, opts AuthFlowOptions) *AuthFlowGuard {
+	if len(opts.StateParam) == 0 {
+		opts.StateParam = DefaultAuthStateParam
+	}
+
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultAuthStateTTL
+	}
+
+	return &AuthFlowGuard{
+		client:     client,
+		stateParam: opts.StateParam,
+		ttl:        opts.TTL,
+		verified:   make(map[[sha256.Size]byte]time.Time),
+	}
+}
+
+func (g *AuthFlowGuard) cleanupLocked(now time.Time) {
+	for k, expiration := range g.verified {
+		if now.After(expiration) {
+			delete(g.verified, k)
+		}
+	}
+}
+
+// VerifyLogin verifies captcha solution of the login request and binds the result to its auth state
+func (g *AuthFlowGuard) VerifyLogin(r *http.Request) error {
+	state := r.FormValue(g.stateParam)
+	if len(state) == 0 {
+		return ErrAuthStateMissing
+	}
+
+	if err := g.client.VerifyRequest(r.Context(), r); err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.cleanupLocked(now)
+	g.verified[sha256.Sum256([]byte(state))] = now.Add(g.ttl)
+
+	return nil
+}
+
+// ConsumeState checks that auth state passed captcha verification and marks it as used. It is intended to be
+// called right before issuing tokens (e.g. in the token endpoint or in the authorization code callback).
+func (g *AuthFlowGuard) ConsumeState(state string) error {
+	if len(state) == 0 {
+		return ErrAuthStateMissing
+	}
+
+	key := sha256.Sum256([]byte(state))
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	expiration, ok := g.verified[key]
+	if !ok {
+		return ErrAuthStateNotVerified
+	}
+
+	delete(g.verified, key)
+
+	if time.Now().After(expiration) {
+		return ErrAuthStateNotVerified
+	}
+
+	return nil
+}
+
+// LoginMiddleware verifies captcha on login form submissions (POST requests) before passing them to the next handler
+func (g *AuthFlowGuard) LoginMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if err := g.VerifyLogin(r); err != nil {
+				status := g.client.failedStatusCode
+				if errors.Is(err, ErrAuthStateMissing) {
+					status = http.StatusBadRequest
+				}
+				http.Error(w, http.StatusText(status), status)
+				return
+			}
+
+			r = r.WithContext(WithVerified(r.Context()))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
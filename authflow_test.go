@@ -0,0 +1,58 @@
+package privatecaptcha
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestAuthFlowGuard(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "good" {
+			w.Write([]byte(`{"success":true,"code":0}`))
+		} else {
+			w.Write([]byte(`{"success":false,"code":3}`))
+		}
+	}, Configuration{})
+
+	guard := NewAuthFlowGuard(client, AuthFlowOptions{})
+	handler := guard.LoginMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	login := func(state, solution string) int {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.PostForm = url.Values{DefaultAuthStateParam: {state}, DefaultFormField: {solution}}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	if code := login("state-1", "good"); code != http.StatusOK {
+		t.Errorf("Unexpected status code: %v", code)
+	}
+
+	if code := login("state-2", "bad"); code != http.StatusForbidden {
+		t.Errorf("Unexpected status code: %v", code)
+	}
+
+	if code := login("", "good"); code != http.StatusBadRequest {
+		t.Errorf("Unexpected status code: %v", code)
+	}
+
+	if err := guard.ConsumeState("state-1"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// state can be consumed only once
+	if err := guard.ConsumeState("state-1"); err != ErrAuthStateNotVerified {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := guard.ConsumeState("state-2"); err != ErrAuthStateNotVerified {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
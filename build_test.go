@@ -0,0 +1,58 @@
+package privatecaptcha
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// goCommand runs go tool in the module root with extra environment, skipping the test if it's not available
+func goCommand(t *testing.T, env []string, args ...string) string {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("Skipping go toolchain test in short mode")
+	}
+
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain is not available")
+	}
+
+	cmd := exec.Command(gobin, args...)
+	cmd.Env = append(os.Environ(), env...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go %v failed: %v\n%s", strings.Join(args, " "), err, output)
+	}
+
+	return string(output)
+}
+
+// SDK must not require cgo, so that it works in scratch and distroless containers
+func TestNoCgoDependencies(t *testing.T) {
+	t.Parallel()
+
+	output := goCommand(t, []string{"CGO_ENABLED=1"}, "list", "-deps", "-test",
+		"-f", "{{if and (not .Standard) .CgoFiles}}{{.ImportPath}}{{end}}", "./...")
+
+	if packages := strings.TrimSpace(output); len(packages) > 0 {
+		t.Errorf("Unexpected packages with cgo:\n%v", packages)
+	}
+}
+
+func TestCGOFreeBuild(t *testing.T) {
+	t.Parallel()
+
+	goCommand(t, []string{"CGO_ENABLED=0", "GOOS=linux"}, "build", "./...")
+}
+
+// edge runtimes (e.g. Spin, wasmCloud, Cloudflare Workers) run Go compiled to WebAssembly
+func TestWASMBuild(t *testing.T) {
+	t.Parallel()
+
+	for _, goos := range []string{"js", "wasip1"} {
+		goCommand(t, []string{"GOOS=" + goos, "GOARCH=wasm"}, "build", "./...")
+	}
+}
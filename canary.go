@@ -0,0 +1,172 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCanaryInterval is the default interval between canary verifications
+	DefaultCanaryInterval = 1 * time.Minute
+	// DefaultCanaryTimeout is the default timeout of a single canary verification
+	DefaultCanaryTimeout = 10 * time.Second
+	// DefaultCanaryFailureThreshold is the default number of consecutive canary failures after which client is unhealthy
+	DefaultCanaryFailureThreshold = 3
+)
+
+var (
+	errNoCanary       = errors.New("privatecaptcha: canary is not configured")
+	errCanarySolution = errors.New("privatecaptcha: canary solution is empty")
+)
+
+// CanaryOptions configures periodic end-to-end self-test of the verification path, see Client.RunCanary()
+type CanaryOptions struct {
+	// (required) Solution obtained for a test property, which is accepted by the API with codes.TestProperty
+	// on every verification (a solution of a real property can be used, if it is not rejected when verified again)
+	Solution string
+	// (optional) Sitekey of the property of Solution
+	Sitekey string
+	// (optional) Interval between verifications (defaults to DefaultCanaryInterval)
+	Interval time.Duration
+	// (optional) Timeout of a single verification (defaults to DefaultCanaryTimeout)
+	Timeout time.Duration
+	// (optional) Number of consecutive failures after which Client.Healthy() reports false (defaults to DefaultCanaryFailureThreshold)
+	FailureThreshold int
+	// (optional) Hook called with the result of every verification, e.g. to record latency and success metrics
+	OnResult func(ctx context.Context, result CanaryResult)
+}
+
+// CanaryResult is the result of a single canary verification
+type CanaryResult struct {
+	Time    time.Time
+	Latency time.Duration
+	// OK is true if API responded with the expected result (codes.NoError or codes.TestProperty)
+	OK   bool
+	Code VerifyCode
+	Err  error
+}
+
+type canary struct {
+	opts     CanaryOptions
+	mu       sync.Mutex
+	last     CanaryResult
+	failures int
+}
+
+func newCanary(opts CanaryOptions) (*canary, error) {
+	if len(opts.Solution) == 0 {
+		return nil, errCanarySolution
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultCanaryInterval
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultCanaryTimeout
+	}
+
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = DefaultCanaryFailureThreshold
+	}
+
+	return &canary{opts: opts}, nil
+}
+
+func (cn *canary) record(result CanaryResult) {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+
+	cn.last = result
+	if result.OK {
+		cn.failures = 0
+	} else {
+		cn.failures++
+	}
+}
+
+func (cn *canary) healthy() bool {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+
+	return cn.failures < cn.opts.FailureThreshold
+}
+
+func (cn *canary) result() (CanaryResult, bool) {
+	cn.mu.Lock()
+	defer cn.mu.Unlock()
+
+	return cn.last, !cn.last.Time.IsZero()
+}
+
+// runCanary verifies canary solution once, bypassing fallback, policies and result hooks
+func (c *Client) runCanary(ctx context.Context) CanaryResult {
+	ctx, cancel := context.WithTimeout(ctx, c.canary.opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	output, err := c.verifyScoped(ctx, VerifyInput{Solution: c.canary.opts.Solution, Sitekey: c.canary.opts.Sitekey, Attempts: 1})
+
+	result := CanaryResult{Time: start, Latency: time.Since(start), Err: err}
+	if output != nil {
+		result.Code = output.Code
+	}
+	result.OK = (err == nil) && ((result.Code == VerifyNoError) || (result.Code == TestPropertyError))
+
+	return result
+}
+
+// RunCanary periodically verifies Configuration.Canary solution until ctx is done, so that health of the whole
+// verification path is known before users hit it (see Healthy()). It blocks, so usually it's started in a goroutine.
+func (c *Client) RunCanary(ctx context.Context) error {
+	if c.canary == nil {
+		return errNoCanary
+	}
+
+	ticker := time.NewTicker(c.canary.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		result := c.runCanary(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		c.canary.record(result)
+		if !result.OK {
+			c.logger.Log(ctx, c.alertLevel(slog.LevelWarn), "Canary verification failed", "code", result.Code.String(), "latency", result.Latency.String(), errAttr(result.Err))
+		}
+
+		if c.canary.opts.OnResult != nil {
+			c.canary.opts.OnResult(ctx, result)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Healthy returns false if the last Configuration.Canary verifications failed (see CanaryOptions.FailureThreshold).
+// It is always true if canary is not configured or is not running.
+func (c *Client) Healthy() bool {
+	if c.canary == nil {
+		return true
+	}
+
+	return c.canary.healthy()
+}
+
+// CanaryResult returns the result of the last canary verification (false if there was none yet)
+func (c *Client) CanaryResult() (CanaryResult, bool) {
+	if c.canary == nil {
+		return CanaryResult{}, false
+	}
+
+	return c.canary.result()
+}
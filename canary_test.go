@@ -0,0 +1,87 @@
+package privatecaptcha
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCanary(t *testing.T) {
+	t.Parallel()
+
+	var healthy atomic.Bool
+	healthy.Store(true)
+
+	results := make(chan CanaryResult)
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"success":false,"code":%d}`, TestPropertyError)
+	}, Configuration{Canary: &CanaryOptions{
+		Solution:         "canary.solution",
+		Interval:         10 * time.Millisecond,
+		FailureThreshold: 2,
+		OnResult: func(ctx context.Context, result CanaryResult) {
+			select {
+			case results <- result:
+			case <-ctx.Done():
+			}
+		},
+	}})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan error)
+	go func() { done <- client.RunCanary(ctx) }()
+
+	if result := <-results; !result.OK || (result.Code != TestPropertyError) || !client.Healthy() {
+		t.Errorf("Unexpected canary result: %v (%v)", result.Code, result.Err)
+	}
+
+	healthy.Store(false)
+
+	// single failure is tolerated
+	for result := <-results; result.OK; result = <-results {
+	}
+	if !client.Healthy() {
+		t.Error("Expected client to be healthy after a single failure")
+	}
+
+	if result := <-results; result.OK || client.Healthy() {
+		t.Error("Expected client to be unhealthy")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if last, ok := client.CanaryResult(); !ok || last.OK {
+		t.Errorf("Unexpected last result: %v", last)
+	}
+}
+
+func TestNoCanary(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(Configuration{APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.RunCanary(context.TODO()); err != errNoCanary {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if !client.Healthy() {
+		t.Error("Expected client without canary to be healthy")
+	}
+
+	if _, err := NewClient(Configuration{APIKey: "test-api-key", Canary: &CanaryOptions{}}); err != errCanarySolution {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+var (
+	// ErrChaos is the error of verify attempts failed by Configuration.UnsafeChaos
+	ErrChaos     = errors.New("privatecaptcha: verify attempt failed by chaos testing")
+	errChaosRate = errors.New("privatecaptcha: chaos rates must be between 0 and 1")
+)
+
+// Chaos configures fault injection into verifications for chaos (gameday) testing of degradation policies
+// (retries, Fallback, FailureMonitor etc.) in staging. It must never be enabled in production.
+type Chaos struct {
+	// (optional) Probability (0..1) of a verify attempt failing with ErrChaos, as if the API were unreachable
+	ErrorRate float64
+	// (optional) Probability (0..1) of a verify attempt being delayed by Delay
+	DelayRate float64
+	// (optional) Delay of verify attempts, see DelayRate
+	Delay time.Duration
+	// (optional) Probability (0..1) of flipping the reported outcome of verification (VerifyOutput.ReportedOK()),
+	// which is used in statistics (Client.Stats(), OnLowSuccessRate) and can be used by OnResult hooks. Results are
+	// only flipped in monitor-only mode (FailureMonitor) and the actual outcome (VerifyOutput.OK()) is never changed
+	FlipRate float64
+}
+
+func (ch *Chaos) validate() error {
+	for _, rate := range []float64{ch.ErrorRate, ch.DelayRate, ch.FlipRate} {
+		if (rate < 0) || (rate > 1) {
+			return errChaosRate
+		}
+	}
+
+	return nil
+}
+
+// faultInjector injects faults into verifications
+type faultInjector interface {
+	// attemptFault returns delay and error of the verify attempt
+	attemptFault(ctx context.Context) (time.Duration, error)
+	// flipResult returns true if the result of verification should be flipped
+	flipResult(ctx context.Context) bool
+}
+
+var _ faultInjector = (*Chaos)(nil)
+
+func chance(rate float64) bool {
+	return (rate > 0) && (rand.Float64() < rate)
+}
+
+func (ch *Chaos) attemptFault(ctx context.Context) (time.Duration, error) {
+	var delay time.Duration
+	if chance(ch.DelayRate) {
+		delay = ch.Delay
+	}
+
+	if chance(ch.ErrorRate) {
+		return delay, ErrChaos
+	}
+
+	return delay, nil
+}
+
+func (ch *Chaos) flipResult(ctx context.Context) bool {
+	return (failurePolicy(ctx, FailureReject) == FailureMonitor) && chance(ch.FlipRate)
+}
+
+// injectFault delays verify attempt and fails it according to the fault injector
+func (c *Client) injectFault(ctx context.Context) error {
+	delay, err := c.faults.attemptFault(ctx)
+	if delay > 0 {
+		c.logger.Log(ctx, levelTrace, "Injected delay of verify attempt", "delay", delay.String())
+		if serr := sleepContext(ctx, delay); serr != nil {
+			return serr
+		}
+	}
+
+	if err != nil {
+		c.logger.Log(ctx, levelTrace, "Injected failure of verify attempt", errAttr(err))
+	}
+
+	return err
+}
+
+// flipResult flips the reported outcome of verification (VerifyOutput.Flipped()) in monitor-only mode according
+// to the fault injector. The actual outcome (VerifyOutput.OK()) is never changed, so flipped results cannot mark
+// requests as verified, issue grace cookies or attestations.
+func (c *Client) flipResult(ctx context.Context, output *VerifyOutput) {
+	if !c.faults.flipResult(ctx) {
+		return
+	}
+
+	output.flipped = true
+
+	c.logger.Log(ctx, levelTrace, "Injected flip of reported verification result", "ok", output.OK(), "reported", output.ReportedOK())
+}
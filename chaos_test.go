@@ -0,0 +1,111 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChaosFailures(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	fallback := &stubProvider{}
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{
+		UnsafeChaos: &Chaos{ErrorRate: 1, DelayRate: 1, Delay: 20 * time.Millisecond},
+	})
+
+	start := time.Now()
+	_, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1})
+	if !errors.Is(err, ErrChaos) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if (time.Since(start) < 20*time.Millisecond) || (requests.Load() != 0) {
+		t.Errorf("Unexpected fault: %v requests in %v", requests.Load(), time.Since(start))
+	}
+
+	// injected failures are hard failures
+	withFallback := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{UnsafeChaos: &Chaos{ErrorRate: 1}, Fallback: fallback})
+
+	if output, err := withFallback.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1}); (err != nil) || !output.OK() || (fallback.calls.Load() != 1) {
+		t.Errorf("Unexpected result with fallback: %v (%v)", output.Error(), err)
+	}
+
+	if _, err := NewClient(Configuration{APIKey: "test-api-key", UnsafeChaos: &Chaos{FlipRate: 2}}); err != errChaosRate {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestChaosFlip(t *testing.T) {
+	t.Parallel()
+
+	var success atomic.Bool
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if success.Load() {
+			w.Write([]byte(`{"success":true,"code":0}`))
+			return
+		}
+		w.Write([]byte(`{"success":false,"code":3}`))
+	}, Configuration{UnsafeChaos: &Chaos{FlipRate: 1}})
+
+	// results are not flipped outside of monitor-only mode
+	if output, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf"}); (err != nil) || output.Flipped() || output.ReportedOK() {
+		t.Errorf("Unexpected result: %v (%v)", output.Error(), err)
+	}
+
+	// only the reported outcome is flipped
+	ctx := WithFailurePolicy(context.TODO(), FailureMonitor)
+	if output, err := client.Verify(ctx, VerifyInput{Solution: "asdf"}); (err != nil) || output.OK() || !output.Flipped() || !output.ReportedOK() {
+		t.Errorf("Result was not flipped: %v (%v)", output.Error(), err)
+	}
+
+	if stats := client.Stats(); (stats.Succeeded != 1) || (stats.Failed != 1) {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	var verified atomic.Bool
+	var called atomic.Bool
+	handler := client.Middleware(MiddlewareOptions{
+		FailurePolicy: FailureMonitor,
+		GraceCookie:   &GraceCookie{Key: []byte("grace-cookie-key")},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called.Store(true)
+		verified.Store(IsVerified(r.Context()))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(DefaultFormField+"=asdf"))
+	req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	// failed verification flipped to success is passed through in monitor-only mode, but is not verified
+	if (w.Code != http.StatusOK) || !called.Load() || verified.Load() {
+		t.Errorf("Unexpected response: %v (verified %v)", w.Code, verified.Load())
+	}
+
+	if cookies := w.Result().Cookies(); len(cookies) > 0 {
+		t.Errorf("Unexpected cookies: %v", cookies)
+	}
+
+	// successful verification flipped to failure is still verified
+	success.Store(true)
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(DefaultFormField+"=asdf"))
+	req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if (w.Code != http.StatusOK) || !verified.Load() {
+		t.Errorf("Unexpected response: %v (verified %v)", w.Code, verified.Load())
+	}
+}
@@ -2,13 +2,19 @@ package privatecaptcha
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/jpillora/backoff"
@@ -22,17 +28,56 @@ var (
 	headerRateLimit   = http.CanonicalHeaderKey("X-RateLimit-Limit")
 	headerContentType = http.CanonicalHeaderKey("Content-Type")
 	headerSitekey     = http.CanonicalHeaderKey("X-PC-Sitekey")
+	headerRegion      = http.CanonicalHeaderKey("X-PC-Region")
+	headerClientHints = http.CanonicalHeaderKey("X-PC-Client-Hints")
+	headerOrigin      = http.CanonicalHeaderKey("Origin")
+	headerAPIVersion  = http.CanonicalHeaderKey("X-API-Version")
 	errEmptyAPIKey    = errors.New("privatecaptcha: API key is empty")
 	errEmtpySolution  = errors.New("privatecaptcha: solution is empty")
+	errVerifyPath     = errors.New("privatecaptcha: verify path must be an absolute path without query or fragment")
+	// ErrSolutionTooLong is returned when solution exceeds configured maximum length
+	ErrSolutionTooLong = errors.New("privatecaptcha: solution is too long")
+	// ErrLoadShed wraps errors of verifications that were shed by the client because of Configuration.Limiter
+	// or Configuration.RetryBudget, so that application can respond with "try again later" instead of a generic error
+	ErrLoadShed = errors.New("privatecaptcha: verification was shed")
+	// ErrInvalidAPIKey is returned when API key was rejected by the API (it is typo'd, revoked or expired).
+	// Such verifications are not retried, as they will never succeed
+	ErrInvalidAPIKey = errors.New("privatecaptcha: API key is invalid")
+	// ErrAttemptTimeout is the cause of a single verify attempt exceeding VerifyInput.AttemptTimeout
+	ErrAttemptTimeout = errors.New("privatecaptcha: verify attempt timed out")
+)
+
+// PayloadFormat defines how the solution is encoded in the outgoing verify request body
+type PayloadFormat int
+
+const (
+	// PayloadRaw sends the solution as-is with text/plain content type (default)
+	PayloadRaw PayloadFormat = iota
+	// PayloadForm sends the solution as application/x-www-form-urlencoded "response" field
+	PayloadForm
+	// PayloadJSON sends the solution as {"solution": "..."} JSON object
+	PayloadJSON
+)
+
+const (
+	RegionGlobal     = "global"
+	RegionEU         = "eu"
+	RegionSelfHosted = "self-hosted"
 )
 
 const (
 	GlobalDomain     = "api.privatecaptcha.com"
 	EUDomain         = "api.eu.privatecaptcha.com"
 	DefaultFormField = "private-captcha-solution"
-	Version          = "0.0.6"
-	minBackoffMillis = 500
-	userAgent        = "private-captcha-go/" + Version
+	// DefaultVerifyPath is the path of the verify endpoint of the API
+	DefaultVerifyPath = "/verify"
+	Version           = "0.0.6"
+	// DefaultAPIVersion is the version of verify API this release of SDK is built against
+	DefaultAPIVersion = "1"
+	// DefaultMaxSolutionLength is the default limit for the solution size (in bytes)
+	DefaultMaxSolutionLength = 4096
+	minBackoffMillis         = 500
+	userAgent                = "private-captcha-go/" + Version
 )
 
 // HTTPError represents an error with an associated HTTP status code
@@ -46,6 +91,54 @@ func (e HTTPError) Error() string {
 	return fmt.Sprintf("privatecaptcha: HTTP error %d", e.StatusCode)
 }
 
+// OriginError is returned when Configuration.Origin is set, but solution was obtained for a different origin.
+// Usually this means that the puzzle was not fetched via the backend (or with a different synthetic origin),
+// or that the origin is missing from the allowed domains in property settings.
+type OriginError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *OriginError) Error() string {
+	return fmt.Sprintf("privatecaptcha: solution origin %q does not match configured origin %q", e.Actual, e.Expected)
+}
+
+// APIVersionError is returned when the API does not support the requested API version
+// (http.StatusNotAcceptable or http.StatusUpgradeRequired response)
+type APIVersionError struct {
+	HTTPError
+	// Requested is the API version sent by the client
+	Requested string
+	// Supported is the API version reported by the server (can be empty)
+	Supported string
+}
+
+func (e *APIVersionError) Error() string {
+	return fmt.Sprintf("privatecaptcha: API version %q is not supported (server version %q, HTTP status %d)", e.Requested, e.Supported, e.StatusCode)
+}
+
+func (e *APIVersionError) Unwrap() error {
+	return e.HTTPError
+}
+
+// APIKeyError is returned when the API responds with http.StatusUnauthorized. It matches ErrInvalidAPIKey
+// with errors.Is() and carries the HTTP status as HTTPError.
+type APIKeyError struct {
+	HTTPError
+}
+
+func (e *APIKeyError) Error() string {
+	return fmt.Sprintf("%v (HTTP status %d)", ErrInvalidAPIKey, e.StatusCode)
+}
+
+func (e *APIKeyError) Is(target error) bool {
+	return target == ErrInvalidAPIKey
+}
+
+func (e *APIKeyError) Unwrap() error {
+	return e.HTTPError
+}
+
 // GetStatusCode returns the HTTP status code if the error is an HTTPError
 func GetStatusCode(err error) (int, bool) {
 	var httpErr HTTPError
@@ -58,56 +151,452 @@ func GetStatusCode(err error) (int, bool) {
 type Configuration struct {
 	// (optional) Domain name when used with self-hosted version of Private Captcha
 	Domain string
-	// (required) API key created in Private Captcha account settings
+	// (optional) Path of the verify endpoint (defaults to DefaultVerifyPath), e.g. for compatibility endpoints of the
+	// self-hosted version. Can be overridden per call with VerifyInput.Path
+	VerifyPath string
+	// (required) API key created in Private Captcha account settings (unless EncryptedAPIKey is set)
 	APIKey string
+	// (optional) API keys of several organizations by scope (e.g. organization name or sitekey), selected for
+	// verifications with KeyResolver. APIKey is optional then and is used when the scope is not resolved
+	Keys map[string]string
+	// (optional) Selects the scope of Keys for verification (defaults to the scope equal to VerifyInput.Sitekey).
+	// If the scope is not resolved and the API responds with OrgScopeError, other keys are tried
+	KeyResolver KeyResolver
+	// (optional) API key encrypted at rest, it is decrypted with Decrypter once in NewClient and is passed to it as-is
+	// (e.g. age armored text or base64-encoded KMS ciphertext)
+	EncryptedAPIKey string
+	// (optional) Decrypter of EncryptedAPIKey
+	Decrypter Decrypter
 	// (optional) Custom form field to read puzzle solution from (only used for VerifyRequest helper)
 	FormField string
+	// (optional) Form fields to read puzzle solution from in order of priority, e.g. during migration from another
+	// captcha provider {DefaultFormField, "h-captcha-response"} (FormField is ignored if set)
+	FormFields []string
+	// (optional) Where VerifyRequest, VerifyFunc and Middleware read the solution from, e.g. Extractors{FromHeader("X-Captcha"),
+	// FromJSON("captcha.solution")} (defaults to FormField or FormFields of form or JSON body). MiddlewareOptions.Extractor takes precedence
+	Extractor Extractor
+	// (optional) Read the solution from this request header instead of the form, e.g. NativeSolutionHeader
+	// (ignored if Extractor is set)
+	SolutionHeader string
 	// (optional) Custom http.Client to use with requests
 	Client *http.Client
+	// (optional) Custom HTTP client to use with requests instead of http.Client (e.g. instrumented client of another
+	// library, HTTP API of the host of wasip1 edge runtime or a fake in unit tests). It cannot be used together with
+	// Client or transport options.
+	Doer Doer
 	// (optional) http status to return for failed verifications (defaults to http.StatusForbidden)
 	FailedStatusCode int
+	// (optional) Format of the verify request body (defaults to PayloadRaw)
+	PayloadFormat PayloadFormat
+	// (optional) Maximum solution length in bytes, longer solutions are rejected before sending (defaults to DefaultMaxSolutionLength)
+	MaxSolutionLength int
+	// (optional) Region label of the API endpoint (defaults to a value derived from Domain)
+	Region string
+	// (optional) How to verify multiple solutions sent in the same form field (defaults to SolutionsFirst)
+	SolutionsPolicy SolutionsPolicy
+	// (optional) Maximum number of solutions verified per request with SolutionsAll or SolutionsAny, requests with more
+	// solutions are rejected before sending (defaults to DefaultMaxSolutions)
+	MaxSolutions int
+	// (optional) Minimum TLS version of the default http client, e.g. tls.VersionTLS13 (defaults to tls.VersionTLS12)
+	TLSMinVersion uint16
+	// (optional) TLS 1.2 cipher suites of the default http client (defaults to Go's secure defaults)
+	TLSCipherSuites []uint16
+	// (optional) Restrict TLS 1.2 cipher suites and curves of the default http client to FIPS-approved ones
+	TLSPreferFIPS bool
+	// (optional) Hook to choose a proxy per verify request of the default http client (defaults to proxy from environment)
+	ProxySelector ProxySelector
+	// (optional) Root certificates of the default http client (defaults to system ones), see LoadRootCAs()
+	RootCAs *x509.CertPool
+	// (optional) Address family used by the default http client to connect to the API (defaults to IPFamilyAny),
+	// e.g. IPFamilyIPv4 on networks with broken IPv6 (ignored on js/wasm and wasip1)
+	IPFamily IPFamily
+	// (optional) Delay before the default http client races the fallback address family ("Happy Eyeballs")
+	// when IPFamily is IPFamilyAny (defaults to 300ms of net.Dialer, negative value disables the fallback)
+	DialFallbackDelay time.Duration
+	// (optional) Process-wide budget for retries, shared between clients (retries are not limited by default)
+	RetryBudget *RetryBudget
+	// (optional) Duration of a single verify attempt above which OnSlowCall is invoked
+	SlowCallThreshold time.Duration
+	// (optional) Hook invoked when verify attempt takes longer than SlowCallThreshold
+	OnSlowCall func(ctx context.Context, info SlowCallInfo)
+	// (optional) Secondary provider used when this client fails hard (network errors, service unavailable)
+	Fallback Provider
+	// (optional) How long to stick to Fallback after a hard failure before retrying primary (defaults to DefaultFailoverDuration)
+	FailoverDuration time.Duration
+	// (optional) CIDRs or IP addresses of reverse proxies, whose Forwarded and X-Forwarded-For headers are trusted
+	// by Client.RealIP(), e.g. []string{"10.0.0.0/8", "127.0.0.1"}
+	TrustedProxies []string
+	// (optional) Synthetic Origin sent with API requests, e.g. when backend proxies puzzles for native mobile apps.
+	// It should be one of the allowed domains in property settings
+	Origin string
+	// (optional) Record per-phase timings (DNS, connect, TLS, TTFB) of verify requests, see VerifyOutput.Timings()
+	TraceTimings bool
+	// (optional) Cost attribution tag (e.g. product or feature name) sent with verify requests, so that API usage
+	// can be attributed when reviewing the bill. It can be overridden with WithCostTag() or VerifyInput.CostTag,
+	// see Client.UsageByTag()
+	CostTag string
+	// (optional) Hook invoked with the result of every Verify() call
+	OnResult func(ctx context.Context, output *VerifyOutput, err error)
+	// (optional) Hook invoked with raw HTTP response of every verify attempt (including failed ones), e.g. to forward
+	// headers of the API or to read response fields not modelled by VerifyOutput. Response body is a copy
+	OnResponse func(ctx context.Context, resp *http.Response)
+	// (optional) Planned maintenance windows of the captcha service, see Client.AddMaintenanceWindow()
+	MaintenanceWindows []MaintenanceWindow
+	// (optional) Domain of the warm standby self-hosted deployment, see Client.Promote()
+	StandbyDomain string
+	// (optional) Promote standby automatically when current endpoint fails hard and standby is healthy
+	AutoPromoteStandby bool
+	// (optional) How often health of standby is checked in background after Client.Start() and how long the result
+	// of the check is reused (defaults to DefaultStandbyCheckInterval)
+	StandbyCheckInterval time.Duration
+	// (optional) Custom health check of the standby endpoint (defaults to HEAD request without server error)
+	StandbyHealthCheck StandbyHealthCheck
+	// (optional) Version of verify API sent with every request (defaults to DefaultAPIVersion)
+	APIVersion string
+	// (optional) Adaptive limit of concurrent verify requests, shared between clients (not limited by default)
+	Limiter *AdaptiveLimiter
+	// (optional) Hook invoked when verification is shed because of Limiter or RetryBudget
+	OnLoadShed func(ctx context.Context, info LoadShedInfo)
+	// (optional) Hook invoked when Middleware accepts a recently expired grace cookie because the API is in maintenance
+	// mode (see GraceCookie.StaleTTL)
+	OnStaleGrace func(ctx context.Context, info StaleGraceInfo)
+	// (optional) Policies applied to every verification result before the final decision, e.g.
+	// Policies{OriginPolicy("example.com"), MaxAgePolicy(time.Minute)}
+	Policies Policies
+	// (optional) Headers identifying backend infrastructure (and signed timestamp) added to every verify attempt
+	// for WAF allowlisting
+	Annotation *Annotation
+	// (optional) Issue signed JWT attestations of successful verifications, see VerifyOutput.Attestation()
+	Attestation *Attestation
+	// (optional) Inject faults into verifications for chaos testing. UNSAFE: only for staging environments
+	UnsafeChaos *Chaos
+	// (optional) Validate signatures of verify responses (for self-hosted deployments that sign them)
+	ResponseSignature *ResponseSignature
+	// (optional) Number of replayed solutions (codes.VerifiedBefore and codes.DuplicateSolutions) within AbuseWindow,
+	// after which OnAbuseSignal is called (replay detection is disabled if zero)
+	AbuseThreshold int
+	// (optional) Window of replay detection (defaults to DefaultAbuseWindow)
+	AbuseWindow time.Duration
+	// (optional) Hook called (at most once per AbuseWindow) when replayed solutions exceed AbuseThreshold
+	OnAbuseSignal func(ctx context.Context, signal AbuseSignal)
+	// (optional) Window of verification statistics reported by Client.Stats() (defaults to DefaultStatsWindow)
+	StatsWindow time.Duration
+	// (optional) Success rate (0..1) within StatsWindow, below which OnLowSuccessRate is called (disabled if zero).
+	// Sudden drop of success rate is often the first sign of widget breakage after a frontend deploy.
+	SuccessRateThreshold float64
+	// (optional) Number of verifications within StatsWindow required to evaluate SuccessRateThreshold
+	// (defaults to DefaultSuccessRateMinSamples)
+	SuccessRateMinSamples int
+	// (optional) Hook called once when success rate drops below SuccessRateThreshold (again after it recovers)
+	OnLowSuccessRate func(ctx context.Context, info SuccessRateInfo)
+	// (optional) Validate API key with a verify request in Client.Start(), which fails with ErrInvalidAPIKey
+	// if the key was rejected, so that misconfigured deployment crashes on start instead of failing every verification
+	ValidateAPIKeyOnStart bool
+	// (optional) Logger of the client, most messages are logged at trace level slog.Level(-8) (defaults to discarding
+	// all messages, so that SDK does not write to the global slog.Default() logger)
+	Logger *slog.Logger
+	// (optional) Periodic end-to-end self-test of the verification path, run by Client.Start() or Client.RunCanary()
+	Canary *CanaryOptions
 }
 
 type Client struct {
-	endpoint         string
+	endpoint         atomic.Pointer[string]
+	standby          *standby
 	apiKey           string
+	keys             *scopedKeys
 	formField        string
+	formFields       []string
+	extractor        Extractor
 	failedStatusCode int
-	client           *http.Client
+	payloadFormat    PayloadFormat
+	maxSolutionLen   int
+	region           string
+	apiVersion       string
+	solutionsPolicy  SolutionsPolicy
+	maxSolutions     int
+	retryBudget      *RetryBudget
+	limiter          *AdaptiveLimiter
+	onLoadShed       func(ctx context.Context, info LoadShedInfo)
+	onStaleGrace     func(ctx context.Context, info StaleGraceInfo)
+	policies         Policies
+	shedCount        atomic.Int64
+	staleGraceCount  atomic.Int64
+	slowThreshold    time.Duration
+	onSlowCall       func(ctx context.Context, info SlowCallInfo)
+	failover         *failover
+	origin           string
+	trustedProxies   []netip.Prefix
+	stats            *transportStats
+	defaultCostTag   string
+	usage            costUsage
+	traceTimings     bool
+	onResult         func(ctx context.Context, output *VerifyOutput, err error)
+	onResponse       func(ctx context.Context, resp *http.Response)
+	maintenance      *maintenance
+	canary           *canary
+	keyValidity      keyValidity
+	annotation       *Annotation
+	signature        *ResponseSignature
+	attestation      *Attestation
+	logger           *slog.Logger
+	abuse            *abuseDetector
+	success          *successTracker
+	validateKey      bool
+	faults           faultInjector
+	verifyPath       string
+	lifecycle        lifecycle
+	ownsClient       bool
+	client           Doer
 }
 
 // NewClient creates a new instance of Private Captcha API client
 func NewClient(cfg Configuration) (*Client, error) {
-	if len(cfg.APIKey) == 0 {
+	if err := cfg.decryptAPIKey(context.Background()); err != nil {
+		return nil, err
+	}
+
+	if (len(cfg.APIKey) == 0) && (len(cfg.Keys) == 0) {
 		return nil, errEmptyAPIKey
 	}
 
+	for _, key := range cfg.Keys {
+		if len(key) == 0 {
+			return nil, errEmptyAPIKey
+		}
+	}
+
 	if len(cfg.Domain) == 0 {
 		cfg.Domain = GlobalDomain
-	} else if strings.HasPrefix(cfg.Domain, "http") {
-		cfg.Domain = strings.TrimPrefix(cfg.Domain, "https://")
-		cfg.Domain = strings.TrimPrefix(cfg.Domain, "http://")
+	} else {
+		cfg.Domain = trimScheme(cfg.Domain)
+	}
+
+	if (cfg.ResponseSignature != nil) && (len(cfg.ResponseSignature.Keys) == 0) {
+		return nil, errNoResponseKeys
+	}
+
+	if (cfg.Attestation != nil) && (len(cfg.Attestation.Keys) == 0) {
+		return nil, errNoAttestationKeys
+	}
+
+	if len(cfg.VerifyPath) == 0 {
+		cfg.VerifyPath = DefaultVerifyPath
+	} else if !validVerifyPath(cfg.VerifyPath) {
+		return nil, errVerifyPath
 	}
 
-	if cfg.Client == nil {
-		cfg.Client = http.DefaultClient
+	if cfg.Doer != nil {
+		if cfg.Client != nil {
+			return nil, errDoerWithClient
+		}
+		if cfg.hasTransportOptions() {
+			return nil, errTransportWithClient
+		}
+	} else if cfg.Client == nil {
+		if cfg.hasTransportOptions() {
+			cfg.Client = newDefaultClient(&cfg)
+		} else {
+			cfg.Client = http.DefaultClient
+		}
+	} else if cfg.hasTransportOptions() {
+		return nil, errTransportWithClient
+	}
+
+	if len(cfg.FormFields) > 0 {
+		cfg.FormField = cfg.FormFields[0]
+	} else {
+		if len(cfg.FormField) == 0 {
+			cfg.FormField = DefaultFormField
+		}
+		cfg.FormFields = []string{cfg.FormField}
 	}
 
-	if len(cfg.FormField) == 0 {
-		cfg.FormField = DefaultFormField
+	if (cfg.Extractor == nil) && (len(cfg.SolutionHeader) > 0) {
+		cfg.Extractor = FromHeader(cfg.SolutionHeader)
 	}
 
 	if cfg.FailedStatusCode == 0 {
 		cfg.FailedStatusCode = http.StatusForbidden
 	}
 
-	return &Client{
-		endpoint:         fmt.Sprintf("https://%s/verify", strings.Trim(cfg.Domain, "/")),
+	if len(cfg.Region) == 0 {
+		cfg.Region = regionFromDomain(cfg.Domain)
+	}
+
+	if cfg.MaxSolutionLength <= 0 {
+		cfg.MaxSolutionLength = DefaultMaxSolutionLength
+	}
+
+	if cfg.MaxSolutions <= 0 {
+		cfg.MaxSolutions = DefaultMaxSolutions
+	}
+
+	if len(cfg.APIVersion) == 0 {
+		cfg.APIVersion = DefaultAPIVersion
+	}
+
+	if cfg.Logger == nil {
+		cfg.Logger = discardLogger
+	}
+
+	var ad *abuseDetector
+	if (cfg.AbuseThreshold > 0) && (cfg.OnAbuseSignal != nil) {
+		ad = newAbuseDetector(&cfg)
+	}
+
+	var fo *failover
+	if cfg.Fallback != nil {
+		if cfg.FailoverDuration <= 0 {
+			cfg.FailoverDuration = DefaultFailoverDuration
+		}
+		fo = &failover{fallback: cfg.Fallback, duration: cfg.FailoverDuration, logger: cfg.Logger}
+	}
+
+	m := &maintenance{}
+	m.add(cfg.MaintenanceWindows...)
+
+	var cn *canary
+	if cfg.Canary != nil {
+		var err error
+		if cn, err = newCanary(*cfg.Canary); err != nil {
+			return nil, err
+		}
+	}
+
+	trusted, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	var faults faultInjector
+	if cfg.UnsafeChaos != nil {
+		if err := cfg.UnsafeChaos.validate(); err != nil {
+			return nil, err
+		}
+		cfg.Logger.Log(context.Background(), slog.LevelWarn, "Chaos fault injection is enabled, it must not be used in production")
+		faults = cfg.UnsafeChaos
+	}
+
+	var sb *standby
+	if len(cfg.StandbyDomain) > 0 {
+		sb = newStandby(&cfg)
+	}
+
+	c := &Client{
+		standby:          sb,
 		apiKey:           cfg.APIKey,
-		client:           cfg.Client,
+		keys:             newScopedKeys(&cfg),
+		client:           cfg.doer(),
 		formField:        cfg.FormField,
+		formFields:       slices.Clone(cfg.FormFields),
+		extractor:        cfg.Extractor,
 		failedStatusCode: cfg.FailedStatusCode,
-	}, nil
+		payloadFormat:    cfg.PayloadFormat,
+		maxSolutionLen:   cfg.MaxSolutionLength,
+		region:           cfg.Region,
+		apiVersion:       cfg.APIVersion,
+		solutionsPolicy:  cfg.SolutionsPolicy,
+		maxSolutions:     cfg.MaxSolutions,
+		retryBudget:      cfg.RetryBudget,
+		limiter:          cfg.Limiter,
+		onLoadShed:       cfg.OnLoadShed,
+		onStaleGrace:     cfg.OnStaleGrace,
+		policies:         cfg.Policies,
+		slowThreshold:    cfg.SlowCallThreshold,
+		onSlowCall:       cfg.OnSlowCall,
+		failover:         fo,
+		origin:           cfg.Origin,
+		trustedProxies:   trusted,
+		stats:            &transportStats{},
+		defaultCostTag:   cfg.CostTag,
+		traceTimings:     cfg.TraceTimings,
+		onResult:         cfg.OnResult,
+		onResponse:       cfg.OnResponse,
+		maintenance:      m,
+		canary:           cn,
+		annotation:       cfg.Annotation,
+		signature:        cfg.ResponseSignature,
+		attestation:      cfg.Attestation,
+		logger:           cfg.Logger,
+		abuse:            ad,
+		success:          newSuccessTracker(&cfg),
+		validateKey:      cfg.ValidateAPIKeyOnStart,
+		faults:           faults,
+		verifyPath:       cfg.VerifyPath,
+		ownsClient:       (cfg.Doer == nil) && cfg.hasTransportOptions(),
+	}
+
+	endpoint := fmt.Sprintf("https://%s%s", strings.Trim(cfg.Domain, "/"), cfg.VerifyPath)
+	c.endpoint.Store(&endpoint)
+
+	return c, nil
+}
+
+func trimScheme(domain string) string {
+	domain = strings.TrimPrefix(domain, "https://")
+	return strings.TrimPrefix(domain, "http://")
+}
+
+// sameOrigin compares origins ignoring scheme, e.g. "https://example.com" and "example.com"
+func sameOrigin(a, b string) bool {
+	trim := func(s string) string {
+		s = strings.TrimPrefix(s, "https://")
+		s = strings.TrimPrefix(s, "http://")
+		return strings.TrimSuffix(s, "/")
+	}
+
+	return strings.EqualFold(trim(a), trim(b))
+}
+
+func regionFromDomain(domain string) string {
+	switch strings.Trim(domain, "/") {
+	case GlobalDomain:
+		return RegionGlobal
+	case EUDomain:
+		return RegionEU
+	default:
+		return RegionSelfHosted
+	}
+}
+
+// Endpoint returns the full URL of the verify endpoint used by the client
+func (c *Client) Endpoint() string {
+	return *c.endpoint.Load()
+}
+
+// validVerifyPath checks that path can be appended to the API domain as-is
+func validVerifyPath(path string) bool {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.ContainsAny(path, "?#\\ ") {
+		return false
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		if (segment == ".") || (segment == "..") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// endpointFor returns the URL of the verify endpoint with the path overridden for a single call
+func (c *Client) endpointFor(path string) string {
+	endpoint := c.Endpoint()
+	if (len(path) == 0) || (path == c.verifyPath) {
+		return endpoint
+	}
+
+	return strings.TrimSuffix(endpoint, c.verifyPath) + path
+}
+
+// APIVersion returns the version of verify API requested by the client
+func (c *Client) APIVersion() string {
+	return c.apiVersion
+}
+
+// Region returns the configured region of the API endpoint (global, EU or self-hosted)
+func (c *Client) Region() string {
+	return c.region
 }
 
 // retriableError is a wrapper for errors that should be retried.
@@ -123,50 +612,269 @@ func (e retriableError) Unwrap() error {
 	return e.err
 }
 
-func (c *Client) doVerify(ctx context.Context, solution, sitekey string, headers []string) (*VerifyOutput, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, strings.NewReader(solution))
+// verificationError is returned by VerifyRequest when the API responded, but verification was not successful
+type verificationError struct {
+	code    VerifyCode
+	message string
+}
+
+func (e *verificationError) Error() string {
+	return "captcha verification failed: " + e.message
+}
+
+// isMaintenanceMode returns true if the API rejected verification with MaintenanceModeError
+func isMaintenanceMode(err error) bool {
+	var verr *verificationError
+	return errors.As(err, &verr) && (verr.code == MaintenanceModeError)
+}
+
+func isRetriableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusServiceUnavailable,
+		http.StatusBadGateway,
+		http.StatusGatewayTimeout,
+		http.StatusRequestTimeout,
+		http.StatusTooEarly:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Client) encodePayload(solution string) (string, string, error) {
+	switch c.payloadFormat {
+	case PayloadForm:
+		return url.Values{"response": []string{solution}}.Encode(), "application/x-www-form-urlencoded", nil
+	case PayloadJSON:
+		data, err := json.Marshal(struct {
+			Solution string `json:"solution"`
+		}{Solution: solution})
+		if err != nil {
+			return "", "", err
+		}
+		return string(data), "application/json", nil
+	default:
+		return solution, "text/plain", nil
+	}
+}
+
+// requestBody is a source of verify request body that can be (re)opened for every attempt
+type requestBody struct {
+	data        string
+	reader      io.Reader
+	contentType string
+	replayable  bool
+	// size and digest of the solution (-1 and empty if it is streamed without buffering)
+	size   int64
+	digest string
+}
+
+// limitedReader is like io.LimitedReader, but fails instead of returning EOF when limit is exceeded
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, ErrSolutionTooLong
+	}
+
+	return n, err
+}
+
+func (c *Client) newRequestBody(input *VerifyInput) (*requestBody, error) {
+	if input.SolutionReader == nil {
+		if len(input.Solution) > c.maxSolutionLen {
+			return nil, ErrSolutionTooLong
+		}
+
+		data, contentType, err := c.encodePayload(input.Solution)
+		if err != nil {
+			return nil, err
+		}
+		return &requestBody{data: data, contentType: contentType, replayable: true,
+			size: int64(len(input.Solution)), digest: solutionDigest(input.Solution)}, nil
+	}
+
+	if c.payloadFormat == PayloadRaw {
+		if rs, ok := input.SolutionReader.(io.ReadSeeker); ok {
+			size, err := rs.Seek(0, io.SeekEnd)
+			if err != nil {
+				return nil, err
+			}
+
+			if size > int64(c.maxSolutionLen) {
+				return nil, ErrSolutionTooLong
+			}
+
+			digest, err := readerDigest(rs)
+			if err != nil {
+				return nil, err
+			}
+
+			return &requestBody{reader: rs, contentType: "text/plain", replayable: true, size: size, digest: digest}, nil
+		}
+
+		if input.DisableBodyBuffering {
+			reader := &limitedReader{r: input.SolutionReader, n: int64(c.maxSolutionLen)}
+			return &requestBody{reader: reader, contentType: "text/plain", replayable: false, size: -1}, nil
+		}
+	}
+
+	solution, err := io.ReadAll(&limitedReader{r: input.SolutionReader, n: int64(c.maxSolutionLen)})
 	if err != nil {
-		slog.Log(ctx, levelTrace, "Failed to create HTTP request", errAttr(err))
 		return nil, err
 	}
 
-	req.Header.Set(headerApiKey, c.apiKey)
+	data, contentType, err := c.encodePayload(string(solution))
+	if err != nil {
+		return nil, err
+	}
+
+	return &requestBody{data: data, contentType: contentType, replayable: true,
+		size: int64(len(solution)), digest: solutionDigest(string(solution))}, nil
+}
+
+func (b *requestBody) newRequest(ctx context.Context, endpoint string) (*http.Request, error) {
+	if b.reader == nil {
+		return http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(b.data))
+	}
+
+	rs, ok := b.reader.(io.ReadSeeker)
+	if !ok {
+		// streamed exactly once with chunked transfer encoding
+		return http.NewRequestWithContext(ctx, http.MethodPost, endpoint, io.NopCloser(b.reader))
+	}
+
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, io.NopCloser(rs))
+	if err != nil {
+		return nil, err
+	}
+
+	// allows transport to replay the body (e.g. after HTTP/2 GOAWAY)
+	req.GetBody = func() (io.ReadCloser, error) {
+		if _, err := rs.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(rs), nil
+	}
+
+	return req, nil
+}
+
+func (c *Client) doVerify(ctx context.Context, body *requestBody, input *VerifyInput) (*VerifyOutput, error) {
+	c.stats.begin()
+	defer c.stats.end()
+
+	if c.faults != nil {
+		if err := c.injectFault(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			return nil, retriableError{err}
+		}
+	}
+
+	traceCtx, rt := c.stats.withTrace(ctx)
+	req, err := body.newRequest(traceCtx, c.endpointFor(input.Path))
+	if err != nil {
+		c.logger.Log(ctx, levelTrace, "Failed to create HTTP request", errAttr(err))
+		return nil, err
+	}
+
+	// annotation goes first so that it cannot override headers of the API
+	if c.annotation != nil {
+		c.annotation.apply(req.Header, time.Now())
+	}
+	apiKey := apiKeyFromContext(ctx)
+	if len(apiKey) == 0 {
+		apiKey = c.apiKey
+	}
+	req.Header.Set(headerApiKey, apiKey)
 	req.Header.Set(headerUserAgent, userAgent)
-	req.Header.Set(headerContentType, "text/plain")
-	if len(sitekey) > 0 {
-		req.Header.Set(headerSitekey, sitekey)
+	req.Header.Set(headerAPIVersion, c.apiVersion)
+	req.Header.Set(headerContentType, body.contentType)
+	if len(c.origin) > 0 {
+		req.Header.Set(headerOrigin, c.origin)
+	}
+	if len(input.Sitekey) > 0 {
+		req.Header.Set(headerSitekey, input.Sitekey)
+	}
+	var nonce string
+	if c.signature != nil {
+		nonce = c.signature.newNonce(req.Header)
+	}
+	tag := c.costTag(ctx, input)
+	if len(tag) > 0 {
+		req.Header.Set(headerCostTag, tag)
+	}
+	if len(input.ClientHints) > 0 {
+		hints := url.Values{}
+		for k, v := range input.ClientHints {
+			hints.Set(k, v)
+		}
+		req.Header.Set(headerClientHints, hints.Encode())
 	}
 
+	c.usage.add(tag)
 	resp, err := c.client.Do(req)
 	if err != nil {
-		slog.Log(ctx, levelTrace, "Failed to send HTTP request", "path", req.URL.Path, "method", req.Method, errAttr(err))
+		c.logger.Log(ctx, levelTrace, "Failed to send HTTP request", "path", req.URL.Path, "method", req.Method, errAttr(err))
 		return nil, retriableError{err}
 	}
 	defer resp.Body.Close()
 
 	traceID := resp.Header.Get(headerTraceID)
 
-	slog.Log(ctx, levelTrace, "HTTP request finished", "path", req.URL.Path, "status", resp.StatusCode, "traceID", traceID)
+	c.logger.Log(ctx, levelTrace, "HTTP request finished", "path", req.URL.Path, "status", resp.StatusCode, "traceID", traceID)
+	c.keyValidity.record(apiKey, resp.StatusCode)
+
+	if c.onResponse != nil {
+		if err := c.captureResponse(ctx, resp); err != nil {
+			return nil, retriableError{err}
+		}
+	}
 
 	switch resp.StatusCode {
 	case http.StatusTooManyRequests:
 		httpErr := HTTPError{StatusCode: resp.StatusCode, TraceID: traceID}
 		if retryAfter := resp.Header.Get(headerRetryAfter); len(retryAfter) > 0 {
-			slog.Log(ctx, levelTrace, "Rate limited", "retryAfter", retryAfter, "rateLimit", resp.Header.Get(headerRateLimit))
+			c.logger.Log(ctx, levelTrace, "Rate limited", "retryAfter", retryAfter, "rateLimit", resp.Header.Get(headerRateLimit))
 			if value, aerr := strconv.Atoi(retryAfter); aerr == nil {
 				httpErr.Seconds = value
 			} else {
-				slog.Log(ctx, levelTrace, "Failed to parse Retry-After header", "retryAfter", retryAfter, errAttr(aerr))
+				c.logger.Log(ctx, levelTrace, "Failed to parse Retry-After header", "retryAfter", retryAfter, errAttr(aerr))
 			}
 		}
 
 		return nil, retriableError{httpErr}
-	case http.StatusInternalServerError,
-		http.StatusServiceUnavailable,
-		http.StatusBadGateway,
-		http.StatusGatewayTimeout,
-		http.StatusRequestTimeout,
-		http.StatusTooEarly:
+	case http.StatusUnauthorized:
+		c.logger.Log(ctx, c.alertLevel(slog.LevelError), "API key was rejected", "status", resp.StatusCode, "traceID", traceID)
+		return nil, &APIKeyError{HTTPError: HTTPError{StatusCode: resp.StatusCode, TraceID: traceID}}
+	case http.StatusNotAcceptable, http.StatusUpgradeRequired:
+		supported := resp.Header.Get(headerAPIVersion)
+		c.logger.Log(ctx, levelTrace, "API version is not supported", "requested", c.apiVersion, "supported", supported)
+		return nil, &APIVersionError{
+			HTTPError: HTTPError{StatusCode: resp.StatusCode, TraceID: traceID},
+			Requested: c.apiVersion,
+			Supported: supported,
+		}
+	}
+
+	if isRetriableStatus(resp.StatusCode) {
 		return nil, retriableError{HTTPError{StatusCode: resp.StatusCode, TraceID: traceID}}
 	}
 
@@ -175,16 +883,47 @@ func (c *Client) doVerify(ctx context.Context, solution, sitekey string, headers
 	}
 
 	metadata := make(map[string]string)
-	for _, header := range headers {
+	for _, header := range input.Headers {
 		metadata[header] = resp.Header.Get(header)
 	}
 
-	response := &VerifyOutput{requestID: traceID, metadata: metadata}
+	region := resp.Header.Get(headerRegion)
+	if len(region) == 0 {
+		region = c.region
+	}
+
+	response := &VerifyOutput{requestID: traceID, metadata: metadata, region: region}
+
+	if c.traceTimings {
+		timings := rt.result()
+		response.timings = &timings
+		c.logger.Log(ctx, levelTrace, "HTTP request timings", "dns", timings.DNS.String(), "connect", timings.Connect.String(),
+			"tls", timings.TLS.String(), "ttfb", timings.TTFB.String())
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return response, retriableError{err}
+	}
+
+	if c.signature != nil {
+		if err := c.signature.verify(resp.Header, nonce, data); err != nil {
+			c.logger.Log(ctx, c.alertLevel(slog.LevelError), "Verify response signature is invalid", "traceID", traceID)
+			return nil, err
+		}
+	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if err := json.Unmarshal(data, &response); err != nil {
 		return response, retriableError{err}
 	}
 
+	response.signals = parseSignals(data)
+
+	if (len(c.origin) > 0) && (len(response.Origin) > 0) && !sameOrigin(c.origin, response.Origin) {
+		c.logger.Log(ctx, levelTrace, "Solution origin mismatch", "expected", c.origin, "actual", response.Origin)
+		return response, &OriginError{Expected: c.origin, Actual: response.Origin}
+	}
+
 	return response, nil
 }
 
@@ -194,20 +933,162 @@ type VerifyInput struct {
 	Attempts          int
 	Headers           []string
 	Sitekey           string
+	// (optional) Stream solution from the reader instead of Solution. Reader is rewound between attempts
+	// if it implements io.Seeker, otherwise it is buffered in memory (unless DisableBodyBuffering is set)
+	SolutionReader io.Reader
+	// (optional) Stream non-seekable SolutionReader without buffering. Such requests are never retried
+	DisableBodyBuffering bool
+	// (optional) Timeout of a single verify attempt. When it expires, attempt fails with ErrAttemptTimeout and is retried
+	AttemptTimeout time.Duration
+	// (optional) Path of the verify endpoint for this call (defaults to Configuration.VerifyPath), e.g. "/siteverify"
+	Path string
+	// (optional) Cost attribution tag of this call (defaults to tag set with WithCostTag or Configuration.CostTag)
+	CostTag string
+	// (optional) Client-side signals gathered by the frontend (e.g. navigator data hash, widget render time),
+	// forwarded to the API as-is for server-side risk scoring
+	ClientHints map[string]string
 }
 
 // Verify will verify CAPTCHA solution obtained from the client-side. Solution usually comes as part of the form.
 // In case of errors, can use VerificationResponse.RequestID() for tracing.
-func (c *Client) Verify(ctx context.Context, input VerifyInput) (*VerifyOutput, error) {
-	if len(input.Solution) == 0 {
+func (c *Client) Verify(ctx context.Context, input VerifyInput) (output *VerifyOutput, err error) {
+	if c.onResult != nil {
+		defer func() {
+			c.onResult(ctx, output, err)
+		}()
+	}
+
+	defer func() {
+		c.trackSuccess(ctx, output, err)
+	}()
+
+	if c.faults != nil {
+		defer func() {
+			if (err == nil) && (output != nil) {
+				c.flipResult(ctx, output)
+			}
+		}()
+	}
+
+	if c.attestation != nil {
+		defer func() {
+			if (err == nil) && output.OK() {
+				c.attest(ctx, output, input.Sitekey)
+			}
+		}()
+	}
+
+	if c.abuse != nil {
+		defer func() {
+			if err == nil {
+				c.detectAbuse(ctx, output)
+			}
+		}()
+	}
+
+	if len(c.policies) > 0 {
+		defer func() {
+			if (err == nil) && (output != nil) {
+				c.policies.Evaluate(ctx, output)
+				c.logger.Log(ctx, levelTrace, "Applied verification policies", "ok", output.OK(), "decisions", output.decisions)
+			}
+		}()
+	}
+
+	if c.failover != nil {
+		if c.InMaintenance() {
+			c.logger.Log(ctx, levelTrace, "Using fallback provider during maintenance window")
+			return c.failover.fallback.Verify(ctx, input)
+		}
+
+		return c.failover.verify(ctx, input, c.verifyPrimary)
+	}
+
+	if c.InMaintenance() {
+		// do not keep requests waiting for retries of the service that is known to be unavailable
+		c.logger.Log(ctx, levelTrace, "Verifying with a single attempt during maintenance window")
+		input.Attempts = 1
+	}
+
+	return c.verifyPrimary(ctx, input)
+}
+
+// contextError returns context error, wrapped together with the cancellation cause if it was provided
+// (e.g. with context.WithCancelCause), so that callers can distinguish between different reasons
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+	if cause := context.Cause(ctx); (cause != nil) && (cause != err) {
+		return fmt.Errorf("%w: %w", err, cause)
+	}
+
+	return err
+}
+
+// sleepContext waits for d or until ctx is done, whichever happens first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return contextError(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *Client) doAttempt(ctx context.Context, body *requestBody, input *VerifyInput) (*VerifyOutput, error) {
+	if err := c.limiter.acquire(ctx); err != nil {
+		c.logger.Log(ctx, levelTrace, "Failed to wait for concurrency limit", "limit", c.limiter.Limit(), errAttr(err))
+		return nil, c.loadShed(ctx, LoadShedLimiter, err)
+	}
+
+	response, err := c.doTimedAttempt(ctx, body, input)
+	c.limiter.release(err)
+
+	return response, err
+}
+
+func (c *Client) doTimedAttempt(ctx context.Context, body *requestBody, input *VerifyInput) (*VerifyOutput, error) {
+	if input.AttemptTimeout <= 0 {
+		return c.doVerify(ctx, body, input)
+	}
+
+	attemptCtx, cancel := context.WithTimeoutCause(ctx, input.AttemptTimeout, ErrAttemptTimeout)
+	defer cancel()
+
+	response, err := c.doVerify(attemptCtx, body, input)
+	if (err != nil) && (ctx.Err() == nil) && (context.Cause(attemptCtx) == ErrAttemptTimeout) {
+		return response, retriableError{fmt.Errorf("%w: %w", ErrAttemptTimeout, err)}
+	}
+
+	return response, err
+}
+
+func (c *Client) verify(ctx context.Context, input VerifyInput) (*VerifyOutput, error) {
+	if (len(input.Solution) == 0) && (input.SolutionReader == nil) {
 		return nil, errEmtpySolution
 	}
 
+	if (len(input.Path) > 0) && !validVerifyPath(input.Path) {
+		return nil, errVerifyPath
+	}
+
+	body, err := c.newRequestBody(&input)
+	if err != nil {
+		c.logger.Log(ctx, levelTrace, "Failed to prepare request body", errAttr(err))
+		return nil, err
+	}
+
 	attempts := 5
 	if input.Attempts > 0 {
 		attempts = input.Attempts
 	}
 
+	if !body.replayable {
+		attempts = 1
+	}
+
 	maxBackoffSeconds := 20
 	if input.MaxBackoffSeconds > 0 {
 		maxBackoffSeconds = input.MaxBackoffSeconds
@@ -221,13 +1102,20 @@ func (c *Client) Verify(ctx context.Context, input VerifyInput) (*VerifyOutput,
 	}
 
 	var response *VerifyOutput
-	var err error
-	var i int
+	var i, sent int
+	digest := body.digest
 
-	slog.Log(ctx, levelTrace, "About to start verifying solution", "maxAttempts", attempts, "maxBackoff", maxBackoffSeconds, "solution", len(input.Solution))
+	c.logger.Log(ctx, levelTrace, "About to start verifying solution", "maxAttempts", attempts, "maxBackoff", maxBackoffSeconds,
+		"solution", body.size, "digest", digest)
 
 	for i = 0; i < attempts; i++ {
 		if i > 0 {
+			if !c.retryBudget.Allow() {
+				c.logger.Log(ctx, levelTrace, "Retry budget is exhausted", "attempt", i, errAttr(err))
+				err = c.loadShed(ctx, LoadShedRetryBudget, err)
+				break
+			}
+
 			backoffDuration := b.Duration()
 			var httpErr HTTPError
 			if (err != nil) && errors.As(err, &httpErr) {
@@ -235,18 +1123,25 @@ func (c *Client) Verify(ctx context.Context, input VerifyInput) (*VerifyOutput,
 					backoffDuration = time.Duration(min(httpErr.Seconds, maxBackoffSeconds)) * time.Second
 				}
 			}
-			slog.Log(ctx, levelTrace, "Failed to send verify request", "attempt", i, "backoff", backoffDuration.String(), errAttr(err))
-			select {
-			case <-ctx.Done():
+			c.logger.Log(ctx, levelTrace, "Failed to send verify request", "attempt", i, "backoff", backoffDuration.String(), errAttr(err))
+			if serr := sleepContext(ctx, backoffDuration); serr != nil {
 				if response == nil {
-					response = &VerifyOutput{Success: false, Code: VERIFY_CODES_COUNT}
+					response = &VerifyOutput{Success: false, Code: VERIFY_CODES_COUNT, region: c.region}
 				}
-				return response, ctx.Err()
-			case <-time.After(backoffDuration):
+				response.attempts = sent
+				response.digest = digest
+				return response, serr
 			}
 		}
 
-		response, err = c.doVerify(ctx, input.Solution, input.Sitekey, input.Headers)
+		start := time.Now()
+		sent++
+		response, err = c.doAttempt(ctx, body, &input)
+		if (c.onSlowCall != nil) && (c.slowThreshold > 0) && !c.InMaintenance() {
+			if duration := time.Since(start); duration > c.slowThreshold {
+				c.onSlowCall(ctx, SlowCallInfo{Attempt: i, Endpoint: c.endpointFor(input.Path), Duration: duration, Err: err, SolutionDigest: digest})
+			}
+		}
 		var rerr retriableError
 		if (err != nil) && errors.As(err, &rerr) {
 			err = rerr.Unwrap()
@@ -255,40 +1150,102 @@ func (c *Client) Verify(ctx context.Context, input VerifyInput) (*VerifyOutput,
 		}
 	}
 
-	slog.Log(ctx, levelTrace, "Finished verifying solution", "attempts", i, "success", (err == nil))
+	if (err != nil) && (ctx.Err() != nil) && !errors.Is(err, ErrLoadShed) {
+		err = contextError(ctx)
+	}
+
+	c.logger.Log(ctx, levelTrace, "Finished verifying solution", "attempts", i, "success", (err == nil), "digest", digest)
 
 	if response == nil {
-		response = &VerifyOutput{Success: false, Code: VERIFY_CODES_COUNT}
+		response = &VerifyOutput{Success: false, Code: VERIFY_CODES_COUNT, region: c.region}
 	}
 	response.attempt = i
+	response.attempts = sent
+	response.digest = digest
 
 	return response, err
 }
 
-// VerifyRequest fetches puzzle solution from HTTP form field configured on creation and calls Verify() with defaults
-func (c *Client) VerifyRequest(ctx context.Context, r *http.Request) error {
-	solution := r.FormValue(c.formField)
-
-	output, err := c.Verify(ctx, VerifyInput{Solution: solution})
+func (c *Client) verifySolution(ctx context.Context, solution string) error {
+	output, err := c.Verify(ctx, VerifyInput{Solution: solution, Sitekey: sitekeyFromContext(ctx)})
 	if err != nil {
 		return err
 	}
 
 	if !output.OK() {
-		return fmt.Errorf("captcha verification failed: %v", output.Error())
+		return &verificationError{code: output.Code, message: output.Error()}
 	}
 
 	return nil
 }
 
+// VerifyRequest fetches puzzle solution from HTTP form field (or Configuration.Extractor) configured on creation
+// and calls Verify() with defaults. With Configuration.FormFields the first field present in the form is used.
+// If the field has multiple values, they are verified according to the configured SolutionsPolicy.
+// For application/json requests the solution is read from the top-level field of the same name in the body
+// (up to DefaultMaxJSONBodySize), which is restored for downstream handlers.
+func (c *Client) VerifyRequest(ctx context.Context, r *http.Request) error {
+	if c.extractor != nil {
+		return c.verifyRequestWith(ctx, r, c.extractor)
+	}
+
+	if isJSONRequest(r) {
+		// form fields are top-level keys of JSON body, even if they contain dots
+		return c.verifyRequestWith(ctx, r, c.formExtractor(func(field string) Extractor {
+			return FromJSON(escapeJSONKey(field))
+		}))
+	}
+
+	field := c.formField
+	for _, f := range c.formFields {
+		if len(r.FormValue(f)) > 0 {
+			field = f
+			break
+		}
+	}
+
+	solution := r.FormValue(field)
+
+	if c.solutionsPolicy == SolutionsFirst {
+		return c.verifySolution(ctx, solution)
+	}
+
+	solutions := r.Form[field]
+	if len(solutions) <= 1 {
+		return c.verifySolution(ctx, solution)
+	}
+
+	return c.verifySolutions(ctx, solutions)
+}
+
+// formExtractor returns extractor of the configured form fields in order of priority
+func (c *Client) formExtractor(extractor func(field string) Extractor) Extractor {
+	if len(c.formFields) == 1 {
+		return extractor(c.formFields[0])
+	}
+
+	extractors := make(Extractors, 0, len(c.formFields))
+	for _, field := range c.formFields {
+		extractors = append(extractors, extractor(field))
+	}
+
+	return extractors
+}
+
 // VerifyFunc is a basic http middleware that verifies captcha solution sent via form
 func (c *Client) VerifyFunc(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if err := c.VerifyRequest(r.Context(), r); err != nil {
+			if policy := c.maintenanceFailurePolicy(FailureReject); policy == FailureMonitor {
+				c.logger.Log(r.Context(), slog.LevelInfo, "Passing request that failed verification during maintenance", errAttr(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			http.Error(w, http.StatusText(c.failedStatusCode), c.failedStatusCode)
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(WithVerified(r.Context())))
 	})
 }
@@ -11,8 +11,10 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -323,3 +325,643 @@ func TestCustomFailedStatusCode(t *testing.T) {
 		t.Errorf("Expected status code %d, got %d", customStatusCode, recorder.Code)
 	}
 }
+
+func newFakeClient(t *testing.T, handler http.HandlerFunc, cfg Configuration) *Client {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+
+	cfg.Domain = srv.URL
+	cfg.Client = srv.Client()
+	if len(cfg.APIKey) == 0 {
+		cfg.APIKey = "test-api-key"
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestPayloadFormat(t *testing.T) {
+	t.Parallel()
+
+	const solution = "abc.def"
+
+	testCases := []struct {
+		format      PayloadFormat
+		contentType string
+		body        string
+	}{
+		{PayloadRaw, "text/plain", solution},
+		{PayloadForm, "application/x-www-form-urlencoded", "response=abc.def"},
+		{PayloadJSON, "application/json", `{"solution":"abc.def"}`},
+	}
+
+	for _, tc := range testCases {
+		client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if ct := r.Header.Get(headerContentType); ct != tc.contentType {
+				t.Errorf("Unexpected content type: %v", ct)
+			}
+			if string(body) != tc.body {
+				t.Errorf("Unexpected body: %v", string(body))
+			}
+			w.Write([]byte(`{"success":true,"code":0}`))
+		}, Configuration{PayloadFormat: tc.format})
+
+		output, err := client.Verify(context.TODO(), VerifyInput{Solution: solution})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !output.OK() {
+			t.Errorf("Unexpected output: %v", output.Error())
+		}
+	}
+}
+
+func TestStreamingSolutionRetries(t *testing.T) {
+	t.Parallel()
+
+	const solution = "streamed.solution"
+
+	var mu sync.Mutex
+	requests := 0
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != solution {
+			t.Errorf("Unexpected body: %v", string(body))
+		}
+
+		mu.Lock()
+		requests++
+		first := requests == 1
+		mu.Unlock()
+
+		if first {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{})
+
+	output, err := client.Verify(context.TODO(), VerifyInput{
+		SolutionReader:    strings.NewReader(solution),
+		MaxBackoffSeconds: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !output.OK() || (output.attempt != 1) {
+		t.Errorf("Unexpected output: %v (attempt %v)", output.Error(), output.attempt)
+	}
+
+	if output.SolutionDigest() != solutionDigest(solution) {
+		t.Errorf("Unexpected digest of streamed solution: %v", output.SolutionDigest())
+	}
+
+	// buffered reader
+	output, err = client.Verify(context.TODO(), VerifyInput{SolutionReader: io.MultiReader(strings.NewReader(solution))})
+	if (err != nil) || (output.SolutionDigest() != solutionDigest(solution)) {
+		t.Errorf("Unexpected digest of buffered solution: %v (%v)", output.SolutionDigest(), err)
+	}
+}
+
+func TestStreamingSolutionNoBuffering(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, Configuration{})
+
+	output, err := client.Verify(context.TODO(), VerifyInput{
+		SolutionReader:       io.MultiReader(strings.NewReader("streamed.solution")),
+		DisableBodyBuffering: true,
+	})
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if output.attempt != 1 {
+		t.Errorf("Unexpected number of attempts: %v", output.attempt)
+	}
+
+	if digest := output.SolutionDigest(); len(digest) > 0 {
+		t.Errorf("Unexpected digest of unbuffered solution: %v", digest)
+	}
+}
+
+func TestVerifyMessageErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.TODO(), traceIDContextKey, t.Name())
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get(headerSitekey) {
+		case "bad-request":
+			w.WriteHeader(http.StatusBadRequest)
+		case "unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"success":false,"code":3}`))
+		}
+	}, Configuration{})
+
+	if _, err := client.VerifyMessage(ctx, nil, nil); (err == nil) || IsRetriable(err) {
+		t.Errorf("Empty payload should be a permanent error: %v", err)
+	}
+
+	if _, err := client.VerifyMessage(ctx, []byte("asdf"), map[string]string{MessageMetaSitekey: "bad-request"}); (err == nil) || IsRetriable(err) {
+		t.Errorf("Bad request should be a permanent error: %v", err)
+	}
+
+	if _, err := client.VerifyMessage(ctx, []byte("asdf"), map[string]string{MessageMetaSitekey: "invalid"}); (err == nil) || IsRetriable(err) {
+		t.Errorf("Invalid solution should be a permanent error: %v", err)
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := client.VerifyMessage(cctx, []byte("asdf"), map[string]string{MessageMetaSitekey: "unavailable"}); !IsRetriable(err) {
+		t.Errorf("Unavailable service should be a retriable error: %v", err)
+	}
+}
+
+func TestVerifyMessageLocalErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.TODO(), traceIDContextKey, t.Name())
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"code":0,"origin":"other.com"}`))
+	}, Configuration{MaxSolutionLength: 10, Origin: "example.com"})
+
+	_, err := client.VerifyMessage(ctx, []byte(strings.Repeat("a", 11)), nil)
+	if !errors.Is(err, ErrSolutionTooLong) || IsRetriable(err) {
+		t.Errorf("Too long solution should be a permanent error: %v", err)
+	}
+
+	_, err = client.VerifyMessage(ctx, []byte("asdf"), nil)
+	var originErr *OriginError
+	if !errors.As(err, &originErr) || IsRetriable(err) {
+		t.Errorf("Origin mismatch should be a permanent error: %v", err)
+	}
+}
+
+func TestMaxSolutionLength(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.TODO(), traceIDContextKey, t.Name())
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Request should not be sent")
+	}, Configuration{MaxSolutionLength: 10})
+
+	solution := strings.Repeat("a", 11)
+
+	if _, err := client.Verify(ctx, VerifyInput{Solution: solution}); err != ErrSolutionTooLong {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := client.Verify(ctx, VerifyInput{SolutionReader: strings.NewReader(solution)}); err != ErrSolutionTooLong {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := client.Verify(ctx, VerifyInput{SolutionReader: io.MultiReader(strings.NewReader(solution))}); err != ErrSolutionTooLong {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestEndpointRegion(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		domain   string
+		endpoint string
+		region   string
+	}{
+		{"", "https://" + GlobalDomain + "/verify", RegionGlobal},
+		{"https://" + EUDomain + "/", "https://" + EUDomain + "/verify", RegionEU},
+		{"captcha.example.com", "https://captcha.example.com/verify", RegionSelfHosted},
+	}
+
+	for _, tc := range testCases {
+		client, err := NewClient(Configuration{APIKey: "test-api-key", Domain: tc.domain})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if client.Endpoint() != tc.endpoint {
+			t.Errorf("Unexpected endpoint: %v", client.Endpoint())
+		}
+
+		if client.Region() != tc.region {
+			t.Errorf("Unexpected region: %v", client.Region())
+		}
+	}
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerRegion, "eu-west")
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{})
+
+	output, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if output.Region() != "eu-west" {
+		t.Errorf("Unexpected output region: %v", output.Region())
+	}
+}
+
+func TestClientHintsAndSignals(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hints, err := url.ParseQuery(r.Header.Get(headerClientHints))
+		if err != nil {
+			t.Error(err)
+		}
+		if hints.Get("render_ms") != "250" {
+			t.Errorf("Unexpected client hints: %v", hints)
+		}
+		w.Write([]byte(`{"success":true,"code":0,"origin":"example.com","score":0.9}`))
+	}, Configuration{})
+
+	output, err := client.Verify(context.TODO(), VerifyInput{
+		Solution:    "asdf",
+		ClientHints: map[string]string{"render_ms": "250"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(output.Signal("score")) != "0.9" {
+		t.Errorf("Unexpected score signal: %v", string(output.Signal("score")))
+	}
+
+	if signals := output.Signals(); len(signals) != 1 {
+		t.Errorf("Unexpected signals: %v", signals)
+	}
+}
+
+func TestSyntheticOrigin(t *testing.T) {
+	t.Parallel()
+
+	const origin = "https://app.example.com"
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(headerOrigin) != origin {
+			t.Errorf("Unexpected origin header: %v", r.Header.Get(headerOrigin))
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, `{"success":true,"code":0,"origin":"%s"}`, string(body))
+	}, Configuration{Origin: origin})
+
+	if _, err := client.Verify(context.TODO(), VerifyInput{Solution: "app.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := client.Verify(context.TODO(), VerifyInput{Solution: "evil.example.com"})
+	var originErr *OriginError
+	if !errors.As(err, &originErr) || (originErr.Actual != "evil.example.com") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestContextCause(t *testing.T) {
+	t.Parallel()
+
+	errClientGone := errors.New("client disconnected")
+
+	var requests atomic.Int32
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(1 * time.Second):
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, Configuration{})
+
+	// per-attempt timeout is retried and reported with its own cause
+	input := VerifyInput{Solution: "asdf", Attempts: 2, MaxBackoffSeconds: 1, AttemptTimeout: 50 * time.Millisecond}
+	if _, err := client.Verify(context.TODO(), input); !errors.Is(err, ErrAttemptTimeout) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if requests.Load() != 2 {
+		t.Errorf("Unexpected number of requests: %v", requests.Load())
+	}
+
+	// cancellation by the caller carries the cause
+	ctx, cancel := context.WithCancelCause(context.TODO())
+	time.AfterFunc(50*time.Millisecond, func() { cancel(errClientGone) })
+
+	_, err := client.Verify(ctx, VerifyInput{Solution: "asdf", Attempts: 1})
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, errClientGone) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestAPIVersionNotSupported(t *testing.T) {
+	t.Parallel()
+
+	var requested atomic.Value
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requested.Store(r.Header.Get(headerAPIVersion))
+		w.Header().Set(headerAPIVersion, "2")
+		w.WriteHeader(http.StatusUpgradeRequired)
+	}, Configuration{})
+
+	if client.APIVersion() != DefaultAPIVersion {
+		t.Errorf("Unexpected API version: %v", client.APIVersion())
+	}
+
+	_, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 3})
+
+	var versionErr *APIVersionError
+	if !errors.As(err, &versionErr) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if (versionErr.Requested != DefaultAPIVersion) || (versionErr.Supported != "2") {
+		t.Errorf("Unexpected versions: requested=%v supported=%v", versionErr.Requested, versionErr.Supported)
+	}
+
+	if code, ok := GetStatusCode(err); !ok || (code != http.StatusUpgradeRequired) {
+		t.Errorf("Unexpected status code: %v", code)
+	}
+
+	if requested.Load() != DefaultAPIVersion {
+		t.Errorf("Unexpected API version header: %v", requested.Load())
+	}
+}
+
+func TestInvalidAPIKey(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}, Configuration{})
+
+	_, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 3})
+	if !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if code, ok := GetStatusCode(err); !ok || (code != http.StatusUnauthorized) {
+		t.Errorf("Unexpected status code: %v", code)
+	}
+
+	// invalid API key is never retried
+	if calls.Load() != 1 {
+		t.Errorf("Unexpected calls: %v", calls.Load())
+	}
+}
+
+func TestVerifyPath(t *testing.T) {
+	t.Parallel()
+
+	var path atomic.Value
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path.Store(r.URL.Path)
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{VerifyPath: "/v2/verify"})
+
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{"", "/v2/verify"},
+		{"/siteverify", "/siteverify"},
+	}
+
+	for _, tc := range testCases {
+		if _, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1, Path: tc.path}); err != nil {
+			t.Fatal(err)
+		}
+
+		if path.Load() != tc.expected {
+			t.Errorf("Unexpected path for %q: %v", tc.path, path.Load())
+		}
+	}
+
+	for _, invalid := range []string{"verify", "//evil.com/verify", "/verify?a=b", "/../admin", "/a#b"} {
+		if _, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1, Path: invalid}); err != errVerifyPath {
+			t.Errorf("Unexpected error for %q: %v", invalid, err)
+		}
+
+		if _, err := NewClient(Configuration{APIKey: "test-api-key", VerifyPath: invalid}); err != errVerifyPath {
+			t.Errorf("Unexpected client error for %q: %v", invalid, err)
+		}
+	}
+}
+
+func FuzzNewRequestBody(f *testing.F) {
+	f.Add("abc.def", uint8(PayloadRaw))
+	f.Add("response=a&b", uint8(PayloadForm))
+	f.Add(`"}{\u0000`, uint8(PayloadJSON))
+
+	f.Fuzz(func(t *testing.T, solution string, format uint8) {
+		client, err := NewClient(Configuration{APIKey: "test-api-key", PayloadFormat: PayloadFormat(format % 3), MaxSolutionLength: 64})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := client.newRequestBody(&VerifyInput{Solution: solution})
+		if len(solution) > 64 {
+			if err != ErrSolutionTooLong {
+				t.Errorf("Unexpected error for too long solution: %v", err)
+			}
+			return
+		}
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if _, err := body.newRequest(context.TODO(), client.Endpoint()); err != nil {
+			t.Errorf("Failed to create request: %v", err)
+		}
+
+		switch client.payloadFormat {
+		case PayloadRaw:
+			if body.data != solution {
+				t.Errorf("Unexpected raw payload")
+			}
+		case PayloadForm:
+			values, err := url.ParseQuery(body.data)
+			if (err != nil) || (values.Get("response") != solution) {
+				t.Errorf("Unexpected form payload: %v", err)
+			}
+		}
+	})
+}
+
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestDoer(t *testing.T) {
+	t.Parallel()
+
+	var requests int
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{headerContentType: []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"success":true,"code":0}`)),
+			Request:    req,
+		}, nil
+	})
+
+	client, err := NewClient(Configuration{APIKey: "test-api-key", Doer: doer})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := client.Verify(context.Background(), VerifyInput{Solution: "abc.def"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !output.OK() || (requests != 1) {
+		t.Errorf("Unexpected result: %v (requests %v)", output.Error(), requests)
+	}
+
+	if _, err := NewClient(Configuration{APIKey: "test-api-key", Doer: doer, Client: http.DefaultClient}); err != errDoerWithClient {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := NewClient(Configuration{APIKey: "test-api-key", Doer: doer, TLSPreferFIPS: true}); err != errTransportWithClient {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+type recordingHandler struct {
+	mu       sync.Mutex
+	messages []string
+	levels   []slog.Level
+}
+
+func (h *recordingHandler) Enabled(ctx context.Context, level slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.messages = append(h.messages, r.Message)
+	h.levels = append(h.levels, r.Level)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler { return h }
+func (h *recordingHandler) WithGroup(name string) slog.Handler       { return h }
+
+func TestClientLogger(t *testing.T) {
+	t.Parallel()
+
+	handler := &recordingHandler{}
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{Logger: slog.New(handler)})
+
+	if _, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf"}); err != nil {
+		t.Fatal(err)
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	if !slices.Contains(handler.messages, "Finished verifying solution") {
+		t.Errorf("Unexpected log messages: %v", handler.messages)
+	}
+}
+
+func TestBackoffCancellation(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// long Retry-After makes the client back off for MaxBackoffSeconds
+		w.Header().Set(headerRetryAfter, "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, Configuration{})
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	output, err := client.Verify(ctx, VerifyInput{Solution: "asdf", Attempts: 5, MaxBackoffSeconds: 30})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Backoff was not interrupted by context: %v", elapsed)
+	}
+
+	if (requests.Load() != 1) || (output == nil) || output.OK() {
+		t.Errorf("Unexpected result: requests=%v output=%v", requests.Load(), output)
+	}
+}
+
+func TestSolutionDigest(t *testing.T) {
+	t.Parallel()
+
+	handler := &recordingAttrsHandler{}
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":false,"code":6}`))
+	}, Configuration{Logger: slog.New(handler)})
+
+	const solution = "solution.puzzle"
+	digest := solutionDigest(solution)
+	if (len(digest) != 16) || (digest != solutionDigest(solution)) || (digest == solutionDigest("other.puzzle")) {
+		t.Errorf("Unexpected digest: %v", digest)
+	}
+
+	output, _ := client.Verify(context.TODO(), VerifyInput{Solution: solution})
+	if output.SolutionDigest() != digest {
+		t.Errorf("Unexpected output digest: %v", output.SolutionDigest())
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	if !slices.Contains(handler.values, digest) || slices.Contains(handler.values, solution) {
+		t.Errorf("Unexpected logged values: %v", handler.values)
+	}
+}
+
+// recordingAttrsHandler records string values of all logged attributes
+type recordingAttrsHandler struct {
+	recordingHandler
+	values []string
+}
+
+func (h *recordingAttrsHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r.Attrs(func(a slog.Attr) bool {
+		h.values = append(h.values, a.Value.String())
+		return true
+	})
+	return nil
+}
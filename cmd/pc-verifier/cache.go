@@ -0,0 +1,81 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	key        string
+	response   verifyResponse
+	expiration time.Time
+}
+
+// resultCache keeps results of verifications for a short time, so that repeated verification of the same solution
+// (e.g. when application retries a request) returns the same result instead of "solution was verified before"
+type resultCache struct {
+	ttl     time.Duration
+	maxSize int
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order is the list of entries from the oldest to the newest, which is also the order of expiration
+	order *list.List
+}
+
+func newResultCache(ttl time.Duration, maxSize int) *resultCache {
+	return &resultCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func cacheKey(input verifyRequest) string {
+	hash := sha256.Sum256([]byte(input.Sitekey + "\x00" + input.Solution))
+	return hex.EncodeToString(hash[:])
+}
+
+func (c *resultCache) get(key string, now time.Time) (verifyResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return verifyResponse{}, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if !now.Before(entry.expiration) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return verifyResponse{}, false
+	}
+
+	return entry.response, true
+}
+
+func (c *resultCache) put(key string, response verifyResponse, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+
+	// drop expired entries and the oldest ones above the limit
+	for front := c.order.Front(); front != nil; front = c.order.Front() {
+		entry := front.Value.(*cacheEntry)
+		if (len(c.entries) < c.maxSize) && now.Before(entry.expiration) {
+			break
+		}
+		c.order.Remove(front)
+		delete(c.entries, entry.key)
+	}
+
+	c.entries[key] = c.order.PushBack(&cacheEntry{key: key, response: response, expiration: now.Add(c.ttl)})
+}
@@ -0,0 +1,228 @@
+// Command pc-verifier is a small HTTP sidecar that verifies Private Captcha solutions, so that applications
+// written in any language can verify solutions via localhost without a dedicated SDK.
+//
+// Endpoints:
+//
+//	POST /verify        body is either the raw solution or JSON {"solution": "...", "sitekey": "..."}
+//	GET  /forward-auth  ForwardAuth (Traefik) / auth_request (NGINX) endpoint, solution in X-Captcha-Solution header
+//	GET  /healthz       liveness probe
+//
+// API key is read from PC_API_KEY environment variable. Results of verifications are cached for a short time
+// (-cache-ttl), concurrent verify requests are limited adaptively (-max-concurrency) and retries are limited
+// by the retry budget (-retry-rate). With -fail-open, solutions are accepted when the API is unavailable.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+const (
+	maxBodySize = 64 * 1024
+)
+
+type verifyRequest struct {
+	Solution string `json:"solution"`
+	Sitekey  string `json:"sitekey,omitempty"`
+}
+
+type verifyResponse struct {
+	Success  bool   `json:"success"`
+	Code     int    `json:"code"`
+	Error    string `json:"error,omitempty"`
+	Origin   string `json:"origin,omitempty"`
+	TraceID  string `json:"trace_id,omitempty"`
+	FailOpen bool   `json:"fail_open,omitempty"`
+}
+
+type server struct {
+	client   *pc.Client
+	failOpen bool
+	attempts int
+	// cache is nil if caching is disabled
+	cache *resultCache
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func parseVerifyRequest(r *http.Request) (verifyRequest, error) {
+	var input verifyRequest
+
+	data, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBodySize))
+	if err != nil {
+		return input, err
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		err = json.Unmarshal(data, &input)
+	} else {
+		input.Solution = strings.TrimSpace(string(data))
+	}
+
+	return input, err
+}
+
+// isUnavailable returns true if error means that the API could not be reached (network error), failed on its side
+// (5xx) or rate limited the request (429). Errors caused by the caller (e.g. canceled request) are not included.
+func isUnavailable(ctx context.Context, err error) bool {
+	if (ctx.Err() != nil) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if code, ok := pc.GetStatusCode(err); ok {
+		return (code >= http.StatusInternalServerError) || (code == http.StatusTooManyRequests)
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func (s *server) verify(w http.ResponseWriter, r *http.Request) {
+	input, err := parseVerifyRequest(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, &verifyResponse{Error: err.Error()})
+		return
+	}
+
+	if len(input.Solution) == 0 {
+		writeJSON(w, http.StatusBadRequest, &verifyResponse{Error: "solution is empty"})
+		return
+	}
+
+	var key string
+	if s.cache != nil {
+		key = cacheKey(input)
+		if response, ok := s.cache.get(key, time.Now()); ok {
+			writeJSON(w, http.StatusOK, &response)
+			return
+		}
+	}
+
+	output, err := s.client.Verify(r.Context(), pc.VerifyInput{Solution: input.Solution, Sitekey: input.Sitekey, Attempts: s.attempts})
+	if err != nil {
+		slog.WarnContext(r.Context(), "Failed to verify solution", "error", err)
+
+		if errors.Is(err, pc.ErrSolutionTooLong) {
+			writeJSON(w, http.StatusBadRequest, &verifyResponse{Error: err.Error()})
+			return
+		}
+
+		if s.failOpen && isUnavailable(r.Context(), err) {
+			writeJSON(w, http.StatusOK, &verifyResponse{Success: true, FailOpen: true, Error: err.Error()})
+			return
+		}
+
+		if errors.Is(err, pc.ErrLoadShed) {
+			writeJSON(w, http.StatusServiceUnavailable, &verifyResponse{Error: err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusBadGateway, &verifyResponse{Error: err.Error(), TraceID: output.RequestID()})
+		return
+	}
+
+	response := verifyResponse{
+		Success: output.OK(),
+		Code:    int(output.Code),
+		Error:   output.Error(),
+		Origin:  output.Origin,
+		TraceID: output.RequestID(),
+	}
+
+	if s.cache != nil {
+		s.cache.put(key, response, time.Now())
+	}
+
+	writeJSON(w, http.StatusOK, &response)
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /verify", s.verify)
+	mux.Handle("GET /forward-auth", s.client.ForwardAuthHandler(pc.ForwardAuthOptions{}))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	return mux
+}
+
+func run() error {
+	addr := flag.String("addr", "127.0.0.1:8090", "Address to listen on")
+	domain := flag.String("domain", "", "Private Captcha API domain (for EU or self-hosted)")
+	failOpen := flag.Bool("fail-open", false, "Treat verifications as successful when API is unavailable")
+	attempts := flag.Int("attempts", 3, "Maximum verify attempts")
+	retryRate := flag.Float64("retry-rate", 10, "Maximum average retries per second")
+	maxConcurrency := flag.Int("max-concurrency", 64, "Maximum concurrent verify requests, adjusted adaptively when API rate limits them (0 disables the limit)")
+	cacheTTL := flag.Duration("cache-ttl", time.Minute, "How long verification results are cached (0 disables caching)")
+	cacheSize := flag.Int("cache-size", 10000, "Maximum number of cached verification results")
+	flag.Parse()
+
+	cfg := pc.Configuration{
+		APIKey:      os.Getenv("PC_API_KEY"),
+		Domain:      *domain,
+		RetryBudget: pc.NewRetryBudget(*retryRate, max(1, int(*retryRate))),
+		Logger:      slog.Default(),
+	}
+
+	if *maxConcurrency > 0 {
+		cfg.Limiter = pc.NewAdaptiveLimiter(*maxConcurrency, 1, *maxConcurrency)
+	}
+
+	client, err := pc.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	s := &server{client: client, failOpen: *failOpen, attempts: *attempts}
+	if (*cacheTTL > 0) && (*cacheSize > 0) {
+		s.cache = newResultCache(*cacheTTL, *cacheSize)
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("Starting verifier", "addr", *addr)
+
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+func main() {
+	if err := run(); err != nil {
+		slog.Error("Verifier failed", "error", err)
+		os.Exit(1)
+	}
+}
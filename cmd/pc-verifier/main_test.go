@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+func TestVerifyEndpoint(t *testing.T) {
+	api := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch string(body) {
+		case "good":
+			w.Write([]byte(`{"success":true,"code":0}`))
+		case "unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"success":false,"code":3}`))
+		}
+	}))
+	defer api.Close()
+
+	client, err := pc.NewClient(pc.Configuration{APIKey: "test-api-key", Domain: api.URL, Client: api.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer((&server{client: client, failOpen: true, attempts: 1}).handler())
+	defer srv.Close()
+
+	testCases := []struct {
+		contentType string
+		body        string
+		status      int
+		success     bool
+		failOpen    bool
+	}{
+		{"text/plain", "good", http.StatusOK, true, false},
+		{"application/json", `{"solution":"good"}`, http.StatusOK, true, false},
+		{"text/plain", "bad", http.StatusOK, false, false},
+		{"text/plain", "unavailable", http.StatusOK, true, true},
+		{"text/plain", "", http.StatusBadRequest, false, false},
+	}
+
+	for _, tc := range testCases {
+		resp, err := http.Post(srv.URL+"/verify", tc.contentType, strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var result verifyResponse
+		json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		if (resp.StatusCode != tc.status) || (result.Success != tc.success) || (result.FailOpen != tc.failOpen) {
+			t.Errorf("Unexpected result for %q: %v %+v", tc.body, resp.StatusCode, result)
+		}
+	}
+}
+
+func TestVerifyCache(t *testing.T) {
+	var requests atomic.Int32
+	api := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) > 1 {
+			w.Write([]byte(`{"success":false,"code":9}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}))
+	defer api.Close()
+
+	client, err := pc.NewClient(pc.Configuration{APIKey: "test-api-key", Domain: api.URL, Client: api.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer((&server{client: client, attempts: 1, cache: newResultCache(time.Minute, 10)}).handler())
+	defer srv.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, err := http.Post(srv.URL+"/verify", "text/plain", strings.NewReader("good"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var result verifyResponse
+		json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		if !result.Success {
+			t.Errorf("Unexpected result of verification %d: %+v", i, result)
+		}
+	}
+
+	if requests.Load() != 1 {
+		t.Errorf("Unexpected number of API requests: %v", requests.Load())
+	}
+}
+
+func TestResultCacheLimit(t *testing.T) {
+	now := time.Now()
+	cache := newResultCache(time.Minute, 2)
+	cache.put("a", verifyResponse{Success: true}, now)
+	cache.put("b", verifyResponse{Success: true}, now)
+	cache.put("c", verifyResponse{Success: true}, now)
+
+	if _, ok := cache.get("a", now); ok {
+		t.Error("Oldest result was not evicted")
+	}
+
+	if _, ok := cache.get("c", now); !ok {
+		t.Error("Newest result was evicted")
+	}
+
+	if _, ok := cache.get("c", now.Add(time.Minute)); ok {
+		t.Error("Expired result was returned")
+	}
+}
+
+func TestIsUnavailable(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	testCases := []struct {
+		ctx      context.Context
+		err      error
+		expected bool
+	}{
+		{context.TODO(), pc.HTTPError{StatusCode: http.StatusServiceUnavailable}, true},
+		{context.TODO(), pc.HTTPError{StatusCode: http.StatusTooManyRequests}, true},
+		{context.TODO(), pc.HTTPError{StatusCode: http.StatusBadRequest}, false},
+		{context.TODO(), &url.Error{Op: "Post", URL: "https://api", Err: &net.OpError{Op: "dial", Err: errors.New("refused")}}, true},
+		{context.TODO(), &pc.OriginError{Expected: "a.com", Actual: "b.com"}, false},
+		{context.TODO(), pc.ErrResponseSignature, false},
+		{canceled, &url.Error{Op: "Post", URL: "https://api", Err: context.Canceled}, false},
+	}
+
+	for i, tc := range testCases {
+		if actual := isUnavailable(tc.ctx, tc.err); actual != tc.expected {
+			t.Errorf("Unexpected result for test case %d: %v", i, actual)
+		}
+	}
+}
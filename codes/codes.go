@@ -0,0 +1,163 @@
+// Package codes contains verification result codes of Private Captcha API.
+//
+// Numeric values are stable and identical across Private Captcha SDKs in all languages, so they can be
+// safely stored (e.g. in analytics pipelines) and compared between services.
+// Values that are not known to this version of the package must be preserved as-is: new codes can
+// be added by the API at any time (see IsKnown()).
+package codes
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Code is a verification result code returned by the API
+type Code int
+
+const (
+	NoError Code = iota
+	ErrorOther
+	DuplicateSolutions
+	InvalidSolution
+	ParseResponse
+	PuzzleExpired
+	InvalidProperty
+	WrongOwner
+	VerifiedBefore
+	MaintenanceMode
+	TestProperty
+	Integrity
+	OrgScope
+	// Add new fields _above_
+	Count
+)
+
+const (
+	// MaxReserved is the upper bound (exclusive) of the range reserved for API verification codes.
+	// Values at or above it are never returned by the API and can be used for application-specific codes.
+	MaxReserved Code = 1000
+)
+
+var errUnknownCode = errors.New("codes: unknown verification code")
+
+var names = [Count]string{
+	NoError:            "",
+	ErrorOther:         "error-other",
+	DuplicateSolutions: "solution-duplicates",
+	InvalidSolution:    "solution-invalid",
+	ParseResponse:      "solution-bad-format",
+	PuzzleExpired:      "puzzle-expired",
+	InvalidProperty:    "property-invalid",
+	WrongOwner:         "property-owner-mismatch",
+	VerifiedBefore:     "solution-verified-before",
+	MaintenanceMode:    "maintenance-mode",
+	TestProperty:       "property-test",
+	Integrity:          "integrity-error",
+	OrgScope:           "property-org-scope",
+}
+
+func (c Code) String() string {
+	if c.IsKnown() {
+		return names[c]
+	}
+
+	return "error"
+}
+
+// IsKnown returns true if the code is defined in this version of the package
+func (c Code) IsKnown() bool {
+	return (c >= 0) && (c < Count)
+}
+
+// Parse converts string form of the code (as returned by String()) or its numeric value back to Code.
+// Numeric values within reserved range are accepted even if they are not known to this package.
+func Parse(s string) (Code, error) {
+	for i, name := range names {
+		if (name == s) && (len(s) > 0) {
+			return Code(i), nil
+		}
+	}
+
+	if len(s) == 0 {
+		return NoError, nil
+	}
+
+	if value, err := strconv.Atoi(s); err == nil {
+		if code := Code(value); (code >= 0) && (code < MaxReserved) {
+			return code, nil
+		}
+	}
+
+	return 0, errUnknownCode
+}
+
+// Category groups codes by the party that can fix the failure
+type Category int
+
+const (
+	// CategoryUnknown is used for codes not known to this version of the package
+	CategoryUnknown Category = iota
+	// CategorySuccess means verification succeeded
+	CategorySuccess
+	// CategoryUser means the problem is with the solution (e.g. invalid, expired or reused) and user can solve the puzzle again
+	CategoryUser
+	// CategoryConfig means the problem is with the property or API key configuration
+	CategoryConfig
+	// CategoryService means the problem is on the side of the captcha service
+	CategoryService
+)
+
+func (c Category) String() string {
+	switch c {
+	case CategorySuccess:
+		return "success"
+	case CategoryUser:
+		return "user"
+	case CategoryConfig:
+		return "config"
+	case CategoryService:
+		return "service"
+	default:
+		return "unknown"
+	}
+}
+
+// Description contains semantics of the code, so that applications can handle codes by category
+// instead of switching over individual codes
+type Description struct {
+	Code     Code
+	Category Category
+	// Retriable is true if the same verification may succeed if retried later
+	Retriable bool
+	// UserActionable is true if the end user can fix the problem by solving a new puzzle
+	UserActionable bool
+}
+
+var descriptions = [Count]Description{
+	NoError:            {Category: CategorySuccess},
+	ErrorOther:         {Category: CategoryService, Retriable: true},
+	DuplicateSolutions: {Category: CategoryUser, UserActionable: true},
+	InvalidSolution:    {Category: CategoryUser, UserActionable: true},
+	ParseResponse:      {Category: CategoryUser, UserActionable: true},
+	PuzzleExpired:      {Category: CategoryUser, UserActionable: true},
+	InvalidProperty:    {Category: CategoryConfig},
+	WrongOwner:         {Category: CategoryConfig},
+	VerifiedBefore:     {Category: CategoryUser, UserActionable: true},
+	MaintenanceMode:    {Category: CategoryService, Retriable: true},
+	TestProperty:       {Category: CategoryConfig},
+	Integrity:          {Category: CategoryService},
+	OrgScope:           {Category: CategoryConfig},
+}
+
+// Describe returns semantics of the code. Codes not known to this version of the package
+// are described with CategoryUnknown.
+func Describe(c Code) Description {
+	if !c.IsKnown() {
+		return Description{Code: c, Category: CategoryUnknown}
+	}
+
+	d := descriptions[c]
+	d.Code = c
+
+	return d
+}
@@ -0,0 +1,66 @@
+package codes
+
+import "testing"
+
+func TestStableValues(t *testing.T) {
+	if (OrgScope != 12) || (Count != 13) {
+		t.Errorf("Numeric values of codes must never change")
+	}
+}
+
+func TestParse(t *testing.T) {
+	for code := NoError; code < Count; code++ {
+		parsed, err := Parse(code.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed != code {
+			t.Errorf("Unexpected parsed code %v for %v", parsed, code)
+		}
+	}
+
+	if code, err := Parse("123"); (err != nil) || (code != 123) || code.IsKnown() {
+		t.Errorf("Unexpected result for reserved code: %v (%v)", code, err)
+	}
+
+	if _, err := Parse("something"); err == nil {
+		t.Error("Expected error for unknown code")
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	for code := NoError; code < Count; code++ {
+		if d := Describe(code); (d.Code != code) || (d.Category == CategoryUnknown) {
+			t.Errorf("Code %v is not described", code)
+		}
+	}
+
+	if d := Describe(PuzzleExpired); (d.Category != CategoryUser) || !d.UserActionable || d.Retriable {
+		t.Errorf("Unexpected description: %+v", d)
+	}
+
+	if d := Describe(Code(123)); (d.Category != CategoryUnknown) || (d.Code != 123) {
+		t.Errorf("Unexpected description of unknown code: %+v", d)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add("solution-invalid")
+	f.Add("123")
+	f.Add("-1")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		code, err := Parse(s)
+		if err != nil {
+			return
+		}
+
+		if (code < 0) || (code >= MaxReserved) {
+			t.Errorf("Unexpected code %v parsed from %q", code, s)
+		}
+
+		_ = code.String()
+		_ = Describe(code)
+	})
+}
@@ -0,0 +1,273 @@
+// Package analyzer provides a static analysis pass that flags HTTP handlers registered on "protected"
+// patterns (e.g. signup or comment endpoints) that don't pass through captcha verification.
+//
+// Handler is considered protected if the handler expression passed to http.Handle, http.HandleFunc or
+// the same methods of *http.ServeMux:
+//   - is wrapped with Client.Middleware(), Client.VerifyFunc() or AuthFlowGuard.LoginMiddleware()
+//     (directly or via a variable assigned from their result), or
+//   - is a function (literal or declared in the same package) that calls RequireVerified(),
+//     IsVerified() or one of Client.Verify* methods.
+//
+// Analysis is intentionally shallow (it does not follow handlers across packages), so it can be used
+// as a lint step in CI with go vet -vettool or with multichecker.
+package analyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const (
+	pcPackage   = "github.com/PrivateCaptcha/private-captcha-go"
+	httpPackage = "net/http"
+)
+
+var (
+	// functions and methods of the SDK that wrap handlers with verification
+	wrappers = map[string]bool{
+		"Middleware":      true,
+		"VerifyFunc":      true,
+		"LoginMiddleware": true,
+	}
+	// functions and methods of the SDK that verify request inside the handler
+	verifiers = map[string]bool{
+		"RequireVerified": true,
+		"IsVerified":      true,
+		"Verify":          true,
+		"VerifyRequest":   true,
+		"VerifyNative":    true,
+		"VerifyLogin":     true,
+	}
+	registrations = map[string]bool{
+		"Handle":     true,
+		"HandleFunc": true,
+	}
+)
+
+var protected string
+
+// Analyzer reports handlers registered on protected patterns without captcha verification
+var Analyzer = &analysis.Analyzer{
+	Name:     "pcprotected",
+	Doc:      "report HTTP handlers registered on protected patterns that do not verify captcha",
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+func init() {
+	Analyzer.Flags.StringVar(&protected, "protected", "",
+		`comma-separated list of protected patterns, e.g. "POST /signup,/comments/*" (trailing * matches any suffix)`)
+}
+
+// matchPattern checks if registered mux pattern (optionally with method, e.g. "POST /signup") matches protected pattern
+func matchPattern(registered, pattern string) bool {
+	if !strings.Contains(pattern, " ") {
+		if i := strings.IndexByte(registered, ' '); i != -1 {
+			registered = strings.TrimSpace(registered[i+1:])
+		}
+	}
+
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(registered, prefix)
+	}
+
+	return registered == pattern
+}
+
+func isProtectedPattern(registered string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(registered, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// calleeOf returns function or method called by the call expression (nil for calls of func values)
+func calleeOf(info *types.Info, call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
+	}
+
+	fn, _ := info.Uses[ident].(*types.Func)
+	return fn
+}
+
+func isSDKFunc(fn *types.Func, names map[string]bool) bool {
+	return (fn != nil) && (fn.Pkg() != nil) && (fn.Pkg().Path() == pcPackage) && names[fn.Name()]
+}
+
+type checker struct {
+	pass *analysis.Pass
+	// values assigned to local and package variables, to follow `mw := client.Middleware(...)`
+	values map[types.Object]ast.Expr
+	// bodies of functions declared in the package, to follow `mux.HandleFunc("/", handler)`
+	funcs map[types.Object]*ast.FuncDecl
+}
+
+func (c *checker) collect(files []*ast.File) {
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if obj := c.pass.TypesInfo.Defs[n.Name]; obj != nil {
+					c.funcs[obj] = n
+				}
+			case *ast.AssignStmt:
+				if len(n.Lhs) == len(n.Rhs) {
+					for i, lhs := range n.Lhs {
+						if ident, ok := lhs.(*ast.Ident); ok {
+							if obj := c.pass.TypesInfo.ObjectOf(ident); obj != nil {
+								c.values[obj] = n.Rhs[i]
+							}
+						}
+					}
+				}
+			case *ast.ValueSpec:
+				if len(n.Names) == len(n.Values) {
+					for i, name := range n.Names {
+						if obj := c.pass.TypesInfo.Defs[name]; obj != nil {
+							c.values[obj] = n.Values[i]
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+}
+
+// verifies returns true if node contains calls of SDK functions that verify captcha
+func (c *checker) verifies(node ast.Node) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && isSDKFunc(calleeOf(c.pass.TypesInfo, call), verifiers) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isProtected checks if handler expression verifies captcha. visited prevents infinite recursion on cyclic assignments.
+func (c *checker) isProtected(expr ast.Expr, visited map[types.Object]bool) bool {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.CallExpr:
+		if fn := calleeOf(c.pass.TypesInfo, e); isSDKFunc(fn, wrappers) {
+			return true
+		}
+
+		// handler wrapped with middleware stored in a variable, e.g. mw(handler)
+		if c.isProtected(e.Fun, visited) {
+			return true
+		}
+
+		// handler converted or wrapped with other middleware, e.g. http.HandlerFunc(handler) or logging(handler)
+		for _, arg := range e.Args {
+			if c.isProtected(arg, visited) {
+				return true
+			}
+		}
+	case *ast.FuncLit:
+		return c.verifies(e.Body)
+	case *ast.Ident, *ast.SelectorExpr:
+		var ident *ast.Ident
+		if sel, ok := e.(*ast.SelectorExpr); ok {
+			ident = sel.Sel
+		} else {
+			ident = e.(*ast.Ident)
+		}
+
+		obj := c.pass.TypesInfo.ObjectOf(ident)
+		if (obj == nil) || visited[obj] {
+			return false
+		}
+		visited[obj] = true
+
+		if fn, ok := c.funcs[obj]; ok {
+			return (fn.Body != nil) && c.verifies(fn.Body)
+		}
+
+		if value, ok := c.values[obj]; ok {
+			return c.isProtected(value, visited)
+		}
+	}
+
+	return false
+}
+
+func isRegistration(fn *types.Func) bool {
+	if (fn == nil) || (fn.Pkg() == nil) || (fn.Pkg().Path() != httpPackage) || !registrations[fn.Name()] {
+		return false
+	}
+
+	// http.Handle(), http.HandleFunc() or (*http.ServeMux) methods
+	sig := fn.Type().(*types.Signature)
+	if recv := sig.Recv(); recv != nil {
+		ptr, ok := recv.Type().(*types.Pointer)
+		if !ok {
+			return false
+		}
+		named, ok := ptr.Elem().(*types.Named)
+		return ok && (named.Obj().Name() == "ServeMux")
+	}
+
+	return true
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	var patterns []string
+	for _, p := range strings.Split(protected, ",") {
+		if p = strings.TrimSpace(p); len(p) > 0 {
+			patterns = append(patterns, p)
+		}
+	}
+
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	c := &checker{
+		pass:   pass,
+		values: make(map[types.Object]ast.Expr),
+		funcs:  make(map[types.Object]*ast.FuncDecl),
+	}
+	c.collect(pass.Files)
+
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		if (len(call.Args) != 2) || !isRegistration(calleeOf(pass.TypesInfo, call)) {
+			return
+		}
+
+		tv, ok := pass.TypesInfo.Types[call.Args[0]]
+		if !ok || (tv.Value == nil) || (tv.Value.Kind() != constant.String) {
+			return
+		}
+
+		pattern := constant.StringVal(tv.Value)
+		if !isProtectedPattern(pattern, patterns) {
+			return
+		}
+
+		if !c.isProtected(call.Args[1], make(map[types.Object]bool)) {
+			pass.Reportf(call.Args[1].Pos(), "handler for protected pattern %q does not verify captcha", pattern)
+		}
+	})
+
+	return nil, nil
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	if err := Analyzer.Flags.Set("protected", "/signup*,/comments/*"); err != nil {
+		t.Fatal(err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "handlers")
+}
+
+func TestMatchPattern(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		registered string
+		pattern    string
+		match      bool
+	}{
+		{"/signup", "/signup", true},
+		{"POST /signup", "/signup", true},
+		{"POST /signup", "POST /signup", true},
+		{"GET /signup", "POST /signup", false},
+		{"/signup/v2", "/signup", false},
+		{"/signup/v2", "/signup*", true},
+		{"/about", "/signup*", false},
+	}
+
+	for _, tc := range testCases {
+		if matchPattern(tc.registered, tc.pattern) != tc.match {
+			t.Errorf("Unexpected match of %q with %q", tc.registered, tc.pattern)
+		}
+	}
+}
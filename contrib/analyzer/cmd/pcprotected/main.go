@@ -0,0 +1,15 @@
+// Command pcprotected reports HTTP handlers registered on protected patterns without captcha verification.
+//
+// Usage:
+//
+//	go vet -vettool=$(which pcprotected) -pcprotected.protected="POST /signup,/comments/*" ./...
+package main
+
+import (
+	"github.com/PrivateCaptcha/private-captcha-go/contrib/analyzer"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
@@ -0,0 +1,19 @@
+// Package privatecaptcha is a minimal stub of the SDK for analyzer tests
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+)
+
+type Client struct{}
+
+type MiddlewareOptions struct{}
+
+func (c *Client) Middleware(opts MiddlewareOptions) func(http.Handler) http.Handler { return nil }
+
+func (c *Client) VerifyFunc(next http.Handler) http.Handler { return next }
+
+func (c *Client) VerifyRequest(ctx context.Context, r *http.Request) error { return nil }
+
+func RequireVerified(ctx context.Context) error { return nil }
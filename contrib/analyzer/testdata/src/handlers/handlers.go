@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+func signup(w http.ResponseWriter, r *http.Request) {}
+
+func comment(w http.ResponseWriter, r *http.Request) {
+	if err := pc.RequireVerified(r.Context()); err != nil {
+		return
+	}
+}
+
+func register(client *pc.Client) {
+	mux := http.NewServeMux()
+	mw := client.Middleware(pc.MiddlewareOptions{})
+
+	mux.HandleFunc("POST /signup", signup) // want `handler for protected pattern "POST /signup" does not verify captcha`
+	mux.Handle("POST /signup/v2", mw(http.HandlerFunc(signup)))
+	mux.Handle("/signup/v3", client.VerifyFunc(http.HandlerFunc(signup)))
+	mux.HandleFunc("/comments/new", comment)
+	mux.HandleFunc("/comments/edit", func(w http.ResponseWriter, r *http.Request) {}) // want `handler for protected pattern "/comments/edit" does not verify captcha`
+	mux.HandleFunc("/comments/delete", func(w http.ResponseWriter, r *http.Request) {
+		client.VerifyRequest(r.Context(), r)
+	})
+	mux.HandleFunc("/about", signup)
+
+	http.Handle("/signup", http.HandlerFunc(signup)) // want `handler for protected pattern "/signup" does not verify captcha`
+}
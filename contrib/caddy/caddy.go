@@ -0,0 +1,163 @@
+// Package pccaddy provides Private Captcha verification as a Caddy HTTP handler module, so that applications
+// behind Caddy can be protected at the proxy layer without changing their code.
+//
+// Caddyfile:
+//
+//	private_captcha {
+//		api_key {env.PC_API_KEY}
+//		domain api.eu.privatecaptcha.com
+//		form_field private-captcha-solution
+//		fail_status 403
+//	}
+//
+// The directive is ordered before reverse_proxy, so it can be used in site blocks without "order" or "route".
+package pccaddy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+	"github.com/caddyserver/caddy/v2"
+	"github.com/caddyserver/caddy/v2/caddyconfig/caddyfile"
+	"github.com/caddyserver/caddy/v2/caddyconfig/httpcaddyfile"
+	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
+)
+
+var errEmptyAPIKey = errors.New("pccaddy: api_key is required")
+
+func init() {
+	caddy.RegisterModule(Handler{})
+	httpcaddyfile.RegisterHandlerDirective("private_captcha", parseCaddyfile)
+	httpcaddyfile.RegisterDirectiveOrder("private_captcha", httpcaddyfile.Before, "reverse_proxy")
+}
+
+// Handler verifies captcha solution of the request and passes it to the next handler only if it succeeded
+type Handler struct {
+	// API key, placeholders (e.g. {env.PC_API_KEY}) are replaced
+	APIKey string `json:"api_key,omitempty"`
+	// (optional) API domain for EU or self-hosted deployments
+	Domain string `json:"domain,omitempty"`
+	// (optional) Form field to read the solution from (defaults to privatecaptcha.DefaultFormField)
+	FormField string `json:"form_field,omitempty"`
+	// (optional) http status to return for failed verifications (defaults to http.StatusForbidden)
+	FailStatus int `json:"fail_status,omitempty"`
+
+	client     *pc.Client
+	middleware func(http.Handler) http.Handler
+}
+
+// CaddyModule returns the Caddy module information
+func (Handler) CaddyModule() caddy.ModuleInfo {
+	return caddy.ModuleInfo{
+		ID:  "http.handlers.private_captcha",
+		New: func() caddy.Module { return new(Handler) },
+	}
+}
+
+// Provision creates the client
+func (h *Handler) Provision(ctx caddy.Context) error {
+	repl := caddy.NewReplacer()
+
+	return h.provision(pc.Configuration{
+		APIKey:           repl.ReplaceAll(h.APIKey, ""),
+		Domain:           repl.ReplaceAll(h.Domain, ""),
+		FormField:        h.FormField,
+		FailedStatusCode: h.FailStatus,
+		Logger:           ctx.Slogger(),
+	})
+}
+
+func (h *Handler) provision(cfg pc.Configuration) error {
+	client, err := pc.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	h.client = client
+	h.middleware = client.Middleware(pc.MiddlewareOptions{})
+
+	return nil
+}
+
+// Validate checks configuration of the handler
+func (h *Handler) Validate() error {
+	if len(h.APIKey) == 0 {
+		return errEmptyAPIKey
+	}
+
+	return nil
+}
+
+// Cleanup releases resources of the client
+func (h *Handler) Cleanup() error {
+	if h.client == nil {
+		return nil
+	}
+
+	return h.client.Close(context.Background())
+}
+
+// ServeHTTP implements caddyhttp.MiddlewareHandler
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request, next caddyhttp.Handler) error {
+	var nextErr error
+	h.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextErr = next.ServeHTTP(w, r)
+	})).ServeHTTP(w, r)
+
+	return nextErr
+}
+
+// UnmarshalCaddyfile sets up the handler from Caddyfile tokens
+func (h *Handler) UnmarshalCaddyfile(d *caddyfile.Dispenser) error {
+	d.Next() // consume directive name
+
+	if d.NextArg() {
+		return d.ArgErr()
+	}
+
+	for d.NextBlock(0) {
+		subdirective := d.Val()
+
+		var value string
+		if !d.Args(&value) {
+			return d.ArgErr()
+		}
+
+		switch subdirective {
+		case "api_key":
+			h.APIKey = value
+		case "domain":
+			h.Domain = value
+		case "form_field":
+			h.FormField = value
+		case "fail_status":
+			status, err := strconv.Atoi(value)
+			if (err != nil) || (status < 400) || (status > 599) {
+				return d.Errf("invalid fail_status %q", value)
+			}
+			h.FailStatus = status
+		default:
+			return d.Errf("unrecognized private_captcha subdirective %q", subdirective)
+		}
+	}
+
+	return nil
+}
+
+func parseCaddyfile(h httpcaddyfile.Helper) (caddyhttp.MiddlewareHandler, error) {
+	handler := new(Handler)
+	err := handler.UnmarshalCaddyfile(h.Dispenser)
+	return handler, err
+}
+
+// Interface guards
+var (
+	_ caddy.Provisioner           = (*Handler)(nil)
+	_ caddy.Validator             = (*Handler)(nil)
+	_ caddy.CleanerUpper          = (*Handler)(nil)
+	_ caddyhttp.MiddlewareHandler = (*Handler)(nil)
+	_ caddyfile.Unmarshaler       = (*Handler)(nil)
+)
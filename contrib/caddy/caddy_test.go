@@ -0,0 +1,122 @@
+package pccaddy
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+	"github.com/caddyserver/caddy/v2/caddyconfig/caddyfile"
+	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
+)
+
+func TestUnmarshalCaddyfile(t *testing.T) {
+	t.Parallel()
+
+	d := caddyfile.NewTestDispenser(`private_captcha {
+		api_key {env.PC_API_KEY}
+		domain api.eu.privatecaptcha.com
+		form_field captcha
+		fail_status 429
+	}`)
+
+	var h Handler
+	if err := h.UnmarshalCaddyfile(d); err != nil {
+		t.Fatal(err)
+	}
+
+	if (h.APIKey != "{env.PC_API_KEY}") || (h.Domain != pc.EUDomain) || (h.FormField != "captcha") || (h.FailStatus != http.StatusTooManyRequests) {
+		t.Errorf("Unexpected handler: %+v", h)
+	}
+
+	if err := h.Validate(); err != nil {
+		t.Errorf("Unexpected validation error: %v", err)
+	}
+}
+
+func TestUnmarshalCaddyfileErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []string{
+		`private_captcha extra`,
+		`private_captcha {
+			api_key
+		}`,
+		`private_captcha {
+			fail_status 200
+		}`,
+		`private_captcha {
+			unknown value
+		}`,
+	}
+
+	for i, tc := range testCases {
+		var h Handler
+		if err := h.UnmarshalCaddyfile(caddyfile.NewTestDispenser(tc)); err == nil {
+			t.Errorf("Expected error for test case %v", i)
+		}
+	}
+
+	if err := (&Handler{}).Validate(); err != errEmptyAPIKey {
+		t.Errorf("Unexpected validation error: %v", err)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "good" {
+			w.Write([]byte(`{"success":true,"code":0}`))
+		} else {
+			w.Write([]byte(`{"success":false,"code":3}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	h := &Handler{FailStatus: http.StatusTeapot}
+	if err := h.provision(pc.Configuration{APIKey: "test-api-key", Domain: srv.URL, Client: srv.Client(), FailedStatusCode: h.FailStatus}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.Cleanup() })
+
+	nextErr := errors.New("next handler error")
+	next := caddyhttp.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nextErr
+	})
+
+	testCases := []struct {
+		solution string
+		status   int
+		err      error
+	}{
+		{"good", http.StatusOK, nextErr},
+		{"bad", http.StatusTeapot, nil},
+		{"", http.StatusTeapot, nil},
+	}
+
+	for i, tc := range testCases {
+		form := url.Values{}
+		if len(tc.solution) > 0 {
+			form.Set(pc.DefaultFormField, tc.solution)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		err := h.ServeHTTP(w, req, next)
+		if err != tc.err {
+			t.Errorf("Unexpected error for test case %v: %v", i, err)
+		}
+		if w.Code != tc.status {
+			t.Errorf("Unexpected status for test case %v: %v", i, w.Code)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package pcfasthttp provides Private Captcha verification middleware for fasthttp.
+//
+// It works with fasthttp.RequestCtx directly and does not convert requests to net/http.
+package pcfasthttp
+
+import (
+	"encoding/json"
+	"strings"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+	"github.com/valyala/fasthttp"
+)
+
+// Extractor reads captcha solution from the request. Empty solution means it was not found.
+type Extractor func(ctx *fasthttp.RequestCtx) (string, error)
+
+// Options configures the middleware. Sources of the solution are tried in order: Extractor, form field
+// (including query and multipart), header, cookie and JSON body.
+type Options struct {
+	// (optional) Form field to read the solution from (defaults to privatecaptcha.DefaultFormField)
+	FormField string
+	// (optional) Request header to read the solution from
+	Header string
+	// (optional) Cookie to read the solution from
+	Cookie string
+	// (optional) Dot-separated path of the solution in JSON body (e.g. "captcha.solution")
+	JSONPath string
+	// (optional) Custom extractor tried before all other sources
+	Extractor Extractor
+	// (optional) http status to return for failed verifications (defaults to fasthttp.StatusForbidden)
+	FailedStatusCode int
+}
+
+func lookupJSON(data []byte, path string) (string, error) {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", err
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return "", nil
+		}
+
+		if value, ok = obj[key]; !ok {
+			return "", nil
+		}
+	}
+
+	solution, _ := value.(string)
+	return solution, nil
+}
+
+func (o *Options) extract(ctx *fasthttp.RequestCtx) (string, error) {
+	if o.Extractor != nil {
+		solution, err := o.Extractor(ctx)
+		if (err != nil) || (len(solution) > 0) {
+			return solution, err
+		}
+	}
+
+	if value := ctx.FormValue(o.FormField); len(value) > 0 {
+		return string(value), nil
+	}
+
+	if len(o.Header) > 0 {
+		if value := ctx.Request.Header.Peek(o.Header); len(value) > 0 {
+			return string(value), nil
+		}
+	}
+
+	if len(o.Cookie) > 0 {
+		if value := ctx.Request.Header.Cookie(o.Cookie); len(value) > 0 {
+			return string(value), nil
+		}
+	}
+
+	if (len(o.JSONPath) > 0) && strings.HasPrefix(string(ctx.Request.Header.ContentType()), "application/json") {
+		return lookupJSON(ctx.PostBody(), o.JSONPath)
+	}
+
+	return "", nil
+}
+
+// Verify extracts the solution from the request and verifies it
+func Verify(client *pc.Client, opts Options, ctx *fasthttp.RequestCtx) (*pc.VerifyOutput, error) {
+	if len(opts.FormField) == 0 {
+		opts.FormField = pc.DefaultFormField
+	}
+
+	solution, err := opts.extract(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Verify(ctx, pc.VerifyInput{Solution: solution})
+}
+
+// Middleware wraps fasthttp handler with captcha verification
+func Middleware(client *pc.Client, opts Options, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if len(opts.FormField) == 0 {
+		opts.FormField = pc.DefaultFormField
+	}
+
+	if opts.FailedStatusCode == 0 {
+		opts.FailedStatusCode = fasthttp.StatusForbidden
+	}
+
+	return func(ctx *fasthttp.RequestCtx) {
+		output, err := Verify(client, opts, ctx)
+		if (err != nil) || !output.OK() {
+			ctx.Error(fasthttp.StatusMessage(opts.FailedStatusCode), opts.FailedStatusCode)
+			return
+		}
+
+		next(ctx)
+	}
+}
@@ -0,0 +1,71 @@
+package pcfasthttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+	"github.com/valyala/fasthttp"
+)
+
+func newTestClient(t *testing.T) *pc.Client {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "good" {
+			w.Write([]byte(`{"success":true,"code":0}`))
+		} else {
+			w.Write([]byte(`{"success":false,"code":3}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := pc.NewClient(pc.Configuration{APIKey: "test-api-key", Domain: srv.URL, Client: srv.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestMiddleware(t *testing.T) {
+	client := newTestClient(t)
+
+	handler := Middleware(client, Options{Header: "X-Captcha", JSONPath: "captcha"}, func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	testCases := []struct {
+		setup  func(req *fasthttp.Request)
+		status int
+	}{
+		{func(req *fasthttp.Request) {
+			req.Header.SetContentType("application/x-www-form-urlencoded")
+			req.SetBodyString(pc.DefaultFormField + "=good")
+		}, fasthttp.StatusOK},
+		{func(req *fasthttp.Request) { req.Header.Set("X-Captcha", "good") }, fasthttp.StatusOK},
+		{func(req *fasthttp.Request) {
+			req.Header.SetContentType("application/json")
+			req.SetBodyString(`{"captcha":"good"}`)
+		}, fasthttp.StatusOK},
+		{func(req *fasthttp.Request) { req.Header.Set("X-Captcha", "bad") }, fasthttp.StatusForbidden},
+		{func(req *fasthttp.Request) {}, fasthttp.StatusForbidden},
+	}
+
+	for i, tc := range testCases {
+		var req fasthttp.Request
+		req.Header.SetMethod(fasthttp.MethodPost)
+		req.SetRequestURI("/test")
+		tc.setup(&req)
+
+		var ctx fasthttp.RequestCtx
+		ctx.Init(&req, nil, nil)
+
+		handler(&ctx)
+
+		if ctx.Response.StatusCode() != tc.status {
+			t.Errorf("Unexpected status in test case %d: %d", i, ctx.Response.StatusCode())
+		}
+	}
+}
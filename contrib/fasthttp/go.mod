@@ -0,0 +1,17 @@
+module github.com/PrivateCaptcha/private-captcha-go/contrib/fasthttp
+
+go 1.24.2
+
+replace github.com/PrivateCaptcha/private-captcha-go => ../..
+
+require (
+	github.com/PrivateCaptcha/private-captcha-go v0.0.0-00010101000000-000000000000
+	github.com/valyala/fasthttp v1.65.0
+)
+
+require (
+	github.com/andybalholm/brotli v1.2.0 // indirect
+	github.com/jpillora/backoff v1.0.0 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+)
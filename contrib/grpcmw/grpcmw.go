@@ -0,0 +1,104 @@
+// Package grpcmw provides Private Captcha verification interceptors for gRPC servers.
+//
+// Solution is read from incoming metadata (e.g. set by the web app or a gRPC-Web proxy from the widget) and
+// verified before the handler is called.
+package grpcmw
+
+import (
+	"context"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultMetadataKey is the metadata key of the captcha solution
+	DefaultMetadataKey = "x-captcha-solution"
+)
+
+// Options configures the interceptors
+type Options struct {
+	// (optional) Metadata key to read the solution from (defaults to DefaultMetadataKey)
+	MetadataKey string
+	// (optional) Filter reports whether the method (e.g. "/signup.v1.SignupService/Register") requires verification
+	// (defaults to all methods)
+	Filter func(ctx context.Context, fullMethod string) bool
+}
+
+func (o *Options) metadataKey() string {
+	if len(o.MetadataKey) > 0 {
+		return o.MetadataKey
+	}
+
+	return DefaultMetadataKey
+}
+
+// verify returns the context marked with privatecaptcha.WithVerified() if the solution from metadata is valid
+func (o *Options) verify(ctx context.Context, verifier pc.SolutionVerifier, fullMethod string) (context.Context, error) {
+	if (o.Filter != nil) && !o.Filter(ctx, fullMethod) {
+		return ctx, nil
+	}
+
+	var solution string
+	if values := metadata.ValueFromIncomingContext(ctx, o.metadataKey()); len(values) > 0 {
+		solution = values[0]
+	}
+
+	if len(solution) == 0 {
+		return nil, status.Error(codes.PermissionDenied, "captcha solution is missing")
+	}
+
+	output, err := verifier.Verify(ctx, pc.VerifyInput{Solution: solution})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+
+		return nil, status.Error(codes.Unavailable, "captcha verification is unavailable")
+	}
+
+	if !output.OK() {
+		return nil, status.Error(codes.PermissionDenied, "captcha verification failed")
+	}
+
+	return pc.WithVerified(ctx), nil
+}
+
+// UnaryServerInterceptor verifies captcha solution from incoming metadata and returns codes.PermissionDenied
+// if it's missing or invalid (codes.Unavailable if it could not be verified)
+func UnaryServerInterceptor(verifier pc.SolutionVerifier, opts Options) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := opts.verify(ctx, verifier, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// serverStream overrides context of the stream with the verified one
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// StreamServerInterceptor verifies captcha solution from incoming metadata once, when the stream is opened,
+// same as UnaryServerInterceptor
+func StreamServerInterceptor(verifier pc.SolutionVerifier, opts Options) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := opts.verify(ss.Context(), verifier, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
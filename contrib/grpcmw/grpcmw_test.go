@@ -0,0 +1,107 @@
+package grpcmw
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type stubVerifier struct {
+	err error
+}
+
+func (v *stubVerifier) Verify(ctx context.Context, input pc.VerifyInput) (*pc.VerifyOutput, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+
+	if input.Solution == "good" {
+		return &pc.VerifyOutput{Success: true, Code: pc.VerifyNoError}, nil
+	}
+
+	return &pc.VerifyOutput{Success: false, Code: pc.ParseResponseError}, nil
+}
+
+type stubStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *stubStream) Context() context.Context {
+	return s.ctx
+}
+
+func incomingContext(kv ...string) context.Context {
+	return metadata.NewIncomingContext(context.TODO(), metadata.Pairs(kv...))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	interceptor := UnaryServerInterceptor(&stubVerifier{}, Options{
+		Filter: func(ctx context.Context, fullMethod string) bool { return fullMethod != "/test.Service/Public" },
+	})
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		return pc.IsVerified(ctx), nil
+	}
+
+	testCases := []struct {
+		ctx      context.Context
+		method   string
+		code     codes.Code
+		verified bool
+	}{
+		{incomingContext(DefaultMetadataKey, "good"), "/test.Service/Signup", codes.OK, true},
+		{incomingContext(DefaultMetadataKey, "bad"), "/test.Service/Signup", codes.PermissionDenied, false},
+		{incomingContext(), "/test.Service/Signup", codes.PermissionDenied, false},
+		{context.TODO(), "/test.Service/Signup", codes.PermissionDenied, false},
+		{incomingContext(), "/test.Service/Public", codes.OK, false},
+	}
+
+	for i, tc := range testCases {
+		resp, err := interceptor(tc.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+		if status.Code(err) != tc.code {
+			t.Errorf("Unexpected code (%v): %v", i, err)
+			continue
+		}
+
+		if (err == nil) && (resp != tc.verified) {
+			t.Errorf("Unexpected verified (%v): %v", i, resp)
+		}
+	}
+
+	interceptor = UnaryServerInterceptor(&stubVerifier{err: errors.New("network error")}, Options{})
+	if _, err := interceptor(incomingContext(DefaultMetadataKey, "good"), nil, &grpc.UnaryServerInfo{}, handler); status.Code(err) != codes.Unavailable {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	const key = "captcha"
+	interceptor := StreamServerInterceptor(&stubVerifier{}, Options{MetadataKey: key})
+
+	var verified bool
+	handler := func(srv any, stream grpc.ServerStream) error {
+		verified = pc.IsVerified(stream.Context())
+		return nil
+	}
+
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Chat"}
+
+	if err := interceptor(nil, &stubStream{ctx: incomingContext(key, "good")}, info, handler); (err != nil) || !verified {
+		t.Errorf("Unexpected result: %v (verified=%v)", err, verified)
+	}
+
+	if err := interceptor(nil, &stubStream{ctx: incomingContext(DefaultMetadataKey, "good")}, info, handler); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
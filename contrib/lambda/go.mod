@@ -0,0 +1,12 @@
+module github.com/PrivateCaptcha/private-captcha-go/contrib/lambda
+
+go 1.24.2
+
+replace github.com/PrivateCaptcha/private-captcha-go => ../..
+
+require (
+	github.com/PrivateCaptcha/private-captcha-go v0.0.0-00010101000000-000000000000
+	github.com/aws/aws-lambda-go v1.49.0
+)
+
+require github.com/jpillora/backoff v1.0.0 // indirect
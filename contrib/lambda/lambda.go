@@ -0,0 +1,171 @@
+// Package pclambda provides Private Captcha verification of AWS Lambda events of API Gateway REST API (v1)
+// and HTTP API (v2) payloads.
+//
+// Events are converted to *http.Request (with base64-encoded bodies decoded), so the solution is extracted
+// from form (urlencoded or multipart), JSON body, headers or cookies with the same privatecaptcha.Extractor
+// as in net/http handlers.
+package pclambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var (
+	// ErrVerificationFailed is returned when captcha solution is missing or invalid
+	ErrVerificationFailed = errors.New("pclambda: captcha verification failed")
+)
+
+// Options configures the verifier
+type Options struct {
+	// (optional) Extractor of the solution (defaults to form field and JSON body property
+	// privatecaptcha.DefaultFormField)
+	Extractor pc.Extractor
+	// (optional) Sitekey to verify solution against
+	Sitekey string
+}
+
+// Verifier verifies captcha solutions of API Gateway events
+type Verifier struct {
+	verifier  pc.SolutionVerifier
+	extractor pc.Extractor
+	sitekey   string
+}
+
+// New creates Verifier with the client (usually *privatecaptcha.Client)
+func New(verifier pc.SolutionVerifier, opts Options) *Verifier {
+	extractor := opts.Extractor
+	if extractor == nil {
+		extractor = pc.Extractors{pc.FromForm(pc.DefaultFormField), pc.FromJSON(pc.DefaultFormField)}
+	}
+
+	return &Verifier{verifier: verifier, extractor: extractor, sitekey: opts.Sitekey}
+}
+
+func decodeBody(body string, isBase64 bool) ([]byte, error) {
+	if isBase64 {
+		return base64.StdEncoding.DecodeString(body)
+	}
+
+	return []byte(body), nil
+}
+
+func newRequest(ctx context.Context, method, path, query string, body []byte) (*http.Request, error) {
+	r, err := http.NewRequestWithContext(ctx, method, (&url.URL{Path: path, RawQuery: query}).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	r.RequestURI = r.URL.RequestURI()
+
+	return r, nil
+}
+
+// NewAPIGatewayRequest converts API Gateway REST API (v1 payload) event to *http.Request
+func NewAPIGatewayRequest(ctx context.Context, event events.APIGatewayProxyRequest) (*http.Request, error) {
+	body, err := decodeBody(event.Body, event.IsBase64Encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	for k, v := range event.QueryStringParameters {
+		query.Set(k, v)
+	}
+	for k, values := range event.MultiValueQueryStringParameters {
+		query[k] = values
+	}
+
+	r, err := newRequest(ctx, event.HTTPMethod, event.Path, query.Encode(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range event.Headers {
+		r.Header.Set(k, v)
+	}
+	for k, values := range event.MultiValueHeaders {
+		r.Header.Del(k)
+		for _, v := range values {
+			r.Header.Add(k, v)
+		}
+	}
+
+	return r, nil
+}
+
+// NewAPIGatewayV2Request converts API Gateway HTTP API (v2 payload) event to *http.Request
+func NewAPIGatewayV2Request(ctx context.Context, event events.APIGatewayV2HTTPRequest) (*http.Request, error) {
+	body, err := decodeBody(event.Body, event.IsBase64Encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := newRequest(ctx, event.RequestContext.HTTP.Method, event.RawPath, event.RawQueryString, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range event.Headers {
+		r.Header.Set(k, v)
+	}
+
+	// HTTP API moves cookies out of headers
+	if len(event.Cookies) > 0 {
+		r.Header.Set("Cookie", strings.Join(event.Cookies, "; "))
+	}
+
+	return r, nil
+}
+
+func (v *Verifier) verify(ctx context.Context, r *http.Request) (*pc.VerifyOutput, error) {
+	solution, err := v.extractor.Extract(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(solution) == 0 {
+		return nil, ErrVerificationFailed
+	}
+
+	output, err := v.verifier.Verify(ctx, pc.VerifyInput{Solution: solution, Sitekey: v.sitekey})
+	if err != nil {
+		return output, err
+	}
+
+	if !output.OK() {
+		return output, ErrVerificationFailed
+	}
+
+	return output, nil
+}
+
+// VerifyAPIGatewayRequest extracts the solution from API Gateway REST API (v1 payload) event and verifies it.
+// ErrVerificationFailed is returned if the solution is missing or invalid.
+func (v *Verifier) VerifyAPIGatewayRequest(ctx context.Context, event events.APIGatewayProxyRequest) (*pc.VerifyOutput, error) {
+	r, err := NewAPIGatewayRequest(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.verify(ctx, r)
+}
+
+// VerifyAPIGatewayV2Request extracts the solution from API Gateway HTTP API (v2 payload) event and verifies it.
+// ErrVerificationFailed is returned if the solution is missing or invalid.
+func (v *Verifier) VerifyAPIGatewayV2Request(ctx context.Context, event events.APIGatewayV2HTTPRequest) (*pc.VerifyOutput, error) {
+	r, err := NewAPIGatewayV2Request(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.verify(ctx, r)
+}
@@ -0,0 +1,93 @@
+package pclambda
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type stubVerifier struct{}
+
+func (v *stubVerifier) Verify(ctx context.Context, input pc.VerifyInput) (*pc.VerifyOutput, error) {
+	if input.Solution == "good" {
+		return &pc.VerifyOutput{Success: true, Code: pc.VerifyNoError}, nil
+	}
+
+	return &pc.VerifyOutput{Success: false, Code: pc.ParseResponseError}, nil
+}
+
+func TestVerifyAPIGatewayRequest(t *testing.T) {
+	t.Parallel()
+
+	verifier := New(&stubVerifier{}, Options{})
+	form := pc.DefaultFormField + "=good&name=test"
+
+	testCases := []struct {
+		event events.APIGatewayProxyRequest
+		err   error
+	}{
+		{events.APIGatewayProxyRequest{
+			HTTPMethod: "POST",
+			Path:       "/signup",
+			Headers:    map[string]string{"content-type": "application/x-www-form-urlencoded"},
+			Body:       form,
+		}, nil},
+		{events.APIGatewayProxyRequest{
+			HTTPMethod:      "POST",
+			Path:            "/signup",
+			Headers:         map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			Body:            base64.StdEncoding.EncodeToString([]byte(form)),
+			IsBase64Encoded: true,
+		}, nil},
+		{events.APIGatewayProxyRequest{
+			HTTPMethod:        "POST",
+			Path:              "/signup",
+			MultiValueHeaders: map[string][]string{"Content-Type": {"application/json"}},
+			Body:              `{"` + pc.DefaultFormField + `":"bad"}`,
+		}, ErrVerificationFailed},
+		{events.APIGatewayProxyRequest{HTTPMethod: "POST", Path: "/signup"}, ErrVerificationFailed},
+	}
+
+	for i, tc := range testCases {
+		if _, err := verifier.VerifyAPIGatewayRequest(context.TODO(), tc.event); !errors.Is(err, tc.err) {
+			t.Errorf("Unexpected error (%v): %v", i, err)
+		}
+	}
+}
+
+func TestVerifyAPIGatewayV2Request(t *testing.T) {
+	t.Parallel()
+
+	verifier := New(&stubVerifier{}, Options{Extractor: pc.Extractors{pc.FromCookie("captcha"), pc.FromJSON("captcha.solution")}})
+
+	event := events.APIGatewayV2HTTPRequest{
+		RawPath:        "/signup",
+		RawQueryString: "a=b",
+		Headers:        map[string]string{"content-type": "application/json"},
+		Body:           `{"captcha":{"solution":"good"}}`,
+	}
+	event.RequestContext.HTTP.Method = "POST"
+
+	if output, err := verifier.VerifyAPIGatewayV2Request(context.TODO(), event); (err != nil) || !output.OK() {
+		t.Errorf("Unexpected result: %v", err)
+	}
+
+	event.Body = ""
+	event.Cookies = []string{"session=1", "captcha=good"}
+	if _, err := verifier.VerifyAPIGatewayV2Request(context.TODO(), event); err != nil {
+		t.Errorf("Unexpected error with cookie: %v", err)
+	}
+
+	r, err := NewAPIGatewayV2Request(context.TODO(), event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (r.URL.Path != "/signup") || (r.URL.Query().Get("a") != "b") || (r.Method != "POST") {
+		t.Errorf("Unexpected request: %v %v", r.Method, r.URL)
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/PrivateCaptcha/private-captcha-go/contrib/mozillaroots
+
+go 1.25.0
+
+require golang.org/x/crypto/x509roots/fallback v0.0.0-20260213171211-a408498e5541
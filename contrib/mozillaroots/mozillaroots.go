@@ -0,0 +1,13 @@
+// Package mozillaroots embeds Mozilla root certificates into the binary, so that Private Captcha client (or any
+// other TLS client) works in scratch and distroless containers without system certificates. Import it for side
+// effects in the main package:
+//
+//	import _ "github.com/PrivateCaptcha/private-captcha-go/contrib/mozillaroots"
+//
+// Embedded roots are only used when system roots are not available (see x509.SetFallbackRoots). Alternatively,
+// mount a CA bundle and load it with privatecaptcha.LoadRootCAs().
+package mozillaroots
+
+import (
+	_ "golang.org/x/crypto/x509roots/fallback"
+)
@@ -0,0 +1,70 @@
+package privatecaptcha
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"sync"
+)
+
+const (
+	// OtherCostTag aggregates usage of cost tags above maxCostTags in Client.UsageByTag()
+	OtherCostTag = "other"
+	// maxCostTags limits the number of distinct cost tags tracked by the client
+	maxCostTags = 256
+)
+
+var (
+	headerCostTag = http.CanonicalHeaderKey("X-PC-Cost-Tag")
+)
+
+type costTagContextKey struct{}
+
+// WithCostTag returns context carrying cost tag (e.g. product or feature name) of verifications made with it,
+// see Configuration.CostTag
+func WithCostTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, costTagContextKey{}, tag)
+}
+
+// costTag returns cost tag of the verification: from VerifyInput, context or configuration, in this order
+func (c *Client) costTag(ctx context.Context, input *VerifyInput) string {
+	if len(input.CostTag) > 0 {
+		return input.CostTag
+	}
+
+	if tag, ok := ctx.Value(costTagContextKey{}).(string); ok && (len(tag) > 0) {
+		return tag
+	}
+
+	return c.defaultCostTag
+}
+
+// costUsage counts verify requests to the API per cost tag
+type costUsage struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func (u *costUsage) add(tag string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.counts == nil {
+		u.counts = make(map[string]int64)
+	}
+
+	if _, ok := u.counts[tag]; !ok && (len(u.counts) >= maxCostTags) {
+		tag = OtherCostTag
+	}
+
+	u.counts[tag]++
+}
+
+// UsageByTag returns the number of verify requests sent to the API (including retries) per cost tag
+// (untagged requests are counted under empty tag), so that API consumption can be attributed to products
+func (c *Client) UsageByTag() map[string]int64 {
+	c.usage.mu.Lock()
+	defer c.usage.mu.Unlock()
+
+	return maps.Clone(c.usage.counts)
+}
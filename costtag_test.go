@@ -0,0 +1,63 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestCostTag(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var tags []string
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tags = append(tags, r.Header.Get(headerCostTag))
+		mu.Unlock()
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{CostTag: "default"})
+
+	ctx := context.TODO()
+	inputs := []struct {
+		ctx   context.Context
+		input VerifyInput
+	}{
+		{ctx, VerifyInput{Solution: "asdf"}},
+		{WithCostTag(ctx, "signup"), VerifyInput{Solution: "asdf"}},
+		{WithCostTag(ctx, "signup"), VerifyInput{Solution: "asdf", CostTag: "checkout"}},
+		{ctx, VerifyInput{Solution: "asdf", CostTag: "checkout"}},
+	}
+
+	for _, i := range inputs {
+		if _, err := client.Verify(i.ctx, i.input); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{"default", "signup", "checkout", "checkout"}
+	for i := range expected {
+		if tags[i] != expected[i] {
+			t.Errorf("Unexpected tag of request %v: %v", i, tags[i])
+		}
+	}
+
+	usage := client.UsageByTag()
+	if (len(usage) != 3) || (usage["default"] != 1) || (usage["signup"] != 1) || (usage["checkout"] != 2) {
+		t.Errorf("Unexpected usage: %v", usage)
+	}
+}
+
+func TestCostUsageLimit(t *testing.T) {
+	t.Parallel()
+
+	var u costUsage
+	for i := 0; i < maxCostTags+10; i++ {
+		u.add(string(rune('a' + i)))
+	}
+
+	if (len(u.counts) != maxCostTags+1) || (u.counts[OtherCostTag] != 10) {
+		t.Errorf("Unexpected usage: %v tags, %v other", len(u.counts), u.counts[OtherCostTag])
+	}
+}
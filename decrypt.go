@@ -0,0 +1,50 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	errNoDecrypter        = errors.New("privatecaptcha: encrypted API key requires Decrypter")
+	errAPIKeyAndEncrypted = errors.New("privatecaptcha: API key and encrypted API key cannot be used together")
+)
+
+// Decrypter decrypts secrets stored encrypted at rest (e.g. with age, sops or KMS envelope encryption),
+// so that configuration files never contain plaintext API keys
+type Decrypter interface {
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// DecrypterFunc is an adapter to use ordinary functions as Decrypter
+type DecrypterFunc func(ctx context.Context, ciphertext []byte) ([]byte, error)
+
+func (f DecrypterFunc) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	return f(ctx, ciphertext)
+}
+
+// decryptAPIKey replaces Configuration.APIKey with decrypted Configuration.EncryptedAPIKey
+func (cfg *Configuration) decryptAPIKey(ctx context.Context) error {
+	if len(cfg.EncryptedAPIKey) == 0 {
+		return nil
+	}
+
+	if len(cfg.APIKey) > 0 {
+		return errAPIKeyAndEncrypted
+	}
+
+	if cfg.Decrypter == nil {
+		return errNoDecrypter
+	}
+
+	plaintext, err := cfg.Decrypter.Decrypt(ctx, []byte(cfg.EncryptedAPIKey))
+	if err != nil {
+		return fmt.Errorf("privatecaptcha: failed to decrypt API key: %w", err)
+	}
+
+	cfg.APIKey = strings.TrimSpace(string(plaintext))
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package privatecaptcha
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestDecryptAPIKey(t *testing.T) {
+	t.Parallel()
+
+	decrypter := DecrypterFunc(func(ctx context.Context, ciphertext []byte) ([]byte, error) {
+		return base64.StdEncoding.AppendDecode(nil, ciphertext)
+	})
+
+	encrypted := base64.StdEncoding.EncodeToString([]byte("pc_secret\n"))
+
+	client, err := NewClient(Configuration{EncryptedAPIKey: encrypted, Decrypter: decrypter})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.apiKey != "pc_secret" {
+		t.Errorf("Unexpected API key: %v", client.apiKey)
+	}
+
+	if _, err := NewClient(Configuration{EncryptedAPIKey: encrypted}); err != errNoDecrypter {
+		t.Errorf("Unexpected error without decrypter: %v", err)
+	}
+
+	if _, err := NewClient(Configuration{APIKey: "pc_abc", EncryptedAPIKey: encrypted, Decrypter: decrypter}); err != errAPIKeyAndEncrypted {
+		t.Errorf("Unexpected error with both keys: %v", err)
+	}
+
+	if _, err := NewClient(Configuration{EncryptedAPIKey: "!!!", Decrypter: decrypter}); err == nil {
+		t.Error("Expected decryption error")
+	} else if errors.Is(err, errNoDecrypter) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
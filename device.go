@@ -0,0 +1,162 @@
+package privatecaptcha
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultDeviceTokenHeader is the request header trusted devices send their token in
+	DefaultDeviceTokenHeader = "X-PC-Device-Token"
+	deviceTokenVersion       = "d1"
+)
+
+var (
+	errDeviceTokenFormat    = errors.New("privatecaptcha: invalid device token format")
+	errDeviceTokenSignature = errors.New("privatecaptcha: invalid device token signature")
+	errDeviceTokenExpired   = errors.New("privatecaptcha: device token expired")
+	errDeviceTokenKey       = errors.New("privatecaptcha: unknown device token key")
+	errDeviceNotAllowed     = errors.New("privatecaptcha: device is not allowed")
+	errNoDeviceKeys         = errors.New("privatecaptcha: no device token signing keys")
+)
+
+// DeviceBypassInfo describes a request of the trusted device that bypassed verification, see DeviceAllowlist.OnBypass
+type DeviceBypassInfo struct {
+	DeviceID string
+	// Expiration is the expiration time of the device token
+	Expiration time.Time
+	Method     string
+	Path       string
+	// IP is the client IP address, see Client.RealIP()
+	IP string
+}
+
+// DeviceAllowlist lets trusted devices (e.g. kiosks or POS terminals on own hardware) bypass verification
+// in Middleware with signed device tokens, issued with IssueToken() when devices are provisioned.
+// Every bypass is logged (and reported to OnBypass) for audit.
+type DeviceAllowlist struct {
+	// (required) Keys to sign device tokens: the first one is used to sign new tokens, while all of them are
+	// accepted for validation
+	Keys []SigningKey
+	// (optional) Request header with device token (defaults to DefaultDeviceTokenHeader)
+	Header string
+	// (optional) Checks if the device is still allowed (e.g. it's not decommissioned or lost), all devices
+	// with valid tokens are allowed if not set
+	Allowed func(deviceID string) bool
+	// (optional) Audit hook called for every request that bypassed verification
+	OnBypass func(ctx context.Context, info DeviceBypassInfo)
+}
+
+func (d *DeviceAllowlist) header() string {
+	if len(d.Header) > 0 {
+		return d.Header
+	}
+
+	return DefaultDeviceTokenHeader
+}
+
+// IssueToken returns token of the device valid until expiration, signed with the current key
+func (d *DeviceAllowlist) IssueToken(deviceID string, expiration time.Time) (string, error) {
+	if len(d.Keys) == 0 {
+		return "", errNoDeviceKeys
+	}
+
+	key := d.Keys[0]
+	payload := strings.Join([]string{
+		deviceTokenVersion,
+		base64.RawURLEncoding.EncodeToString([]byte(key.ID)),
+		base64.RawURLEncoding.EncodeToString([]byte(deviceID)),
+		strconv.FormatInt(expiration.Unix(), 10),
+	}, ".")
+
+	return payload + "." + graceCookieSignature(key.Key, payload, ""), nil
+}
+
+// Validate checks signature and expiration of the token and returns device identifier and expiration time
+func (d *DeviceAllowlist) Validate(token string, now time.Time) (string, time.Time, error) {
+	parts := strings.Split(token, ".")
+	if (len(parts) != 5) || (parts[0] != deviceTokenVersion) {
+		return "", time.Time{}, errDeviceTokenFormat
+	}
+
+	keyID, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", time.Time{}, errDeviceTokenFormat
+	}
+
+	deviceID, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if (err != nil) || (len(deviceID) == 0) {
+		return "", time.Time{}, errDeviceTokenFormat
+	}
+
+	exp, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return "", time.Time{}, errDeviceTokenFormat
+	}
+
+	var key []byte
+	for _, k := range d.Keys {
+		if k.ID == string(keyID) {
+			key = k.Key
+			break
+		}
+	}
+
+	if key == nil {
+		return "", time.Time{}, errDeviceTokenKey
+	}
+
+	payload := strings.Join(parts[:4], ".")
+	if !hmac.Equal([]byte(parts[4]), []byte(graceCookieSignature(key, payload, ""))) {
+		return "", time.Time{}, errDeviceTokenSignature
+	}
+
+	expiration := time.Unix(exp, 0)
+	if !now.Before(expiration) {
+		return "", time.Time{}, errDeviceTokenExpired
+	}
+
+	if (d.Allowed != nil) && !d.Allowed(string(deviceID)) {
+		return "", time.Time{}, errDeviceNotAllowed
+	}
+
+	return string(deviceID), expiration, nil
+}
+
+// bypassDevice returns true if request comes from the trusted device, bypasses are logged for audit
+func (c *Client) bypassDevice(ctx context.Context, r *http.Request, d *DeviceAllowlist) bool {
+	token := r.Header.Get(d.header())
+	if len(token) == 0 {
+		return false
+	}
+
+	deviceID, expiration, err := d.Validate(token, time.Now())
+	if err != nil {
+		c.logger.Log(ctx, slog.LevelWarn, "Rejected device token", "path", r.URL.Path, "ip", c.RealIP(r), errAttr(err))
+		return false
+	}
+
+	info := DeviceBypassInfo{
+		DeviceID:   deviceID,
+		Expiration: expiration,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		IP:         c.RealIP(r),
+	}
+
+	c.logger.Log(ctx, slog.LevelInfo, "Bypassed verification for trusted device", "device", info.DeviceID,
+		"method", info.Method, "path", info.Path, "ip", info.IP)
+
+	if d.OnBypass != nil {
+		d.OnBypass(ctx, info)
+	}
+
+	return true
+}
@@ -0,0 +1,93 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeviceToken(t *testing.T) {
+	t.Parallel()
+
+	devices := &DeviceAllowlist{
+		Keys:    []SigningKey{{ID: "k2", Key: []byte("new-key")}, {ID: "k1", Key: []byte("old-key")}},
+		Allowed: func(deviceID string) bool { return deviceID != "lost-kiosk" },
+	}
+	old := &DeviceAllowlist{Keys: []SigningKey{{ID: "k1", Key: []byte("old-key")}}}
+	other := &DeviceAllowlist{Keys: []SigningKey{{ID: "k2", Key: []byte("other-key")}}}
+
+	now := time.Now()
+	issue := func(d *DeviceAllowlist, deviceID string, expiration time.Time) string {
+		token, err := d.IssueToken(deviceID, expiration)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	testCases := []struct {
+		token string
+		err   error
+	}{
+		{issue(devices, "kiosk-1", now.Add(time.Hour)), nil},
+		{issue(old, "kiosk-2", now.Add(time.Hour)), nil},
+		{issue(devices, "kiosk-1", now.Add(-time.Second)), errDeviceTokenExpired},
+		{issue(other, "kiosk-1", now.Add(time.Hour)), errDeviceTokenSignature},
+		{issue(devices, "lost-kiosk", now.Add(time.Hour)), errDeviceNotAllowed},
+		{"d1.garbage", errDeviceTokenFormat},
+	}
+
+	for i, tc := range testCases {
+		if _, _, err := devices.Validate(tc.token, now); err != tc.err {
+			t.Errorf("Unexpected error in case %v: %v (expected %v)", i, err, tc.err)
+		}
+	}
+
+	if _, err := (&DeviceAllowlist{}).IssueToken("kiosk", now); err != errNoDeviceKeys {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestMiddlewareDevices(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":false,"code":3}`))
+	}, Configuration{})
+
+	var bypasses []DeviceBypassInfo
+	devices := &DeviceAllowlist{
+		Keys: []SigningKey{{ID: "k1", Key: []byte("device-key")}},
+		OnBypass: func(ctx context.Context, info DeviceBypassInfo) {
+			bypasses = append(bypasses, info)
+		},
+	}
+
+	handler := client.Middleware(MiddlewareOptions{Devices: devices})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsVerified(r.Context()) {
+			t.Errorf("Request is not marked as verified")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	token, _ := devices.IssueToken("kiosk-1", time.Now().Add(time.Hour))
+
+	for header, expected := range map[string]int{token: http.StatusOK, token + "x": http.StatusForbidden, "": http.StatusForbidden} {
+		req := httptest.NewRequest(http.MethodPost, "/checkout", nil)
+		if len(header) > 0 {
+			req.Header.Set(DefaultDeviceTokenHeader, header)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != expected {
+			t.Errorf("Unexpected status: %v (expected %v)", w.Code, expected)
+		}
+	}
+
+	if (len(bypasses) != 1) || (bypasses[0].DeviceID != "kiosk-1") || (bypasses[0].Path != "/checkout") || (bypasses[0].IP != "192.0.2.1") {
+		t.Errorf("Unexpected bypasses: %+v", bypasses)
+	}
+}
@@ -0,0 +1,93 @@
+// Package endpointmw provides captcha verification middleware for RPC-style endpoint abstractions
+// of microservice frameworks, such as Go-kit (endpoint.Endpoint) and Kratos (middleware.Handler).
+//
+// Both frameworks define endpoints as func(ctx context.Context, request any) (any, error), so the
+// middleware is generic over such function types and does not depend on the frameworks:
+//
+//	// Go-kit
+//	var ep endpoint.Endpoint = makeSignupEndpoint(svc)
+//	ep = endpointmw.Middleware[endpoint.Endpoint](client, endpointmw.Options{})(ep)
+//
+//	// Kratos
+//	http.Middleware(func(h middleware.Handler) middleware.Handler {
+//		return endpointmw.Middleware[middleware.Handler](client, endpointmw.Options{})(h)
+//	})
+package endpointmw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+var (
+	// ErrVerificationFailed is returned by protected endpoints when captcha verification fails
+	ErrVerificationFailed = errors.New("endpointmw: captcha verification failed")
+)
+
+// SolutionRequest can be implemented by endpoint request types that carry captcha solution
+type SolutionRequest interface {
+	CaptchaSolution() string
+}
+
+type solutionContextKey struct{}
+
+// WithSolution returns context carrying the captcha solution. It's useful to put the solution from transport
+// (e.g. HTTP header in Go-kit's ServerBefore or Kratos transport header) into context.
+func WithSolution(ctx context.Context, solution string) context.Context {
+	return context.WithValue(ctx, solutionContextKey{}, solution)
+}
+
+// SolutionFromContext returns solution stored with WithSolution
+func SolutionFromContext(ctx context.Context) string {
+	solution, _ := ctx.Value(solutionContextKey{}).(string)
+	return solution
+}
+
+// Options configures the middleware
+type Options struct {
+	// (optional) Custom function to get the solution. By default, the solution is taken from the request
+	// if it implements SolutionRequest, otherwise from context (see WithSolution)
+	Extract func(ctx context.Context, request any) (string, error)
+	// (optional) Sitekey to verify solution against
+	Sitekey string
+}
+
+func (o *Options) extract(ctx context.Context, request any) (string, error) {
+	if o.Extract != nil {
+		return o.Extract(ctx, request)
+	}
+
+	if sr, ok := request.(SolutionRequest); ok {
+		if solution := sr.CaptchaSolution(); len(solution) > 0 {
+			return solution, nil
+		}
+	}
+
+	return SolutionFromContext(ctx), nil
+}
+
+// Middleware creates middleware that verifies captcha solution before calling the endpoint
+func Middleware[E ~func(ctx context.Context, request any) (any, error)](client *pc.Client, opts Options) func(E) E {
+	return func(next E) E {
+		return func(ctx context.Context, request any) (any, error) {
+			solution, err := opts.extract(ctx, request)
+			if err != nil {
+				return nil, err
+			}
+
+			output, err := client.Verify(ctx, pc.VerifyInput{Solution: solution, Sitekey: opts.Sitekey})
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrVerificationFailed, err)
+			}
+
+			if !output.OK() {
+				return nil, fmt.Errorf("%w: %v", ErrVerificationFailed, output.Error())
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
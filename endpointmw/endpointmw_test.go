@@ -0,0 +1,56 @@
+package endpointmw
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+// endpoint mirrors endpoint.Endpoint of Go-kit and middleware.Handler of Kratos
+type endpoint func(ctx context.Context, request any) (any, error)
+
+type signupRequest struct {
+	Email    string
+	Solution string
+}
+
+func (r signupRequest) CaptchaSolution() string { return r.Solution }
+
+func TestMiddleware(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "good" {
+			w.Write([]byte(`{"success":true,"code":0}`))
+		} else {
+			w.Write([]byte(`{"success":false,"code":3}`))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := pc.NewClient(pc.Configuration{APIKey: "test-api-key", Domain: srv.URL, Client: srv.Client()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ep endpoint = func(ctx context.Context, request any) (any, error) {
+		return "ok", nil
+	}
+	ep = Middleware[endpoint](client, Options{})(ep)
+
+	if _, err := ep(context.TODO(), signupRequest{Solution: "good"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := ep(WithSolution(context.TODO(), "good"), struct{}{}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := ep(context.TODO(), signupRequest{Solution: "bad"}); !errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
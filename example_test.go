@@ -0,0 +1,123 @@
+package privatecaptcha_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+	"github.com/PrivateCaptcha/private-captcha-go/codes"
+	"github.com/PrivateCaptcha/private-captcha-go/privatecaptchatest"
+)
+
+// queueMessage stands in for a message type of a Kafka or SQS consumer library
+type queueMessage struct {
+	Body       []byte
+	Attributes map[string]string
+	Ack        func()
+	Nack       func()
+	DeadLetter func()
+}
+
+func ExampleClient_VerifyMessage() {
+	client, err := pc.NewClient(pc.Configuration{APIKey: "pc_abcdef"})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	messages := make(chan queueMessage)
+	close(messages)
+
+	for msg := range messages {
+		// for SQS, sitekey can be passed as a message attribute, for Kafka - as a record header
+		if _, err := client.VerifyMessage(context.Background(), msg.Body, msg.Attributes); err != nil {
+			if pc.IsRetriable(err) {
+				// redeliver later (SQS: do not delete; Kafka: do not commit the offset)
+				msg.Nack()
+			} else {
+				// poison message: will never pass verification
+				msg.DeadLetter()
+			}
+			continue
+		}
+
+		// process the form submission here
+		msg.Ack()
+	}
+}
+
+// commentRepository stands in for a repository of user-generated content (or GORM model with BeforeCreate hook)
+type commentRepository struct{}
+
+func (commentRepository) Create(ctx context.Context, text string) error {
+	if err := pc.RequireVerified(ctx); err != nil {
+		return err
+	}
+
+	// INSERT INTO comments ...
+	return nil
+}
+
+func ExampleRequireVerified() {
+	var repo commentRepository
+
+	// context of a request that did not pass through captcha middleware
+	err := repo.Create(context.Background(), "first!")
+	fmt.Println(err)
+
+	err = repo.Create(pc.WithVerified(context.Background()), "first!")
+	fmt.Println(err)
+
+	// Output:
+	// privatecaptcha: request is not verified
+	// <nil>
+}
+
+// signupService depends only on the small SDK interface, so it does not care which implementation is bound
+type signupService struct {
+	verifier pc.SolutionVerifier
+}
+
+func newSignupService(verifier pc.SolutionVerifier) *signupService {
+	return &signupService{verifier: verifier}
+}
+
+func (s *signupService) Signup(ctx context.Context, solution string) error {
+	output, err := s.verifier.Verify(ctx, pc.VerifyInput{Solution: solution})
+	if err != nil {
+		return err
+	}
+
+	if !output.OK() {
+		return fmt.Errorf("captcha verification failed: %v", output.Error())
+	}
+
+	return nil
+}
+
+// newSolutionVerifier is a provider (constructor) for DI containers, which binds a fake verifier outside of production.
+// It can be registered as-is with wire.NewSet(newSolutionVerifier, newSignupService), fx.Provide(newSolutionVerifier,
+// newSignupService) or dig's container.Provide(newSolutionVerifier). Real client is bound the same way to RequestVerifier
+// or MiddlewareProvider, e.g. fx.Provide(fx.Annotate(pc.NewClient, fx.As(new(pc.MiddlewareProvider)))).
+func newSolutionVerifier(env string, cfg pc.Configuration) (pc.SolutionVerifier, error) {
+	if env != "production" {
+		return &privatecaptchatest.Verifier{Code: codes.NoError}, nil
+	}
+
+	return pc.NewClient(cfg)
+}
+
+func ExampleSolutionVerifier() {
+	// this is what DI container does for you
+	verifier, err := newSolutionVerifier(os.Getenv("APP_ENV"), pc.Configuration{APIKey: os.Getenv("PC_API_KEY")})
+	if err != nil {
+		log.Fatal(err)
+	}
+	service := newSignupService(verifier)
+
+	fmt.Println(service.Signup(context.Background(), "solution"))
+
+	// Output:
+	// <nil>
+}
@@ -0,0 +1,81 @@
+package privatecaptcha
+
+import (
+	"context"
+	"hash/fnv"
+)
+
+const (
+	BucketControl   = "control"
+	BucketTreatment = "treatment"
+)
+
+type experimentKeyContextKey struct{}
+
+// WithExperimentKey returns context carrying the key (e.g. client IP or session ID) used for experiment bucketing
+func WithExperimentKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, experimentKeyContextKey{}, key)
+}
+
+// Experiment deterministically splits verifications between two providers (or two differently configured
+// clients), e.g. during migration. Each output is tagged with the bucket (see VerifyOutput.Bucket()).
+// Experiment implements Provider itself.
+type Experiment struct {
+	// (optional) Name of the experiment, used as a salt for bucketing
+	Name string
+	// (required) Provider for the control bucket
+	Control Provider
+	// (required) Provider for the treatment bucket
+	Treatment Provider
+	// (optional) Percentage (0-100) of keys that go to the treatment bucket
+	TreatmentPercent int
+	// (optional) Function to get bucketing key from context (defaults to key set with WithExperimentKey).
+	// Requests without a key always go to the control bucket
+	Key func(ctx context.Context) string
+}
+
+var _ Provider = (*Experiment)(nil)
+
+// Bucket returns the bucket for the given key
+func (e *Experiment) Bucket(key string) string {
+	if len(key) == 0 {
+		return BucketControl
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(e.Name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+
+	if h.Sum64()%100 < uint64(max(0, e.TreatmentPercent)) {
+		return BucketTreatment
+	}
+
+	return BucketControl
+}
+
+func (e *Experiment) key(ctx context.Context) string {
+	if e.Key != nil {
+		return e.Key(ctx)
+	}
+
+	key, _ := ctx.Value(experimentKeyContextKey{}).(string)
+	return key
+}
+
+// Verify verifies solution with the provider selected for the bucket of the current request
+func (e *Experiment) Verify(ctx context.Context, input VerifyInput) (*VerifyOutput, error) {
+	bucket := e.Bucket(e.key(ctx))
+
+	provider := e.Control
+	if bucket == BucketTreatment {
+		provider = e.Treatment
+	}
+
+	output, err := provider.Verify(ctx, input)
+	if output != nil {
+		output.bucket = bucket
+	}
+
+	return output, err
+}
@@ -0,0 +1,42 @@
+package privatecaptcha
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestExperimentBuckets(t *testing.T) {
+	t.Parallel()
+
+	control, treatment := &stubProvider{}, &stubProvider{}
+	experiment := &Experiment{
+		Name:             "migration",
+		Control:          control,
+		Treatment:        treatment,
+		TreatmentPercent: 30,
+	}
+
+	const total = 1000
+	for i := 0; i < total; i++ {
+		key := fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+		ctx := WithExperimentKey(context.TODO(), key)
+
+		output, err := experiment.Verify(ctx, VerifyInput{Solution: "asdf"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if output.Bucket() != experiment.Bucket(key) {
+			t.Fatalf("Bucketing is not deterministic for %v", key)
+		}
+	}
+
+	if n := treatment.calls.Load(); (n < total/5) || (n > total*2/5) {
+		t.Errorf("Unexpected treatment share: %v of %v", n, total)
+	}
+
+	if output, _ := experiment.Verify(context.TODO(), VerifyInput{Solution: "asdf"}); output.Bucket() != BucketControl {
+		t.Errorf("Requests without key should go to control bucket")
+	}
+}
@@ -0,0 +1,212 @@
+package privatecaptcha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	// DefaultMaxJSONBodySize is the maximum size of JSON request body read by FromJSON extractor
+	DefaultMaxJSONBodySize = 1 << 20
+)
+
+var (
+	errJSONBodyTooLarge = errors.New("privatecaptcha: JSON body is too large")
+)
+
+// Extractor reads captcha solution from the incoming request. Empty solution (without error) means
+// that the solution was not found and, when used in Extractors, the next extractor should be tried.
+type Extractor interface {
+	Extract(r *http.Request) (string, error)
+}
+
+// ExtractorFunc is an adapter to use ordinary functions as Extractor
+type ExtractorFunc func(r *http.Request) (string, error)
+
+func (f ExtractorFunc) Extract(r *http.Request) (string, error) {
+	return f(r)
+}
+
+// Extractors is a chain of extractors tried in order until one of them finds the solution
+type Extractors []Extractor
+
+func (e Extractors) Extract(r *http.Request) (string, error) {
+	for _, extractor := range e {
+		solution, err := extractor.Extract(r)
+		if err != nil {
+			return "", err
+		}
+
+		if len(solution) > 0 {
+			return solution, nil
+		}
+	}
+
+	return "", nil
+}
+
+// Locations of the solution used by the built-in extractors (they match OpenAPI "in" values, except form and JSON)
+const (
+	sourceForm   = "form"
+	sourceJSON   = "json"
+	sourceHeader = "header"
+	sourceCookie = "cookie"
+	sourceQuery  = "query"
+)
+
+// sourceExtractor is a built-in extractor that knows where it reads the solution from (used for documentation)
+type sourceExtractor struct {
+	ExtractorFunc
+	source string
+	name   string
+}
+
+// FromForm reads solution from the form field (both urlencoded and multipart)
+func FromForm(field string) Extractor {
+	return sourceExtractor{source: sourceForm, name: field, ExtractorFunc: func(r *http.Request) (string, error) {
+		return r.FormValue(field), nil
+	}}
+}
+
+// FromHeader reads solution from the request header
+func FromHeader(name string) Extractor {
+	return sourceExtractor{source: sourceHeader, name: name, ExtractorFunc: func(r *http.Request) (string, error) {
+		return r.Header.Get(name), nil
+	}}
+}
+
+// FromCookie reads solution from the cookie
+func FromCookie(name string) Extractor {
+	return sourceExtractor{source: sourceCookie, name: name, ExtractorFunc: func(r *http.Request) (string, error) {
+		cookie, err := r.Cookie(name)
+		if err != nil {
+			if errors.Is(err, http.ErrNoCookie) {
+				return "", nil
+			}
+			return "", err
+		}
+
+		return cookie.Value, nil
+	}}
+}
+
+// FromQuery reads solution from the URL query parameter
+func FromQuery(name string) Extractor {
+	return sourceExtractor{source: sourceQuery, name: name, ExtractorFunc: func(r *http.Request) (string, error) {
+		return r.URL.Query().Get(name), nil
+	}}
+}
+
+func isJSONRequest(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get(headerContentType))
+	return (err == nil) && (mediaType == "application/json")
+}
+
+// readBody reads up to limit bytes of the request body and restores it, so that it can be read again
+// by the next handlers. Body is restored even if it is too large or reading failed.
+func readBody(r *http.Request, limit int64) ([]byte, error) {
+	if (r.Body == nil) || (r.Body == http.NoBody) {
+		return nil, nil
+	}
+
+	body := r.Body
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if (err != nil) || (int64(len(data)) > limit) {
+		// the rest of the body was not read yet
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), body), body}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, errJSONBodyTooLarge
+	}
+
+	body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(data))
+
+	return data, nil
+}
+
+// splitJSONPath splits path by dots, except escaped ones (`\.`)
+func splitJSONPath(path string) []string {
+	var parts []string
+	var part strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case (path[i] == '\\') && (i+1 < len(path)) && (path[i+1] == '.'):
+			part.WriteByte('.')
+			i++
+		case path[i] == '.':
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteByte(path[i])
+		}
+	}
+
+	return append(parts, part.String())
+}
+
+// escapeJSONKey escapes dots in key, so that it is not split by FromJSON
+func escapeJSONKey(key string) string {
+	return strings.ReplaceAll(key, ".", `\.`)
+}
+
+// lookupJSON returns string value by dot-separated path (e.g. "captcha.solution")
+func lookupJSON(data []byte, path string) (string, error) {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", err
+	}
+
+	for _, key := range splitJSONPath(path) {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return "", nil
+		}
+
+		if value, ok = obj[key]; !ok {
+			return "", nil
+		}
+	}
+
+	solution, _ := value.(string)
+	return solution, nil
+}
+
+// FromJSON reads solution from JSON request body by dot-separated path (e.g. "captcha.solution"). Dots that
+// are part of the field name should be escaped with a backslash (e.g. `captcha\.solution` for {"captcha.solution": "..."}).
+// Body is restored after reading so that downstream handlers can decode it again.
+func FromJSON(path string) Extractor {
+	return sourceExtractor{source: sourceJSON, name: path, ExtractorFunc: func(r *http.Request) (string, error) {
+		if !isJSONRequest(r) {
+			return "", nil
+		}
+
+		data, err := readBody(r, DefaultMaxJSONBodySize)
+		if (err != nil) || (len(data) == 0) {
+			return "", err
+		}
+
+		return lookupJSON(data, path)
+	}}
+}
+
+func (c *Client) verifyRequestWith(ctx context.Context, r *http.Request, extractor Extractor) error {
+	solution, err := extractor.Extract(r)
+	if err != nil {
+		return err
+	}
+
+	return c.verifySolution(ctx, solution)
+}
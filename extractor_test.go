@@ -0,0 +1,255 @@
+package privatecaptcha
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExtractors(t *testing.T) {
+	t.Parallel()
+
+	extractor := Extractors{
+		FromForm(DefaultFormField),
+		FromJSON("captcha.solution"),
+		FromHeader("X-Captcha"),
+		FromCookie("captcha"),
+		FromQuery("captcha"),
+	}
+
+	formReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(DefaultFormField+"=form"))
+	formReq.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+
+	const jsonBody = `{"name":"test","captcha":{"solution":"json"}}`
+	jsonReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(jsonBody))
+	jsonReq.Header.Set(headerContentType, "application/json; charset=utf-8")
+
+	headerReq := httptest.NewRequest(http.MethodPost, "/", nil)
+	headerReq.Header.Set("X-Captcha", "header")
+
+	cookieReq := httptest.NewRequest(http.MethodPost, "/", nil)
+	cookieReq.AddCookie(&http.Cookie{Name: "captcha", Value: "cookie"})
+
+	queryReq := httptest.NewRequest(http.MethodGet, "/?captcha=query", nil)
+
+	emptyReq := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	testCases := []struct {
+		req      *http.Request
+		solution string
+	}{
+		{formReq, "form"},
+		{jsonReq, "json"},
+		{headerReq, "header"},
+		{cookieReq, "cookie"},
+		{queryReq, "query"},
+		{emptyReq, ""},
+	}
+
+	for _, tc := range testCases {
+		solution, err := extractor.Extract(tc.req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if solution != tc.solution {
+			t.Errorf("Unexpected solution: %v (expected %v)", solution, tc.solution)
+		}
+	}
+
+	// JSON body should remain readable for the next handlers
+	if body, _ := io.ReadAll(jsonReq.Body); string(body) != jsonBody {
+		t.Errorf("JSON body was not restored: %v", string(body))
+	}
+}
+
+func TestVerifyRequestJSON(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := io.ReadAll(r.Body); string(body) != "json" {
+			t.Errorf("Unexpected solution: %v", string(body))
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{})
+
+	const jsonBody = `{"email":"user@example.com","` + DefaultFormField + `":"json"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(jsonBody))
+	req.Header.Set(headerContentType, "application/json")
+
+	if err := client.VerifyRequest(context.TODO(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	if body, _ := io.ReadAll(req.Body); string(body) != jsonBody {
+		t.Errorf("JSON body was not restored: %v", string(body))
+	}
+
+	tooLarge := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":"`+strings.Repeat("x", DefaultMaxJSONBodySize)+`"}`))
+	tooLarge.Header.Set(headerContentType, "application/json")
+	if err := client.VerifyRequest(context.TODO(), tooLarge); err != errJSONBodyTooLarge {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestJSONBodyRestoredOnError(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Request should not be sent")
+	}, Configuration{})
+
+	body := `{"data":"` + strings.Repeat("a", DefaultMaxJSONBodySize) + `"}`
+
+	var received int
+	opts := MiddlewareOptions{FailurePolicy: FailureMonitor, MaxBodySize: 2 * DefaultMaxJSONBodySize}
+	handler := client.Middleware(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		received = len(data)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(headerContentType, "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if received != len(body) {
+		t.Errorf("Unexpected body size: %v (expected %v)", received, len(body))
+	}
+}
+
+func TestJSONPathEscaping(t *testing.T) {
+	t.Parallel()
+
+	const jsonBody = `{"captcha.solution":"flat","captcha":{"solution":"nested"}}`
+
+	testCases := []struct {
+		path     string
+		solution string
+	}{
+		{"captcha.solution", "nested"},
+		{`captcha\.solution`, "flat"},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(jsonBody))
+		req.Header.Set(headerContentType, "application/json")
+
+		if solution, err := FromJSON(tc.path).Extract(req); (err != nil) || (solution != tc.solution) {
+			t.Errorf("Unexpected solution for %v: %v (%v)", tc.path, solution, err)
+		}
+	}
+}
+
+func TestConfigurationExtractor(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := io.ReadAll(r.Body); string(body) != "header" {
+			w.Write([]byte(`{"success":false,"code":3}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{Extractor: FromHeader("X-Captcha")})
+
+	var calls int
+	handler := client.VerifyFunc(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	headerReq := httptest.NewRequest(http.MethodPost, "/", nil)
+	headerReq.Header.Set("X-Captcha", "header")
+
+	// form field is ignored when extractor is configured
+	formReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(DefaultFormField+"=header"))
+	formReq.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+
+	for req, expected := range map[*http.Request]int{headerReq: http.StatusOK, formReq: http.StatusForbidden} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != expected {
+			t.Errorf("Unexpected status: %v (expected %v)", w.Code, expected)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Unexpected handler calls: %v", calls)
+	}
+}
+
+func TestSolutionHeader(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := io.ReadAll(r.Body); string(body) != "header" {
+			w.Write([]byte(`{"success":false,"code":3}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{SolutionHeader: NativeSolutionHeader})
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(NativeSolutionHeader, "header")
+	if err := client.VerifyRequest(context.TODO(), req); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if operation := client.OpenAPI(MiddlewareOptions{}); (len(operation.Parameters) != 1) || (operation.Parameters[0].In != "header") {
+		t.Errorf("Unexpected OpenAPI parameters: %+v", operation.Parameters)
+	}
+}
+
+func TestFormFields(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := io.ReadAll(r.Body); string(body) != "good" {
+			w.Write([]byte(`{"success":false,"code":3}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{FormFields: []string{DefaultFormField, "h-captcha-response"}})
+
+	testCases := []struct {
+		contentType string
+		body        string
+		ok          bool
+	}{
+		{"application/x-www-form-urlencoded", DefaultFormField + "=good", true},
+		{"application/x-www-form-urlencoded", "h-captcha-response=good", true},
+		// fields are tried in order of priority
+		{"application/x-www-form-urlencoded", "h-captcha-response=good&" + DefaultFormField + "=bad", false},
+		{"application/x-www-form-urlencoded", "g-recaptcha-response=good", false},
+		{"application/json", `{"h-captcha-response":"good"}`, true},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+		req.Header.Set(headerContentType, tc.contentType)
+
+		if err := client.VerifyRequest(context.TODO(), req); (err == nil) != tc.ok {
+			t.Errorf("Unexpected result for %v: %v", tc.body, err)
+		}
+	}
+}
+
+func FuzzFromJSON(f *testing.F) {
+	f.Add(`{"captcha":{"solution":"abc"}}`, "captcha.solution")
+	f.Add(`{"solution":1}`, "solution")
+	f.Add(`[1,2,3]`, "0")
+	f.Add(`{"a":`, "a..b")
+
+	f.Fuzz(func(t *testing.T, body string, path string) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set(headerContentType, "application/json")
+
+		FromJSON(path).Extract(req)
+
+		// body must be restored for the next handlers
+		if data, err := io.ReadAll(req.Body); (err == nil) && (len(body) <= DefaultMaxJSONBodySize) && (string(data) != body) {
+			t.Errorf("Request body was not restored")
+		}
+	})
+}
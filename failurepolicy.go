@@ -0,0 +1,45 @@
+package privatecaptcha
+
+import (
+	"context"
+)
+
+// FailurePolicy decides how Middleware handles requests that failed verification
+type FailurePolicy int
+
+const (
+	// FailureReject rejects requests that failed verification (default)
+	FailureReject FailurePolicy = iota
+	// FailureMonitor passes requests that failed verification through (monitor-only), so that verification results
+	// can be observed (e.g. with Configuration.OnResult) without affecting users. Such requests are not marked
+	// as verified (see IsVerified).
+	FailureMonitor
+)
+
+func (p FailurePolicy) String() string {
+	switch p {
+	case FailureMonitor:
+		return "monitor"
+	default:
+		return "reject"
+	}
+}
+
+type failurePolicyContextKey struct{}
+
+// WithFailurePolicy overrides MiddlewareOptions.FailurePolicy for the request with this context. It is intended
+// for request-level decisions made earlier in the middleware chain, e.g. requests already authenticated by another
+// factor can be verified in monitor-only mode. During maintenance windows with FailureMonitor (see MaintenanceWindow)
+// requests are passed through regardless of this policy.
+func WithFailurePolicy(ctx context.Context, policy FailurePolicy) context.Context {
+	return context.WithValue(ctx, failurePolicyContextKey{}, policy)
+}
+
+// failurePolicy returns failure policy set with WithFailurePolicy() or fallback
+func failurePolicy(ctx context.Context, fallback FailurePolicy) FailurePolicy {
+	if policy, ok := ctx.Value(failurePolicyContextKey{}).(FailurePolicy); ok {
+		return policy
+	}
+
+	return fallback
+}
@@ -0,0 +1,62 @@
+package privatecaptcha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFailurePolicy(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&VerifyOutput{Success: false, Code: InvalidSolutionError})
+	}, Configuration{})
+
+	var verified bool
+	handler := client.Middleware(MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verified = IsVerified(r.Context())
+	}))
+
+	// authentication middleware earlier in the chain
+	authenticated := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Authenticated") == "true" {
+			r = r.WithContext(WithFailurePolicy(r.Context(), FailureMonitor))
+		}
+		handler.ServeHTTP(w, r)
+	})
+
+	testCases := []struct {
+		authenticated string
+		status        int
+	}{
+		{"false", http.StatusForbidden},
+		{"true", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("X-Authenticated", tc.authenticated)
+
+		recorder := httptest.NewRecorder()
+		authenticated.ServeHTTP(recorder, req)
+
+		if recorder.Code != tc.status {
+			t.Errorf("Unexpected status %v (authenticated %v)", recorder.Code, tc.authenticated)
+		}
+	}
+
+	if verified {
+		t.Error("Request passed in monitor-only mode must not be verified")
+	}
+
+	// context override takes precedence over middleware options
+	monitored := client.Middleware(MiddlewareOptions{FailurePolicy: FailureMonitor})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	recorder := httptest.NewRecorder()
+	monitored.ServeHTTP(recorder, req.WithContext(WithFailurePolicy(req.Context(), FailureReject)))
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("Unexpected status with context override: %v", recorder.Code)
+	}
+}
@@ -0,0 +1,70 @@
+package privatecaptcha
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	// ForwardAuthResultHeader is set by ForwardAuthHandler to "success" or "failure"
+	ForwardAuthResultHeader = "X-Captcha-Result"
+	// ForwardAuthCodeHeader is set by ForwardAuthHandler to the verification code (e.g. "solution-invalid")
+	ForwardAuthCodeHeader = "X-Captcha-Code"
+	// ForwardAuthTraceHeader is set by ForwardAuthHandler to the trace ID of the verify request
+	ForwardAuthTraceHeader = "X-Captcha-Trace-ID"
+	// ForwardAuthAttemptsHeader is set by ForwardAuthHandler to the number of verify attempts
+	ForwardAuthAttemptsHeader = "X-Captcha-Attempts"
+)
+
+// ForwardAuthOptions configures ForwardAuthHandler
+type ForwardAuthOptions struct {
+	// (optional) Request headers to read the solution from, in order (defaults to NativeSolutionHeader)
+	Headers []string
+	// (optional) Sitekey to verify solution against
+	Sitekey string
+	// (optional) http status to return for failed verifications (defaults to Configuration.FailedStatusCode)
+	FailedStatusCode int
+}
+
+// ForwardAuthHandler returns http.Handler implementing the ForwardAuth contract of Traefik (and auth_request
+// of NGINX): solution is read from the headers of the forwarded request, and the response is 200 if verification
+// succeeded or FailedStatusCode otherwise. Verification details are returned in X-Captcha-* headers that can
+// be copied to the upstream request (e.g. with Traefik's authResponseHeaders).
+func (c *Client) ForwardAuthHandler(opts ForwardAuthOptions) http.Handler {
+	if len(opts.Headers) == 0 {
+		opts.Headers = []string{NativeSolutionHeader}
+	}
+
+	if opts.FailedStatusCode == 0 {
+		opts.FailedStatusCode = c.failedStatusCode
+	}
+
+	extractors := make(Extractors, 0, len(opts.Headers))
+	for _, header := range opts.Headers {
+		extractors = append(extractors, FromHeader(header))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		solution, _ := extractors.Extract(r)
+
+		output, err := c.Verify(r.Context(), VerifyInput{Solution: solution, Sitekey: opts.Sitekey})
+
+		header := w.Header()
+		if output != nil {
+			header.Set(ForwardAuthCodeHeader, output.Error())
+			header.Set(ForwardAuthAttemptsHeader, strconv.Itoa(output.Attempts()))
+			if requestID := output.RequestID(); len(requestID) > 0 {
+				header.Set(ForwardAuthTraceHeader, requestID)
+			}
+		}
+
+		if (err != nil) || !output.OK() {
+			header.Set(ForwardAuthResultHeader, "failure")
+			http.Error(w, http.StatusText(opts.FailedStatusCode), opts.FailedStatusCode)
+			return
+		}
+
+		header.Set(ForwardAuthResultHeader, "success")
+		w.WriteHeader(http.StatusOK)
+	})
+}
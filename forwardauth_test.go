@@ -0,0 +1,97 @@
+package privatecaptcha
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForwardAuthHandler(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set(headerTraceID, "trace-id")
+		if string(body) == "good" {
+			w.Write([]byte(`{"success":true,"code":0}`))
+		} else {
+			w.Write([]byte(`{"success":false,"code":3}`))
+		}
+	}, Configuration{})
+
+	handler := client.ForwardAuthHandler(ForwardAuthOptions{Headers: []string{"X-Forwarded-Captcha", NativeSolutionHeader}})
+
+	testCases := []struct {
+		header   string
+		solution string
+		status   int
+		result   string
+		code     string
+		attempts string
+	}{
+		{NativeSolutionHeader, "good", http.StatusOK, "success", "", "1"},
+		{"X-Forwarded-Captcha", "bad", http.StatusForbidden, "failure", InvalidSolutionError.String(), "1"},
+		{"", "", http.StatusForbidden, "failure", "", ""},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/auth", nil)
+		if len(tc.header) > 0 {
+			req.Header.Set(tc.header, tc.solution)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != tc.status {
+			t.Errorf("Unexpected status code: %v", recorder.Code)
+		}
+
+		if result := recorder.Header().Get(ForwardAuthResultHeader); result != tc.result {
+			t.Errorf("Unexpected result header: %v", result)
+		}
+
+		if code := recorder.Header().Get(ForwardAuthCodeHeader); code != tc.code {
+			t.Errorf("Unexpected code header: %v", code)
+		}
+
+		if attempts := recorder.Header().Get(ForwardAuthAttemptsHeader); attempts != tc.attempts {
+			t.Errorf("Unexpected attempts header: %v", attempts)
+		}
+	}
+}
+
+func TestForwardAuthAttemptsHeader(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, Configuration{})
+
+	// all attempts failed
+	output, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 2, MaxBackoffSeconds: 1})
+	if (err == nil) || (output.Attempts() != 2) || (int(requests.Load()) != output.Attempts()) {
+		t.Errorf("Unexpected attempts: %v (requests %v, error %v)", output.Attempts(), requests.Load(), err)
+	}
+
+	handler := client.ForwardAuthHandler(ForwardAuthOptions{})
+	req := httptest.NewRequest(http.MethodGet, "/auth", nil)
+	req.Header.Set(NativeSolutionHeader, "asdf")
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+
+	requests.Store(0)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req.WithContext(ctx))
+
+	if attempts := recorder.Header().Get(ForwardAuthAttemptsHeader); attempts != strconv.Itoa(int(requests.Load())) {
+		t.Errorf("Unexpected attempts header: %v (requests %v)", attempts, requests.Load())
+	}
+}
@@ -0,0 +1,279 @@
+package privatecaptcha
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultGraceCookieName = "pc_verified"
+	DefaultGraceCookieTTL  = 30 * time.Minute
+	graceCookieVersion     = "v1"
+)
+
+var (
+	errGraceCookieFormat    = errors.New("privatecaptcha: invalid grace cookie format")
+	errGraceCookieSignature = errors.New("privatecaptcha: invalid grace cookie signature")
+	errGraceCookieExpired   = errors.New("privatecaptcha: grace cookie expired")
+	errGraceCookieKey       = errors.New("privatecaptcha: unknown grace cookie key")
+	errNoSigningKeys        = errors.New("privatecaptcha: no grace cookie signing keys")
+)
+
+// SigningKey is a secret key with identifier used to sign grace cookies
+type SigningKey struct {
+	ID  string
+	Key []byte
+}
+
+// SigningKeysProvider returns active signing keys: the first one is used to sign new cookies, while all
+// of them are accepted for validation. It is called for every request, so it should be cheap (e.g. cached).
+type SigningKeysProvider func() ([]SigningKey, error)
+
+// GraceCookie configures a "verified human" grace period: after successful verification, middleware sets
+// a signed HttpOnly cookie and accepts it instead of a new solution for subsequent requests until it expires.
+//
+// Without Binder the cookie is a bearer token: anyone who obtains it (e.g. a bot farm sharing cookies of a single
+// solved captcha) can replay it from any client until it expires. Binder ties the cookie to request-specific data
+// (e.g. client IP or session ID), so that it is only accepted from requests with the same binding.
+//
+// With Binder and StaleTTL, Middleware also serves "stale" verification decisions while the API is in maintenance
+// mode: returning sessions with a recently expired cookie are let through instead of being interrupted, while new
+// visitors get the failure policy of the middleware.
+type GraceCookie struct {
+	// (required, unless KeysProvider is set) Secret key used to sign cookies (at least 32 random bytes are recommended)
+	Key []byte
+	// (optional) Identifier of the key, stored in the cookie to support key rotation
+	KeyID string
+	// (optional) Keys that are no longer used for signing, but cookies signed with them are still accepted
+	PreviousKeys []SigningKey
+	// (optional) Dynamic source of signing keys, overrides Key, KeyID and PreviousKeys
+	KeysProvider SigningKeysProvider
+	// (optional) Cookie name (defaults to DefaultGraceCookieName)
+	Name string
+	// (optional) Grace period duration (defaults to DefaultGraceCookieTTL)
+	TTL time.Duration
+	// (optional) Cookie path (defaults to "/")
+	Path string
+	// (optional) Cookie domain
+	Domain string
+	// (optional) Allow sending cookie over plain HTTP (only for development)
+	Insecure bool
+	// (optional) Returns request-specific data (e.g. Client.RealIP() or session ID) the cookie is bound to. It is
+	// included in the signature, but not in the cookie itself
+	Binder func(r *http.Request) string
+	// (optional) How long after expiration the cookie is still accepted when the API responds with
+	// MaintenanceModeError (stale-while-revalidate). It requires Binder, so that only the session that passed
+	// verification can use it, and the cookie is not renewed, so sessions revalidate after maintenance
+	StaleTTL time.Duration
+}
+
+func (g *GraceCookie) name() string {
+	if len(g.Name) > 0 {
+		return g.Name
+	}
+
+	return DefaultGraceCookieName
+}
+
+func (g *GraceCookie) ttl() time.Duration {
+	if g.TTL > 0 {
+		return g.TTL
+	}
+
+	return DefaultGraceCookieTTL
+}
+
+func graceCookieSignature(key []byte, payload, binding string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	if len(binding) > 0 {
+		mac.Write([]byte("." + base64.RawURLEncoding.EncodeToString([]byte(binding))))
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (g *GraceCookie) binding(r *http.Request) string {
+	if g.Binder == nil {
+		return ""
+	}
+
+	return g.Binder(r)
+}
+
+// keys returns active signing keys, the first one is the current key
+func (g *GraceCookie) keys() ([]SigningKey, error) {
+	if g.KeysProvider != nil {
+		keys, err := g.KeysProvider()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(keys) == 0 {
+			return nil, errNoSigningKeys
+		}
+
+		return keys, nil
+	}
+
+	if len(g.Key) == 0 {
+		return nil, errNoSigningKeys
+	}
+
+	return append([]SigningKey{{ID: g.KeyID, Key: g.Key}}, g.PreviousKeys...), nil
+}
+
+// Value returns cookie value valid until expiration, signed with the current key. It is not bound to any request
+// (see BoundValue())
+func (g *GraceCookie) Value(expiration time.Time) (string, error) {
+	return g.BoundValue(expiration, "")
+}
+
+// BoundValue returns cookie value valid until expiration for requests with the same binding (see Binder)
+func (g *GraceCookie) BoundValue(expiration time.Time, binding string) (string, error) {
+	keys, err := g.keys()
+	if err != nil {
+		return "", err
+	}
+
+	payload := strings.Join([]string{
+		graceCookieVersion,
+		base64.RawURLEncoding.EncodeToString([]byte(keys[0].ID)),
+		strconv.FormatInt(expiration.Unix(), 10),
+	}, ".")
+
+	return payload + "." + graceCookieSignature(keys[0].Key, payload, binding), nil
+}
+
+// lookupKey returns signing key by its identifier
+func (g *GraceCookie) lookupKey(keyID string) ([]byte, error) {
+	keys, err := g.keys()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if key.ID == keyID {
+			return key.Key, nil
+		}
+	}
+
+	return nil, errGraceCookieKey
+}
+
+// Validate checks signature and expiration of the cookie value that is not bound to any request
+func (g *GraceCookie) Validate(value string, now time.Time) error {
+	return g.ValidateBound(value, "", now)
+}
+
+// ValidateBound checks signature and expiration of the cookie value issued for requests with the binding
+func (g *GraceCookie) ValidateBound(value, binding string, now time.Time) error {
+	_, err := g.check(value, binding, now)
+	return err
+}
+
+// check validates the cookie value and returns its expiration (also for expired cookies with a valid signature)
+func (g *GraceCookie) check(value, binding string, now time.Time) (time.Time, error) {
+	parts := strings.Split(value, ".")
+	if (len(parts) != 4) || (parts[0] != graceCookieVersion) {
+		return time.Time{}, errGraceCookieFormat
+	}
+
+	keyID, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, errGraceCookieFormat
+	}
+
+	expiration, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return time.Time{}, errGraceCookieFormat
+	}
+
+	key, err := g.lookupKey(string(keyID))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(graceCookieSignature(key, payload, binding))) {
+		return time.Time{}, errGraceCookieSignature
+	}
+
+	if now.Unix() >= expiration {
+		return time.Unix(expiration, 0), errGraceCookieExpired
+	}
+
+	return time.Unix(expiration, 0), nil
+}
+
+func (g *GraceCookie) valid(r *http.Request) bool {
+	cookie, err := r.Cookie(g.name())
+	if err != nil {
+		return false
+	}
+
+	return g.ValidateBound(cookie.Value, g.binding(r), time.Now()) == nil
+}
+
+// stale returns how long ago the bound cookie of the request expired, if it is still within StaleTTL
+func (g *GraceCookie) stale(r *http.Request, now time.Time) (time.Duration, bool) {
+	if (g.StaleTTL <= 0) || (g.Binder == nil) {
+		return 0, false
+	}
+
+	cookie, err := r.Cookie(g.name())
+	if err != nil {
+		return 0, false
+	}
+
+	binding := g.binding(r)
+	if len(binding) == 0 {
+		return 0, false
+	}
+
+	expiration, err := g.check(cookie.Value, binding, now)
+	if err != errGraceCookieExpired {
+		return 0, false
+	}
+
+	expired := now.Sub(expiration)
+	return expired, expired < g.StaleTTL
+}
+
+func (g *GraceCookie) issue(w http.ResponseWriter, r *http.Request) error {
+	ttl := g.ttl()
+
+	value, err := g.BoundValue(time.Now().Add(ttl), g.binding(r))
+	if err != nil {
+		return err
+	}
+
+	path := g.Path
+	if len(path) == 0 {
+		path = "/"
+	}
+
+	// browser has to keep the expired cookie for it to be accepted as stale
+	maxAge := ttl
+	if (g.StaleTTL > 0) && (g.Binder != nil) {
+		maxAge += g.StaleTTL
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     g.name(),
+		Value:    value,
+		Path:     path,
+		Domain:   g.Domain,
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   !g.Insecure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return nil
+}
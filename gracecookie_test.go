@@ -0,0 +1,229 @@
+package privatecaptcha
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGraceCookieValidate(t *testing.T) {
+	t.Parallel()
+
+	g := &GraceCookie{Key: []byte("0123456789abcdef0123456789abcdef"), KeyID: "k1"}
+	now := time.Now()
+
+	value, _ := g.Value(now.Add(time.Minute))
+	if err := g.Validate(value, now); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := g.Validate(value, now.Add(2*time.Minute)); err != errGraceCookieExpired {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := g.Validate(value[:len(value)-2]+"aa", now); err != errGraceCookieSignature {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	other := &GraceCookie{Key: g.Key, KeyID: "k2"}
+	if err := other.Validate(value, now); err != errGraceCookieKey {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := g.Validate("garbage", now); err != errGraceCookieFormat {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestMiddlewareGraceCookie(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "good" {
+			w.Write([]byte(`{"success":true,"code":0}`))
+		} else {
+			w.Write([]byte(`{"success":false,"code":3}`))
+		}
+	}, Configuration{})
+
+	opts := MiddlewareOptions{GraceCookie: &GraceCookie{Key: []byte("0123456789abcdef0123456789abcdef")}}
+	handler := client.Middleware(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodPost, "/step1", nil)
+	req.PostForm = url.Values{DefaultFormField: {"good"}}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	cookies := recorder.Result().Cookies()
+	if (recorder.Code != http.StatusOK) || (len(cookies) != 1) || !cookies[0].HttpOnly {
+		t.Fatalf("Grace cookie was not issued: %v %v", recorder.Code, cookies)
+	}
+
+	// second step without solution, but with the cookie
+	req = httptest.NewRequest(http.MethodPost, "/step2", nil)
+	req.PostForm = url.Values{}
+	req.AddCookie(cookies[0])
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if (recorder.Code != http.StatusOK) || (requests.Load() != 1) {
+		t.Errorf("Grace cookie was not accepted: %v (requests %v)", recorder.Code, requests.Load())
+	}
+}
+
+func TestMiddlewareGraceCookieBinding(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{})
+
+	opts := MiddlewareOptions{GraceCookie: &GraceCookie{
+		Key:    []byte("0123456789abcdef0123456789abcdef"),
+		Binder: func(r *http.Request) string { return r.RemoteAddr },
+	}}
+	handler := client.Middleware(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodPost, "/step1", nil)
+	req.PostForm = url.Values{DefaultFormField: {"good"}}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Grace cookie was not issued: %v %v", recorder.Code, cookies)
+	}
+
+	testCases := []struct {
+		remoteAddr string
+		code       int
+	}{
+		{req.RemoteAddr, http.StatusOK},
+		{"198.51.100.1:1234", http.StatusForbidden},
+	}
+
+	for i, tc := range testCases {
+		req := httptest.NewRequest(http.MethodPost, "/step2", nil)
+		req.RemoteAddr = tc.remoteAddr
+		req.PostForm = url.Values{}
+		req.AddCookie(cookies[0])
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != tc.code {
+			t.Errorf("Unexpected status code in test case %d: %v", i, recorder.Code)
+		}
+	}
+
+	// bound cookie is not accepted without the binding
+	if err := opts.GraceCookie.Validate(cookies[0].Value, time.Now()); err != errGraceCookieSignature {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestGraceCookieKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	oldKey := SigningKey{ID: "2024", Key: []byte("old-key-old-key-old-key-old-key!")}
+	newKey := SigningKey{ID: "2025", Key: []byte("new-key-new-key-new-key-new-key!")}
+	now := time.Now()
+
+	before := &GraceCookie{Key: oldKey.Key, KeyID: oldKey.ID}
+	oldValue, _ := before.Value(now.Add(time.Minute))
+
+	after := &GraceCookie{Key: newKey.Key, KeyID: newKey.ID, PreviousKeys: []SigningKey{oldKey}}
+	if err := after.Validate(oldValue, now); err != nil {
+		t.Errorf("Cookie signed with previous key should be accepted: %v", err)
+	}
+
+	newValue, _ := after.Value(now.Add(time.Minute))
+	if err := before.Validate(newValue, now); err != errGraceCookieKey {
+		t.Errorf("Cookie should be signed with the new key: %v", err)
+	}
+
+	provided := &GraceCookie{KeysProvider: func() ([]SigningKey, error) {
+		return []SigningKey{newKey}, nil
+	}}
+	if err := provided.Validate(newValue, now); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := provided.Validate(oldValue, now); err != errGraceCookieKey {
+		t.Errorf("Retired key should not be accepted: %v", err)
+	}
+}
+
+func TestMiddlewareStaleGraceCookie(t *testing.T) {
+	t.Parallel()
+
+	var code atomic.Int32
+	code.Store(int32(MaintenanceModeError))
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"success":false,"code":%d}`, code.Load())
+	}, Configuration{})
+
+	var infos []StaleGraceInfo
+	client.onStaleGrace = func(ctx context.Context, info StaleGraceInfo) { infos = append(infos, info) }
+
+	const remoteAddr = "192.0.2.1:1234"
+	g := &GraceCookie{
+		Key:      []byte("0123456789abcdef0123456789abcdef"),
+		Binder:   func(r *http.Request) string { return r.RemoteAddr },
+		StaleTTL: 10 * time.Minute,
+	}
+	handler := client.Middleware(MiddlewareOptions{GraceCookie: g})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsVerified(r.Context()) {
+			t.Error("Request passed with stale grace cookie is not verified")
+		}
+	}))
+
+	now := time.Now()
+	recent, _ := g.BoundValue(now.Add(-time.Minute), remoteAddr)
+	old, _ := g.BoundValue(now.Add(-time.Hour), remoteAddr)
+
+	testCases := []struct {
+		cookie     string
+		remoteAddr string
+		code       VerifyCode
+		status     int
+	}{
+		{recent, remoteAddr, MaintenanceModeError, http.StatusOK},
+		{old, remoteAddr, MaintenanceModeError, http.StatusForbidden},
+		{recent, "198.51.100.1:1234", MaintenanceModeError, http.StatusForbidden},
+		{"", remoteAddr, MaintenanceModeError, http.StatusForbidden},
+		{recent, remoteAddr, PuzzleExpiredError, http.StatusForbidden},
+	}
+
+	for i, tc := range testCases {
+		code.Store(int32(tc.code))
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = tc.remoteAddr
+		req.PostForm = url.Values{DefaultFormField: {"solution"}}
+		if len(tc.cookie) > 0 {
+			req.AddCookie(&http.Cookie{Name: DefaultGraceCookieName, Value: tc.cookie})
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != tc.status {
+			t.Errorf("Unexpected status code in test case %d: %v", i, recorder.Code)
+		}
+		if len(recorder.Result().Cookies()) != 0 {
+			t.Errorf("Stale grace cookie was renewed in test case %d", i)
+		}
+	}
+
+	if (len(infos) != 1) || (infos[0].Total != 1) || (infos[0].Expired < time.Minute) {
+		t.Errorf("Unexpected stale grace infos: %+v", infos)
+	}
+}
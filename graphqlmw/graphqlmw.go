@@ -0,0 +1,131 @@
+// Package graphqlmw provides implementation of @captcha directive for per-mutation captcha enforcement in gqlgen.
+//
+// gqlgen directives are functions of func(ctx context.Context, obj any, next graphql.Resolver) (any, error),
+// so the directive is generic over graphql.Resolver and does not depend on gqlgen:
+//
+//	# schema.graphql
+//	directive @captcha on FIELD_DEFINITION
+//
+//	type Mutation {
+//		signup(input: SignupInput!, captcha: String): User! @captcha
+//	}
+//
+//	// server.go
+//	cfg := generated.Config{Resolvers: &resolver{}}
+//	cfg.Directives.Captcha = graphqlmw.Directive[graphql.Resolver](client, graphqlmw.Options{
+//		Argument: "captcha",
+//		Args: func(ctx context.Context) map[string]any {
+//			return graphql.GetFieldContext(ctx).Args
+//		},
+//	})
+//
+// Failed verifications are rejected with *Error, which gqlgen renders with "code" extension of the GraphQL error.
+package graphqlmw
+
+import (
+	"context"
+	"net/http"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+const (
+	// CodeRequired is the error code of mutations called without captcha solution
+	CodeRequired = "CAPTCHA_REQUIRED"
+	// CodeFailed is the error code of mutations with invalid captcha solution
+	CodeFailed = "CAPTCHA_FAILED"
+	// CodeUnavailable is the error code of mutations whose captcha solution could not be verified
+	CodeUnavailable = "CAPTCHA_UNAVAILABLE"
+)
+
+// Error is returned by the directive when verification fails. It implements Extensions() of gqlgen's
+// graphql.ExtendedError, so clients receive {"extensions": {"code": "CAPTCHA_FAILED"}}.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]any {
+	return map[string]any{"code": e.Code}
+}
+
+type solutionContextKey struct{}
+
+// WithSolution returns context carrying the captcha solution, see HeaderMiddleware
+func WithSolution(ctx context.Context, solution string) context.Context {
+	return context.WithValue(ctx, solutionContextKey{}, solution)
+}
+
+// SolutionFromContext returns solution stored with WithSolution
+func SolutionFromContext(ctx context.Context) string {
+	solution, _ := ctx.Value(solutionContextKey{}).(string)
+	return solution
+}
+
+// HeaderMiddleware stores captcha solution from the request header in context, so that @captcha directive can
+// use it for mutations without captcha argument. It should wrap GraphQL handler (e.g. gqlgen's handler.Server).
+func HeaderMiddleware(header string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if solution := r.Header.Get(header); len(solution) > 0 {
+				r = r.WithContext(WithSolution(r.Context(), solution))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Options configures the directive. Solution is taken from Argument of the field (if set) and, if it is missing,
+// from context (see HeaderMiddleware).
+type Options struct {
+	// (optional) Name of the field argument with the solution
+	Argument string
+	// (optional) Returns arguments of the field, usually graphql.GetFieldContext(ctx).Args (required with Argument)
+	Args func(ctx context.Context) map[string]any
+	// (optional) Sitekey to verify solution against
+	Sitekey string
+}
+
+func (o *Options) solution(ctx context.Context) string {
+	if (len(o.Argument) > 0) && (o.Args != nil) {
+		// optional arguments of gqlgen are pointers
+		switch value := o.Args(ctx)[o.Argument].(type) {
+		case string:
+			if len(value) > 0 {
+				return value
+			}
+		case *string:
+			if (value != nil) && (len(*value) > 0) {
+				return *value
+			}
+		}
+	}
+
+	return SolutionFromContext(ctx)
+}
+
+// Directive returns implementation of @captcha directive, which verifies captcha solution before resolving the field
+func Directive[R ~func(ctx context.Context) (any, error)](verifier pc.SolutionVerifier, opts Options) func(ctx context.Context, obj any, next R) (any, error) {
+	return func(ctx context.Context, obj any, next R) (any, error) {
+		solution := opts.solution(ctx)
+		if len(solution) == 0 {
+			return nil, &Error{Code: CodeRequired, Message: "captcha solution is required"}
+		}
+
+		output, err := verifier.Verify(ctx, pc.VerifyInput{Solution: solution, Sitekey: opts.Sitekey})
+		if err != nil {
+			return nil, &Error{Code: CodeUnavailable, Message: "captcha verification is unavailable"}
+		}
+
+		if !output.OK() {
+			return nil, &Error{Code: CodeFailed, Message: "captcha verification failed"}
+		}
+
+		return next(pc.WithVerified(ctx))
+	}
+}
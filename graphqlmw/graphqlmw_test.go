@@ -0,0 +1,98 @@
+package graphqlmw
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+// resolver mirrors graphql.Resolver of gqlgen
+type resolver func(ctx context.Context) (any, error)
+
+type stubVerifier struct {
+	err error
+}
+
+func (v *stubVerifier) Verify(ctx context.Context, input pc.VerifyInput) (*pc.VerifyOutput, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+
+	if input.Solution == "good" {
+		return &pc.VerifyOutput{Success: true, Code: pc.VerifyNoError}, nil
+	}
+
+	return &pc.VerifyOutput{Success: false, Code: pc.ParseResponseError}, nil
+}
+
+type argsContextKey struct{}
+
+func TestDirective(t *testing.T) {
+	t.Parallel()
+
+	directive := Directive[resolver](&stubVerifier{}, Options{
+		Argument: "captcha",
+		Args: func(ctx context.Context) map[string]any {
+			args, _ := ctx.Value(argsContextKey{}).(map[string]any)
+			return args
+		},
+	})
+
+	next := func(ctx context.Context) (any, error) {
+		return pc.IsVerified(ctx), nil
+	}
+
+	good := "good"
+	testCases := []struct {
+		ctx  context.Context
+		code string
+	}{
+		{context.WithValue(context.TODO(), argsContextKey{}, map[string]any{"captcha": "good"}), ""},
+		{context.WithValue(context.TODO(), argsContextKey{}, map[string]any{"captcha": &good}), ""},
+		{context.WithValue(context.TODO(), argsContextKey{}, map[string]any{"captcha": "bad"}), CodeFailed},
+		{WithSolution(context.TODO(), "good"), ""},
+		{context.TODO(), CodeRequired},
+	}
+
+	for i, tc := range testCases {
+		result, err := directive(tc.ctx, nil, next)
+
+		var gqlErr *Error
+		if len(tc.code) > 0 {
+			if !errors.As(err, &gqlErr) || (gqlErr.Code != tc.code) || (gqlErr.Extensions()["code"] != tc.code) {
+				t.Errorf("Unexpected error (%v): %v", i, err)
+			}
+			continue
+		}
+
+		if (err != nil) || (result != true) {
+			t.Errorf("Unexpected result (%v): %v (%v)", i, result, err)
+		}
+	}
+
+	directive = Directive[resolver](&stubVerifier{err: errors.New("network error")}, Options{})
+	if _, err := directive(WithSolution(context.TODO(), "good"), nil, next); err.(*Error).Code != CodeUnavailable {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestHeaderMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var solution string
+	handler := HeaderMiddleware("X-Captcha")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		solution = SolutionFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	req.Header.Set("X-Captcha", "good")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if solution != "good" {
+		t.Errorf("Unexpected solution: %v", solution)
+	}
+}
@@ -0,0 +1,105 @@
+package privatecaptcha
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// GroupResult is the result of a single verification in VerifyGroup
+type GroupResult struct {
+	Output *VerifyOutput
+	// Err is set if verification failed, including unsuccessful verification (!Output.OK())
+	Err error
+}
+
+// VerifyGroup runs multiple verifications tied to one user action (e.g. bulk invitation form with per-row captchas)
+// concurrently. Like errgroup.Group, the first failure cancels the shared context, so remaining verifications
+// are aborted early, and Wait() reports results of all of them.
+type VerifyGroup struct {
+	provider Provider
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+	wg       sync.WaitGroup
+	sem      chan struct{}
+	mu       sync.Mutex
+	results  []GroupResult
+}
+
+// NewVerifyGroup creates a group verifying solutions with provider (usually *Client). Returned context is canceled
+// when the first verification fails or when Wait() returns.
+func NewVerifyGroup(ctx context.Context, provider Provider) (*VerifyGroup, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &VerifyGroup{provider: provider, ctx: ctx, cancel: cancel}, ctx
+}
+
+// SetLimit limits the number of verifications running concurrently, Go() blocks until a slot is available.
+// It must not be called after Go().
+func (g *VerifyGroup) SetLimit(n int) {
+	if n <= 0 {
+		g.sem = nil
+		return
+	}
+
+	g.sem = make(chan struct{}, n)
+}
+
+// Go starts verification of input in a new goroutine. Results are reported by Wait() in the order of Go() calls.
+func (g *VerifyGroup) Go(input VerifyInput) {
+	g.mu.Lock()
+	index := len(g.results)
+	g.results = append(g.results, GroupResult{})
+	g.mu.Unlock()
+
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			defer func() { <-g.sem }()
+		}
+
+		var output *VerifyOutput
+		err := contextError(g.ctx)
+		if err == nil {
+			output, err = g.provider.Verify(g.ctx, input)
+			if (err == nil) && !output.OK() {
+				err = fmt.Errorf("captcha verification failed: %v", output.Error())
+			}
+		}
+
+		if err != nil {
+			g.cancel(err)
+		}
+
+		g.mu.Lock()
+		g.results[index] = GroupResult{Output: output, Err: err}
+		g.mu.Unlock()
+	}()
+}
+
+// Wait waits for all verifications to finish and returns their results. Error is a *MultiVerifyError if any
+// of verifications failed.
+func (g *VerifyGroup) Wait() ([]GroupResult, error) {
+	g.wg.Wait()
+	g.cancel(nil)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var errs []error
+	for _, result := range g.results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return g.results, nil
+	}
+
+	return g.results, &MultiVerifyError{Policy: SolutionsAll, Total: len(g.results), Errors: errs}
+}
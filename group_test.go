@@ -0,0 +1,88 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestVerifyGroup(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{})
+
+	group, _ := NewVerifyGroup(context.TODO(), client)
+	group.SetLimit(2)
+	for i := 0; i < 5; i++ {
+		group.Go(VerifyInput{Solution: "asdf"})
+	}
+
+	results, err := group.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 5 {
+		t.Fatalf("Unexpected number of results: %v", len(results))
+	}
+
+	for i, result := range results {
+		if !result.Output.OK() {
+			t.Errorf("Unexpected result %v: %v", i, result.Output.Error())
+		}
+	}
+}
+
+type providerFunc func(ctx context.Context, input VerifyInput) (*VerifyOutput, error)
+
+func (f providerFunc) Verify(ctx context.Context, input VerifyInput) (*VerifyOutput, error) {
+	return f(ctx, input)
+}
+
+func TestVerifyGroupCancellation(t *testing.T) {
+	t.Parallel()
+
+	goodDone := make(chan struct{})
+	provider := providerFunc(func(ctx context.Context, input VerifyInput) (*VerifyOutput, error) {
+		switch input.Solution {
+		case "good":
+			defer close(goodDone)
+			return &VerifyOutput{Success: true, Code: VerifyNoError}, nil
+		case "slow":
+			select {
+			case <-ctx.Done():
+				return nil, contextError(ctx)
+			case <-time.After(5 * time.Second):
+				return &VerifyOutput{Success: true, Code: VerifyNoError}, nil
+			}
+		default:
+			// failure cancels the group, so it must come after the successful verification
+			<-goodDone
+			return &VerifyOutput{Success: false, Code: InvalidSolutionError}, nil
+		}
+	})
+
+	group, ctx := NewVerifyGroup(context.TODO(), provider)
+	group.Go(VerifyInput{Solution: "good"})
+	group.Go(VerifyInput{Solution: "slow", Attempts: 1})
+	group.Go(VerifyInput{Solution: "bad"})
+
+	results, err := group.Wait()
+
+	var multiErr *MultiVerifyError
+	if !errors.As(err, &multiErr) || (multiErr.Total != 3) || (len(multiErr.Errors) != 2) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if (results[0].Err != nil) || !errors.Is(results[1].Err, context.Canceled) || (results[2].Err == nil) {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+
+	if ctx.Err() == nil {
+		t.Error("Group context should be canceled")
+	}
+}
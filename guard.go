@@ -0,0 +1,36 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotVerified is returned by RequireVerified when context does not carry a successful captcha verification
+	ErrNotVerified = errors.New("privatecaptcha: request is not verified")
+)
+
+type verifiedContextKey struct{}
+
+// WithVerified marks context as passed captcha verification. Client.Middleware(), Client.VerifyFunc() and
+// AuthFlowGuard.LoginMiddleware() do it automatically for the requests they pass through.
+func WithVerified(ctx context.Context) context.Context {
+	return context.WithValue(ctx, verifiedContextKey{}, true)
+}
+
+// IsVerified returns true if context was marked with WithVerified
+func IsVerified(ctx context.Context) bool {
+	verified, _ := ctx.Value(verifiedContextKey{}).(bool)
+	return verified
+}
+
+// RequireVerified returns ErrNotVerified unless context passed captcha verification. It is intended to guard
+// writes of user-generated content in repositories or ORM hooks (e.g. GORM BeforeCreate), so that a new endpoint
+// which forgot to use captcha middleware fails loudly instead of silently accepting unverified content.
+func RequireVerified(ctx context.Context) error {
+	if !IsVerified(ctx) {
+		return ErrNotVerified
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequireVerified(t *testing.T) {
+	t.Parallel()
+
+	if err := RequireVerified(context.TODO()); err != ErrNotVerified {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := RequireVerified(WithVerified(context.TODO())); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestMiddlewareMarksVerified(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{})
+
+	var guardErr error
+	handler := client.Middleware(MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		guardErr = RequireVerified(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(DefaultFormField+"=asdf"))
+	req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code: %v", recorder.Code)
+	}
+
+	if guardErr != nil {
+		t.Errorf("Unexpected guard error: %v", guardErr)
+	}
+}
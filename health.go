@@ -0,0 +1,230 @@
+package privatecaptcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+type keyState int32
+
+const (
+	keyUnknown keyState = iota
+	keyValid
+	keyRejected
+)
+
+func (s keyState) String() string {
+	switch s {
+	case keyValid:
+		return "valid"
+	case keyRejected:
+		return "rejected"
+	default:
+		return "unknown"
+	}
+}
+
+// keyValidity tracks whether each API key (default and of Configuration.Keys) was accepted by the last verify
+// response sent with it
+type keyValidity struct {
+	mu     sync.Mutex
+	states map[string]keyState
+}
+
+func (kv *keyValidity) record(apiKey string, statusCode int) {
+	var state keyState
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		state = keyRejected
+	case keyAccepted(statusCode):
+		state = keyValid
+	default:
+		return
+	}
+
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	if kv.states == nil {
+		kv.states = make(map[string]keyState)
+	}
+	kv.states[apiKey] = state
+}
+
+// keyAccepted returns true if response with statusCode could only be sent after API key was authenticated, e.g.
+// http.StatusBadRequest for a malformed solution. Server errors and rate limiting can happen before authentication.
+func keyAccepted(statusCode int) bool {
+	switch {
+	case (statusCode == http.StatusUnauthorized) || (statusCode == http.StatusForbidden):
+		return false
+	case statusCode == http.StatusTooManyRequests:
+		return false
+	default:
+		return statusCode < http.StatusInternalServerError
+	}
+}
+
+func (kv *keyValidity) load(apiKey string) keyState {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	return kv.states[apiKey]
+}
+
+// keyValidationSolution is a placeholder sent by ValidateAPIKey(): API authenticates the request before parsing it
+const keyValidationSolution = "validate-api-key"
+
+// ValidateAPIKey sends a single verify request with a placeholder solution and returns ErrInvalidAPIKey if API key
+// was rejected by the API. Other errors (e.g. network ones) are returned as-is, any verification result or a client
+// error other than http.StatusUnauthorized and http.StatusForbidden (e.g. http.StatusBadRequest) means the key is valid. All of Configuration.Keys are validated too.
+func (c *Client) ValidateAPIKey(ctx context.Context) error {
+	if len(c.apiKey) > 0 {
+		if err := c.validateAPIKey(ctx); err != nil {
+			return err
+		}
+	}
+
+	if c.keys != nil {
+		for _, scope := range c.keys.scopes {
+			if err := c.validateAPIKey(withAPIKey(ctx, c.keys.keys[scope])); err != nil {
+				return fmt.Errorf("privatecaptcha: API key of scope %q: %w", scope, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) validateAPIKey(ctx context.Context) error {
+	if _, err := c.verify(ctx, VerifyInput{Solution: keyValidationSolution, Attempts: 1}); err != nil {
+		var originErr *OriginError
+		if errors.As(err, &originErr) {
+			return nil
+		}
+
+		// API rejects the placeholder solution (e.g. with http.StatusBadRequest) only after authenticating the request
+		if code, ok := GetStatusCode(err); ok && keyAccepted(code) {
+			c.logger.Log(ctx, levelTrace, "Validated API key", "status", code)
+			return nil
+		}
+
+		c.logger.Log(ctx, c.alertLevel(slog.LevelError), "Failed to validate API key", errAttr(err))
+		return err
+	}
+
+	c.logger.Log(ctx, levelTrace, "Validated API key")
+
+	return nil
+}
+
+// HealthStatus describes health of the verification path of the client, see Client.Health()
+type HealthStatus struct {
+	Healthy bool `json:"healthy"`
+	// APIKey is "valid" or "rejected" according to the last verify response sent with Configuration.APIKey
+	// ("unknown" before the first one)
+	APIKey string `json:"apiKey"`
+	// Keys are states of API keys of Configuration.Keys by scope, the same as APIKey
+	Keys map[string]string `json:"keys,omitempty"`
+	// Canary is "ok" or "failing" according to Configuration.Canary ("disabled" if not configured)
+	Canary string `json:"canary"`
+	// Failover is true when Configuration.Fallback is in use after primary failed
+	Failover bool `json:"failover"`
+	// Breaker is "closed" while primary is used, "open" while Configuration.Fallback is used after primary failed and
+	// "half-open" when primary has not recovered yet, but will be probed with the next verification
+	// ("disabled" without Configuration.Fallback)
+	Breaker string `json:"breaker"`
+	// Fallback is health of Configuration.Fallback, if it reports one (e.g. it is another *Client)
+	Fallback *HealthStatus `json:"fallback,omitempty"`
+	// Standby is "ok" or "failing" according to the last health check of Configuration.StandbyDomain ("unknown"
+	// before the first one and "disabled" if not configured)
+	Standby string `json:"standby"`
+	// FailOpen is true when Configuration.Fallback is configured, so failures of primary do not affect health
+	FailOpen    bool `json:"failOpen"`
+	Maintenance bool `json:"maintenance"`
+}
+
+// healthReporter is implemented by providers that report their health (e.g. *Client)
+type healthReporter interface {
+	Health() HealthStatus
+}
+
+// Health returns health of the verification path. Client is unhealthy when any of its API keys was rejected or
+// when primary is failing (Configuration.Canary is failing or failover breaker is not closed), unless
+// Configuration.Fallback is configured (fail-open) and it does not report to be unhealthy itself.
+func (c *Client) Health() HealthStatus {
+	status := HealthStatus{
+		APIKey:      c.keyValidity.load(c.apiKey).String(),
+		Canary:      "disabled",
+		Breaker:     "disabled",
+		Standby:     "disabled",
+		FailOpen:    c.failover != nil,
+		Maintenance: c.InMaintenance(),
+	}
+
+	keysHealthy := (len(c.apiKey) == 0) || (c.keyValidity.load(c.apiKey) != keyRejected)
+	if c.keys != nil {
+		status.Keys = make(map[string]string, len(c.keys.scopes))
+		for _, scope := range c.keys.scopes {
+			state := c.keyValidity.load(c.keys.keys[scope])
+			status.Keys[scope] = state.String()
+			keysHealthy = keysHealthy && (state != keyRejected)
+		}
+	}
+
+	canaryHealthy := c.Healthy()
+	if c.canary != nil {
+		status.Canary = "ok"
+		if !canaryHealthy {
+			status.Canary = "failing"
+		}
+	}
+
+	primaryHealthy := canaryHealthy
+	fallbackHealthy := false
+	if c.failover != nil {
+		status.Breaker = c.failover.breaker()
+		status.Failover = (status.Breaker == "open")
+		primaryHealthy = primaryHealthy && (status.Breaker == "closed")
+
+		fallbackHealthy = true
+		if reporter, ok := c.failover.fallback.(healthReporter); ok {
+			fallback := reporter.Health()
+			status.Fallback = &fallback
+			fallbackHealthy = fallback.Healthy
+		}
+	}
+
+	if c.standby != nil {
+		status.Standby = c.standby.status()
+	}
+
+	status.Healthy = keysHealthy && (primaryHealthy || fallbackHealthy)
+
+	return status
+}
+
+// HealthHandler returns http.Handler responding with Health() as JSON and http.StatusOK if the client is healthy
+// or http.StatusServiceUnavailable otherwise. It is intended to be mounted as (part of) readiness probe, e.g. /readyz.
+func (c *Client) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := c.Health()
+
+		code := http.StatusOK
+		if !status.Healthy {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set(headerContentType, "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+
+		if r.Method != http.MethodHead {
+			json.NewEncoder(w).Encode(&status)
+		}
+	})
+}
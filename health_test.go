@@ -0,0 +1,188 @@
+package privatecaptcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthHandler(t *testing.T) {
+	t.Parallel()
+
+	var authorized atomic.Bool
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !authorized.Load() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{})
+
+	check := func(expectedCode int, expectedKey string) {
+		t.Helper()
+
+		recorder := httptest.NewRecorder()
+		client.HealthHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		var status HealthStatus
+		if err := json.NewDecoder(recorder.Body).Decode(&status); err != nil {
+			t.Fatal(err)
+		}
+
+		if (recorder.Code != expectedCode) || (status.APIKey != expectedKey) || (status.Canary != "disabled") {
+			t.Errorf("Unexpected health: %v %+v", recorder.Code, status)
+		}
+	}
+
+	check(http.StatusOK, "unknown")
+
+	client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1})
+	check(http.StatusServiceUnavailable, "rejected")
+
+	authorized.Store(true)
+	client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1})
+	check(http.StatusOK, "valid")
+}
+
+func TestHealthFailOpen(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(Configuration{APIKey: "test-api-key", Canary: &CanaryOptions{Solution: "asdf", FailureThreshold: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.canary.record(CanaryResult{OK: false})
+	if client.Health().Healthy {
+		t.Error("Expected fail-closed client with failing canary to be unhealthy")
+	}
+
+	client.failover = &failover{fallback: &stubProvider{}}
+	if status := client.Health(); !status.Healthy || (status.Canary != "failing") || !status.FailOpen {
+		t.Errorf("Unexpected fail-open health: %+v", status)
+	}
+}
+
+func TestValidateAPIKeyOnStart(t *testing.T) {
+	t.Parallel()
+
+	var authorized atomic.Bool
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !authorized.Load() {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"success":false,"code":2}`))
+	}, Configuration{ValidateAPIKeyOnStart: true})
+	defer client.Close(context.TODO())
+
+	if err := client.Start(context.TODO()); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// unsuccessful verification still means that the key is valid
+	authorized.Store(true)
+	if err := client.Start(context.TODO()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if status := client.Health(); status.APIKey != "valid" {
+		t.Errorf("Unexpected API key status: %v", status.APIKey)
+	}
+}
+
+func TestValidateAPIKeyBadRequest(t *testing.T) {
+	t.Parallel()
+
+	// real API rejects placeholder solution as malformed
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}, Configuration{ValidateAPIKeyOnStart: true})
+	defer client.Close(context.TODO())
+
+	if err := client.Start(context.TODO()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if status := client.Health(); !status.Healthy || (status.APIKey != "valid") {
+		t.Errorf("Unexpected health: %+v", status)
+	}
+}
+
+func TestHealthScopedKeys(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(headerApiKey) == "revoked-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{Keys: map[string]string{"org1": "org1-key", "org2": "revoked-key"}})
+
+	client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Sitekey: "org1", Attempts: 1})
+	if status := client.Health(); !status.Healthy || (status.APIKey != "unknown") || (status.Keys["org1"] != "valid") || (status.Keys["org2"] != "unknown") {
+		t.Errorf("Unexpected health: %+v", status)
+	}
+
+	// rejected key of one scope does not mark others as rejected
+	client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Sitekey: "org2", Attempts: 1})
+	client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1})
+	if status := client.Health(); status.Healthy || (status.APIKey != "valid") || (status.Keys["org1"] != "valid") || (status.Keys["org2"] != "rejected") {
+		t.Errorf("Unexpected health: %+v", status)
+	}
+}
+
+func TestHealthFailoverBreaker(t *testing.T) {
+	t.Parallel()
+
+	fallback := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}, Configuration{})
+
+	client, err := NewClient(Configuration{APIKey: "test-api-key", StandbyDomain: "standby.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.failover = &failover{fallback: fallback, duration: time.Minute}
+	if status := client.Health(); !status.Healthy || (status.Breaker != "closed") || (status.Fallback == nil) || (status.Standby != "unknown") {
+		t.Errorf("Unexpected health: %+v", status)
+	}
+
+	// fallback is healthy until its key is rejected
+	client.failover.setFailed(true)
+	if status := client.Health(); !status.Healthy || (status.Breaker != "open") || !status.Failover {
+		t.Errorf("Unexpected health: %+v", status)
+	}
+
+	fallback.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1})
+	if status := client.Health(); status.Healthy || (status.Fallback.APIKey != "rejected") {
+		t.Errorf("Unexpected health: %+v", status)
+	}
+
+	// primary has not recovered yet after failover expired
+	client.failover.mu.Lock()
+	client.failover.failedAt = time.Now().Add(-2 * time.Minute)
+	client.failover.mu.Unlock()
+	if status := client.Health(); status.Healthy || (status.Breaker != "half-open") || status.Failover {
+		t.Errorf("Unexpected health: %+v", status)
+	}
+
+	client.failover.setFailed(false)
+	if status := client.Health(); !status.Healthy || (status.Breaker != "closed") {
+		t.Errorf("Unexpected health: %+v", status)
+	}
+
+	client.standby.mu.Lock()
+	client.standby.checkedAt, client.standby.lastErr = time.Now(), errNoStandby
+	client.standby.mu.Unlock()
+	if status := client.Health(); status.Standby != "failing" {
+		t.Errorf("Unexpected standby health: %v", status.Standby)
+	}
+}
@@ -0,0 +1,109 @@
+package privatecaptcha
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// SlowCallInfo describes a verify attempt that exceeded Configuration.SlowCallThreshold
+type SlowCallInfo struct {
+	// Attempt is a zero-based attempt number
+	Attempt  int
+	Endpoint string
+	Duration time.Duration
+	Err      error
+	// SolutionDigest is a short hash of the solution, see VerifyOutput.SolutionDigest()
+	SolutionDigest string
+}
+
+// LoadShedReason describes why verification was shed
+type LoadShedReason int
+
+const (
+	// LoadShedLimiter means that verification did not get a slot of Configuration.Limiter in time
+	LoadShedLimiter LoadShedReason = iota
+	// LoadShedRetryBudget means that verification failed and was not retried because Configuration.RetryBudget is exhausted
+	LoadShedRetryBudget
+)
+
+func (r LoadShedReason) String() string {
+	switch r {
+	case LoadShedLimiter:
+		return "limiter"
+	case LoadShedRetryBudget:
+		return "retry-budget"
+	default:
+		return "unknown"
+	}
+}
+
+// LoadShedInfo describes a verification shed by the client, see Configuration.OnLoadShed
+type LoadShedInfo struct {
+	Reason LoadShedReason
+	// Limit is the current concurrency limit of Configuration.Limiter (zero if not configured)
+	Limit int
+	// InFlight is the number of verify requests in progress in Configuration.Limiter (zero if not configured)
+	InFlight int
+	// Total is the number of verifications shed by this client so far, including this one
+	Total int64
+}
+
+// StaleGraceInfo describes a recently expired grace cookie accepted during maintenance mode of the API,
+// see Configuration.OnStaleGrace
+type StaleGraceInfo struct {
+	// Expired is how long ago the grace cookie expired
+	Expired time.Duration
+	// Total is the number of stale grace cookies accepted by this client so far, including this one
+	Total int64
+}
+
+// staleGrace notifies OnStaleGrace hook
+func (c *Client) staleGrace(ctx context.Context, expired time.Duration) {
+	total := c.staleGraceCount.Add(1)
+
+	c.logger.Log(ctx, slog.LevelInfo, "Accepted stale grace cookie during maintenance mode", "expired", expired.String(), "total", total)
+
+	if c.onStaleGrace != nil {
+		c.onStaleGrace(ctx, StaleGraceInfo{Expired: expired, Total: total})
+	}
+}
+
+// loadShed notifies OnLoadShed hook and wraps err with ErrLoadShed
+func (c *Client) loadShed(ctx context.Context, reason LoadShedReason, err error) error {
+	total := c.shedCount.Add(1)
+
+	if c.onLoadShed != nil {
+		c.onLoadShed(ctx, LoadShedInfo{
+			Reason:   reason,
+			Limit:    c.limiter.Limit(),
+			InFlight: c.limiter.InFlight(),
+			Total:    total,
+		})
+	}
+
+	return fmt.Errorf("%w: %w", ErrLoadShed, err)
+}
+
+// captureResponse reads the body of verify response and passes a copy of the response to OnResponse hook,
+// so that the body can still be read by the client
+func (c *Client) captureResponse(ctx context.Context, resp *http.Response) error {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.logger.Log(ctx, levelTrace, "Failed to read response body", errAttr(err))
+		return err
+	}
+
+	raw := *resp
+	raw.Header = resp.Header.Clone()
+	raw.Body = io.NopCloser(bytes.NewReader(data))
+	c.onResponse(ctx, &raw)
+
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSlowCallHook(t *testing.T) {
+	t.Parallel()
+
+	var calls []SlowCallInfo
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{
+		SlowCallThreshold: 10 * time.Millisecond,
+		OnSlowCall: func(ctx context.Context, info SlowCallInfo) {
+			calls = append(calls, info)
+		},
+	})
+
+	if _, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(calls) != 1 {
+		t.Fatalf("Unexpected number of slow calls: %v", len(calls))
+	}
+
+	if (calls[0].Attempt != 0) || (calls[0].Endpoint != client.Endpoint()) || (calls[0].Duration < 50*time.Millisecond) {
+		t.Errorf("Unexpected slow call info: %+v", calls[0])
+	}
+}
+
+func TestLoadShedHook(t *testing.T) {
+	t.Parallel()
+
+	var calls []LoadShedInfo
+	limiter := NewAdaptiveLimiter(1, 1, 1)
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, Configuration{
+		Limiter:     limiter,
+		RetryBudget: NewRetryBudget(0, 0),
+		OnLoadShed: func(ctx context.Context, info LoadShedInfo) {
+			calls = append(calls, info)
+		},
+	})
+
+	_, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 3})
+	if !errors.Is(err, ErrLoadShed) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if code, ok := GetStatusCode(err); !ok || (code != http.StatusServiceUnavailable) {
+		t.Errorf("Unexpected status code: %v", code)
+	}
+
+	// occupy the only slot of the limiter
+	if err := limiter.acquire(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = client.Verify(ctx, VerifyInput{Solution: "asdf", Attempts: 1})
+	if !errors.Is(err, ErrLoadShed) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(calls) != 2 {
+		t.Fatalf("Unexpected number of load shed calls: %v", len(calls))
+	}
+
+	if (calls[0].Reason != LoadShedRetryBudget) || (calls[1].Reason != LoadShedLimiter) || (calls[1].InFlight != 1) || (calls[1].Total != 2) {
+		t.Errorf("Unexpected load shed info: %+v", calls)
+	}
+}
+
+func TestResponseHook(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	var bodies []string
+	var statuses []int
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Provider", "test")
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("unavailable"))
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0,"score":0.9}`))
+	}, Configuration{
+		OnResponse: func(ctx context.Context, resp *http.Response) {
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			if resp.Header.Get("X-Provider") != "test" {
+				t.Errorf("Unexpected headers: %v", resp.Header)
+			}
+			bodies = append(bodies, string(data))
+			statuses = append(statuses, resp.StatusCode)
+		},
+	})
+
+	output, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 2, MaxBackoffSeconds: 1})
+	if (err != nil) || !output.OK() {
+		t.Fatalf("Unexpected result: %v (%v)", output.Error(), err)
+	}
+
+	if (len(statuses) != 2) || (statuses[0] != http.StatusServiceUnavailable) || (statuses[1] != http.StatusOK) {
+		t.Errorf("Unexpected statuses: %v", statuses)
+	}
+
+	if (len(bodies) != 2) || (bodies[0] != "unavailable") || (bodies[1] != `{"success":true,"code":0,"score":0.9}`) {
+		t.Errorf("Unexpected bodies: %v", bodies)
+	}
+}
@@ -0,0 +1,32 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+)
+
+// Doer sends HTTP requests, it is implemented by *http.Client and most HTTP client libraries
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// SolutionVerifier verifies a single solution. It is the same as Provider and is declared for dependency
+// injection frameworks (wire, fx, dig), where consumers bind to small interfaces instead of *Client.
+type SolutionVerifier = Provider
+
+// RequestVerifier verifies solution sent with the incoming HTTP request
+type RequestVerifier interface {
+	VerifyRequest(ctx context.Context, r *http.Request) error
+}
+
+// MiddlewareProvider creates http middleware verifying captcha
+type MiddlewareProvider interface {
+	Middleware(opts MiddlewareOptions) func(http.Handler) http.Handler
+}
+
+var (
+	_ Doer               = (*http.Client)(nil)
+	_ SolutionVerifier   = (*Client)(nil)
+	_ RequestVerifier    = (*Client)(nil)
+	_ MiddlewareProvider = (*Client)(nil)
+)
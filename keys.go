@@ -0,0 +1,193 @@
+package privatecaptcha
+
+import (
+	"container/list"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// maxLearnedSitekeys limits the number of sitekeys, whose scopes are remembered after OrgScopeError
+const maxLearnedSitekeys = 1024
+
+// KeyResolver returns the scope of Configuration.Keys to verify the solution with (e.g. organization owning the
+// property with input.Sitekey). Empty scope means that the key is not known and Configuration.APIKey is used.
+type KeyResolver func(ctx context.Context, input *VerifyInput) string
+
+// KeysBySitekey returns KeyResolver selecting the scope by the sitekey of verification
+func KeysBySitekey(scopes map[string]string) KeyResolver {
+	return func(ctx context.Context, input *VerifyInput) string {
+		return scopes[input.Sitekey]
+	}
+}
+
+type apiKeyContextKey struct{}
+
+func withAPIKey(ctx context.Context, apiKey string) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, apiKey)
+}
+
+func apiKeyFromContext(ctx context.Context) string {
+	apiKey, _ := ctx.Value(apiKeyContextKey{}).(string)
+	return apiKey
+}
+
+// scopedKeys routes verifications to API keys of several organizations
+type scopedKeys struct {
+	keys     map[string]string
+	scopes   []string
+	resolver KeyResolver
+	// learned maps sitekeys to scopes found after OrgScopeError
+	learned *scopeCache
+}
+
+type scopeEntry struct {
+	sitekey string
+	scope   string
+}
+
+// scopeCache is a LRU cache of scopes by sitekey
+type scopeCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newScopeCache(max int) *scopeCache {
+	return &scopeCache{max: max, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (sc *scopeCache) Load(sitekey string) (string, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if e, ok := sc.entries[sitekey]; ok {
+		sc.order.MoveToFront(e)
+		return e.Value.(*scopeEntry).scope, true
+	}
+
+	return "", false
+}
+
+func (sc *scopeCache) Store(sitekey, scope string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if e, ok := sc.entries[sitekey]; ok {
+		e.Value.(*scopeEntry).scope = scope
+		sc.order.MoveToFront(e)
+		return
+	}
+
+	sc.entries[sitekey] = sc.order.PushFront(&scopeEntry{sitekey: sitekey, scope: scope})
+
+	if sc.order.Len() > sc.max {
+		oldest := sc.order.Back()
+		sc.order.Remove(oldest)
+		delete(sc.entries, oldest.Value.(*scopeEntry).sitekey)
+	}
+}
+
+func (sc *scopeCache) Delete(sitekey string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if e, ok := sc.entries[sitekey]; ok {
+		sc.order.Remove(e)
+		delete(sc.entries, sitekey)
+	}
+}
+
+func (sc *scopeCache) Len() int {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	return sc.order.Len()
+}
+
+func newScopedKeys(cfg *Configuration) *scopedKeys {
+	if len(cfg.Keys) == 0 {
+		return nil
+	}
+
+	resolver := cfg.KeyResolver
+	if resolver == nil {
+		resolver = func(ctx context.Context, input *VerifyInput) string {
+			if _, ok := cfg.Keys[input.Sitekey]; ok {
+				return input.Sitekey
+			}
+			return ""
+		}
+	}
+
+	return &scopedKeys{
+		keys:     maps.Clone(cfg.Keys),
+		scopes:   slices.Sorted(maps.Keys(cfg.Keys)),
+		resolver: resolver,
+		learned:  newScopeCache(maxLearnedSitekeys),
+	}
+}
+
+// resolve returns the scope of the key identified by resolver
+func (k *scopedKeys) resolve(ctx context.Context, input *VerifyInput) (string, bool) {
+	if scope := k.resolver(ctx, input); len(scope) > 0 {
+		if _, ok := k.keys[scope]; ok {
+			return scope, true
+		}
+	}
+
+	return "", false
+}
+
+// verifyScoped verifies solution with the key of the resolved scope. When the key was not identified and
+// the API responds with OrgScopeError, other keys are tried in order of their scopes, and the one that verified
+// the solution successfully is remembered for the sitekey (until it results in OrgScopeError).
+func (c *Client) verifyScoped(ctx context.Context, input VerifyInput) (*VerifyOutput, error) {
+	if c.keys == nil {
+		return c.verify(ctx, input)
+	}
+
+	if scope, ok := c.keys.resolve(ctx, &input); ok {
+		return c.verify(withAPIKey(ctx, c.keys.keys[scope]), input)
+	}
+
+	if len(input.Sitekey) > 0 {
+		if scope, ok := c.keys.learned.Load(input.Sitekey); ok {
+			output, err := c.verify(withAPIKey(ctx, c.keys.keys[scope]), input)
+			if (err != nil) || (output.Code != OrgScopeError) {
+				return output, err
+			}
+
+			c.logger.Log(ctx, levelTrace, "Forgetting scope of the sitekey after OrgScopeError", "scope", scope)
+			c.keys.learned.Delete(input.Sitekey)
+		}
+	}
+
+	var output *VerifyOutput
+	var err error
+	if len(c.apiKey) > 0 {
+		if output, err = c.verify(ctx, input); (err != nil) || (output.Code != OrgScopeError) {
+			return output, err
+		}
+	}
+
+	for _, scope := range c.keys.scopes {
+		c.logger.Log(ctx, levelTrace, "Retrying verification with API key of another scope", "scope", scope)
+
+		output, err = c.verify(withAPIKey(ctx, c.keys.keys[scope]), input)
+		if (err == nil) && (output.Code == OrgScopeError) {
+			continue
+		}
+
+		// only successful verifications prove that the sitekey belongs to the scope
+		if (err == nil) && output.OK() && (len(input.Sitekey) > 0) {
+			c.keys.learned.Store(input.Sitekey, scope)
+		}
+
+		break
+	}
+
+	return output, err
+}
@@ -0,0 +1,148 @@
+package privatecaptcha
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestScopedKeys(t *testing.T) {
+	t.Parallel()
+
+	// property "site-b" belongs to organization "org-b"
+	var mu sync.Mutex
+	var keys []string
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(headerApiKey)
+		mu.Lock()
+		keys = append(keys, key)
+		mu.Unlock()
+
+		if (r.Header.Get(headerSitekey) == "site-b") && (key != "key-b") {
+			w.Write([]byte(`{"success":false,"code":12}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{Keys: map[string]string{"org-a": "key-a", "org-b": "key-b"}})
+
+	verify := func(sitekey string) []string {
+		t.Helper()
+
+		mu.Lock()
+		keys = nil
+		mu.Unlock()
+
+		output, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1, Sitekey: sitekey})
+		if (err != nil) || !output.OK() {
+			t.Fatalf("Unexpected result for %v: %v (%v)", sitekey, output.Error(), err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		return keys
+	}
+
+	// unknown scope: default key and then scoped keys are tried in order until the one of the right organization
+	if used := verify("site-b"); (len(used) != 3) || (used[0] != "test-api-key") || (used[1] != "key-a") || (used[2] != "key-b") {
+		t.Errorf("Unexpected keys: %v", used)
+	}
+
+	// scope of the sitekey is remembered
+	if used := verify("site-b"); (len(used) != 1) || (used[0] != "key-b") {
+		t.Errorf("Unexpected keys after learning: %v", used)
+	}
+
+	// scope resolved explicitly
+	client.keys.resolver = KeysBySitekey(map[string]string{"site-c": "org-b"})
+	if used := verify("site-c"); (len(used) != 1) || (used[0] != "key-b") {
+		t.Errorf("Unexpected keys with resolver: %v", used)
+	}
+}
+
+func TestScopedKeysConfiguration(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewClient(Configuration{Keys: map[string]string{"org-a": "key-a"}}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := NewClient(Configuration{Keys: map[string]string{"org-a": ""}}); err != errEmptyAPIKey {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestScopedKeysLearning(t *testing.T) {
+	t.Parallel()
+
+	// organization owning "site-x" can change (e.g. property was moved)
+	var mu sync.Mutex
+	owner := "key-b"
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Header.Get(headerApiKey) != owner {
+			w.Write([]byte(`{"success":false,"code":12}`))
+			return
+		}
+		if body, _ := io.ReadAll(r.Body); string(body) != "good" {
+			w.Write([]byte(`{"success":false,"code":3}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{Keys: map[string]string{"org-a": "key-a", "org-b": "key-b"}})
+
+	verify := func(solution string) *VerifyOutput {
+		t.Helper()
+		output, err := client.Verify(context.TODO(), VerifyInput{Solution: solution, Attempts: 1, Sitekey: "site-x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return output
+	}
+
+	// unsuccessful verifications are not remembered
+	verify("bad")
+	if _, ok := client.keys.learned.Load("site-x"); ok {
+		t.Errorf("Scope was learned from unsuccessful verification")
+	}
+
+	if !verify("good").OK() {
+		t.Fatal("Verification failed")
+	}
+	if scope, _ := client.keys.learned.Load("site-x"); scope != "org-b" {
+		t.Errorf("Unexpected learned scope: %v", scope)
+	}
+
+	// remembered scope is forgotten after OrgScopeError
+	mu.Lock()
+	owner = "key-a"
+	mu.Unlock()
+
+	if !verify("good").OK() {
+		t.Fatal("Verification failed after changing owner")
+	}
+	if scope, _ := client.keys.learned.Load("site-x"); scope != "org-a" {
+		t.Errorf("Unexpected learned scope after changing owner: %v", scope)
+	}
+}
+
+func TestScopeCacheLimit(t *testing.T) {
+	t.Parallel()
+
+	cache := newScopeCache(2)
+	cache.Store("a", "org-a")
+	cache.Store("b", "org-b")
+	cache.Load("a")
+	cache.Store("c", "org-c")
+
+	if _, ok := cache.Load("b"); ok || (cache.Len() != 2) {
+		t.Errorf("Least recently used sitekey was not evicted")
+	}
+
+	if scope, ok := cache.Load("a"); !ok || (scope != "org-a") {
+		t.Errorf("Unexpected scope: %v", scope)
+	}
+}
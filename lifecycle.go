@@ -0,0 +1,111 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+var (
+	errAlreadyStarted = errors.New("privatecaptcha: client is already started")
+	errClosed         = errors.New("privatecaptcha: client is closed")
+)
+
+// subsystem is a background goroutine of the client
+type subsystem struct {
+	name   string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type lifecycle struct {
+	mu         sync.Mutex
+	started    bool
+	closed     bool
+	subsystems []*subsystem
+}
+
+// startSubsystems runs background subsystems configured for the client, in order
+func (c *Client) startSubsystems(ctx context.Context) {
+	if c.canary != nil {
+		c.lifecycle.spawn(ctx, "canary", func(ctx context.Context) {
+			c.RunCanary(ctx)
+		})
+	}
+
+	if c.standby != nil {
+		c.lifecycle.spawn(ctx, "standby", c.runStandbyChecks)
+	}
+}
+
+func (l *lifecycle) spawn(ctx context.Context, name string, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &subsystem{name: name, cancel: cancel, done: make(chan struct{})}
+	l.subsystems = append(l.subsystems, s)
+
+	go func() {
+		defer close(s.done)
+		run(ctx)
+	}()
+}
+
+// Start starts background subsystems configured for the client (e.g. Configuration.Canary). Subsystems keep running
+// until Close() even if ctx is canceled, so it can be a short-lived context of application startup (e.g. fx.Hook).
+// Client is fully functional without calling Start(), but background features are not running then.
+// With Configuration.ValidateAPIKeyOnStart, API key is validated first and its error is returned.
+func (c *Client) Start(ctx context.Context) error {
+	c.lifecycle.mu.Lock()
+	defer c.lifecycle.mu.Unlock()
+
+	if c.lifecycle.closed {
+		return errClosed
+	}
+
+	if c.lifecycle.started {
+		return errAlreadyStarted
+	}
+
+	if c.validateKey {
+		if err := c.ValidateAPIKey(ctx); err != nil {
+			return err
+		}
+	}
+
+	c.lifecycle.started = true
+	c.startSubsystems(context.WithoutCancel(ctx))
+
+	return nil
+}
+
+// Close stops background subsystems in reverse order of their start and waits for them to exit until ctx is done,
+// after which idle connections of the http client created by the SDK are closed. It is safe to call Close() more
+// than once, but the client cannot be started again.
+func (c *Client) Close(ctx context.Context) error {
+	c.lifecycle.mu.Lock()
+	defer c.lifecycle.mu.Unlock()
+
+	c.lifecycle.closed = true
+
+	for i := len(c.lifecycle.subsystems) - 1; i >= 0; i-- {
+		s := c.lifecycle.subsystems[i]
+		s.cancel()
+
+		select {
+		case <-s.done:
+			c.logger.Log(ctx, levelTrace, "Stopped background subsystem", "name", s.name)
+		case <-ctx.Done():
+			c.logger.Log(ctx, slog.LevelWarn, "Timed out waiting for background subsystem to stop", "name", s.name)
+			return contextError(ctx)
+		}
+
+		c.lifecycle.subsystems = c.lifecycle.subsystems[:i]
+	}
+
+	if hc, ok := c.client.(*http.Client); ok && c.ownsClient {
+		hc.CloseIdleConnections()
+	}
+
+	return nil
+}
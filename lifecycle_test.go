@@ -0,0 +1,114 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// waitGoroutines waits until the number of goroutines drops to expected
+func waitGoroutines(t *testing.T, expected int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > expected {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("Unexpected goroutines: %v (expected %v)\n%s", runtime.NumGoroutine(), expected, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// not parallel to count goroutines reliably
+func TestLifecycleNoLeaks(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	canaries := make(chan struct{}, 1)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}))
+
+	client, err := NewClient(Configuration{APIKey: "test-api-key", Domain: srv.URL, Client: srv.Client(), Canary: &CanaryOptions{
+		Solution: "asdf",
+		Interval: time.Millisecond,
+		OnResult: func(ctx context.Context, result CanaryResult) {
+			select {
+			case canaries <- struct{}{}:
+			default:
+			}
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	startCtx, cancel := context.WithCancel(context.TODO())
+	if err := client.Start(startCtx); err != nil {
+		t.Fatal(err)
+	}
+	// canceled startup context does not stop subsystems
+	cancel()
+
+	<-canaries
+	<-canaries
+
+	if err := client.Start(context.TODO()); err != errAlreadyStarted {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := client.Close(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Close(context.TODO()); err != nil {
+		t.Errorf("Unexpected error of repeated close: %v", err)
+	}
+
+	if err := client.Start(context.TODO()); err != errClosed {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	srv.Client().CloseIdleConnections()
+	srv.Close()
+	waitGoroutines(t, before)
+}
+
+func TestLifecycleCloseOrder(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(Configuration{APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// subsystems are stopped one by one, so appends do not race
+	var stopped []string
+	for _, name := range []string{"first", "second"} {
+		client.lifecycle.spawn(context.TODO(), name, func(ctx context.Context) {
+			<-ctx.Done()
+			stopped = append(stopped, name)
+		})
+	}
+
+	if err := client.Close(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	if (len(stopped) != 2) || (stopped[0] != "second") || (stopped[1] != "first") {
+		t.Errorf("Unexpected stop order: %v", stopped)
+	}
+
+	blocked := make(chan struct{})
+	defer close(blocked)
+	client.lifecycle.spawn(context.TODO(), "blocked", func(ctx context.Context) { <-blocked })
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	if err := client.Close(ctx); err == nil {
+		t.Error("Expected close timeout")
+	}
+}
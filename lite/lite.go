@@ -0,0 +1,116 @@
+// Package lite is a reduced-feature Private Captcha client for constrained environments (e.g. embedded gateways
+// built with TinyGo). It does not depend on log/slog and reflection-based encoding/json, and only verifies
+// solutions: there are no retries, policies, failover, hooks or middleware. Use the main package everywhere else.
+package lite
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/PrivateCaptcha/private-captcha-go/codes"
+)
+
+const (
+	// GlobalDomain is the domain of Private Captcha API
+	GlobalDomain = "api.privatecaptcha.com"
+	// maxResponseSize limits verify response read by the client
+	maxResponseSize = 16 * 1024
+	userAgent       = "private-captcha-go-lite/1.0"
+)
+
+var (
+	errEmptyAPIKey   = errors.New("privatecaptcha: API key is empty")
+	errEmptySolution = errors.New("privatecaptcha: solution is empty")
+	errResponse      = errors.New("privatecaptcha: failed to parse verify response")
+)
+
+// HTTPError is returned when the API responds with unexpected status code
+type HTTPError struct {
+	StatusCode int
+}
+
+func (e HTTPError) Error() string {
+	return "privatecaptcha: unexpected HTTP status " + strconv.Itoa(e.StatusCode)
+}
+
+// Doer sends HTTP requests, it is implemented by *http.Client
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Result is the result of solution verification
+type Result struct {
+	Success bool
+	Code    codes.Code
+}
+
+// OK returns true if verification succeeded
+func (r Result) OK() bool {
+	return r.Success && (r.Code == codes.NoError)
+}
+
+// Client verifies solutions with Private Captcha API
+type Client struct {
+	endpoint string
+	apiKey   string
+	doer     Doer
+}
+
+// NewClient creates a client. Domain defaults to GlobalDomain and doer defaults to http.DefaultClient.
+func NewClient(apiKey string, domain string, doer Doer) (*Client, error) {
+	if len(apiKey) == 0 {
+		return nil, errEmptyAPIKey
+	}
+
+	if len(domain) == 0 {
+		domain = GlobalDomain
+	}
+	domain = strings.TrimPrefix(strings.TrimPrefix(domain, "https://"), "http://")
+
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+
+	return &Client{
+		endpoint: "https://" + strings.Trim(domain, "/") + "/verify",
+		apiKey:   apiKey,
+		doer:     doer,
+	}, nil
+}
+
+// Verify verifies solution once (without retries)
+func (c *Client) Verify(ctx context.Context, solution string) (Result, error) {
+	if len(solution) == 0 {
+		return Result{}, errEmptySolution
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, strings.NewReader(solution))
+	if err != nil {
+		return Result{}, err
+	}
+
+	req.Header.Set("X-Api-Key", c.apiKey)
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Content-Type", "text/plain")
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return Result{}, HTTPError{StatusCode: resp.StatusCode}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return Result{}, err
+	}
+
+	return parseResult(data)
+}
@@ -0,0 +1,130 @@
+package lite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/PrivateCaptcha/private-captcha-go/codes"
+)
+
+func TestParseResult(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		data   string
+		result Result
+		ok     bool
+	}{
+		{`{"success":true,"code":0}`, Result{Success: true}, true},
+		{` { "code" : 3 , "success" : false } `, Result{Code: codes.InvalidSolution}, true},
+		{`{"success":true,"code":0,"origin":"a\"}b","x":{"y":[1,{"z":"}"}]},"n":null}`, Result{Success: true}, true},
+		{`{}`, Result{}, true},
+		{`{"success":"true"}`, Result{}, false},
+		{`{"code":1.5}`, Result{}, false},
+		{`{"success":true`, Result{}, false},
+		{`[]`, Result{}, false},
+		{``, Result{}, false},
+	}
+
+	for _, tc := range testCases {
+		result, err := parseResult([]byte(tc.data))
+		if (err == nil) != tc.ok {
+			t.Errorf("Unexpected error for %q: %v", tc.data, err)
+			continue
+		}
+
+		if result != tc.result {
+			t.Errorf("Unexpected result for %q: %+v", tc.data, result)
+		}
+	}
+}
+
+func FuzzParseResult(f *testing.F) {
+	f.Add([]byte(`{"success":true,"code":0}`))
+	f.Add([]byte(`{"success":false,"code":3,"origin":"example.com","signals":{"a":[1,2]}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		result, err := parseResult(data)
+
+		// only well-formed responses are compared, scanner is allowed to be more lenient
+		var fields map[string]json.RawMessage
+		if (json.Unmarshal(data, &fields) != nil) || (fields == nil) || strings.Contains(string(data), `\`) {
+			return
+		}
+
+		var success bool
+		var code int
+		if raw, ok := fields["success"]; ok && (json.Unmarshal(raw, &success) != nil) {
+			return
+		}
+		if raw, ok := fields["code"]; ok && (json.Unmarshal(raw, &code) != nil) {
+			return
+		}
+
+		if err != nil {
+			t.Fatalf("Failed to parse valid response %q: %v", data, err)
+		}
+
+		if (result.Success != success) || (result.Code != codes.Code(code)) {
+			t.Errorf("Unexpected result for %q: %+v (expected %v %v)", data, result, success, code)
+		}
+	})
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "test-api-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0,"timestamp":"2025-01-01T00:00:00Z"}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient("test-api-key", srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.Verify(context.TODO(), "solution")
+	if (err != nil) || !result.OK() {
+		t.Errorf("Unexpected result: %+v (%v)", result, err)
+	}
+
+	client, _ = NewClient("wrong-api-key", srv.URL, srv.Client())
+	if _, err := client.Verify(context.TODO(), "solution"); err != (HTTPError{StatusCode: http.StatusUnauthorized}) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := NewClient("", "", nil); err != errEmptyAPIKey {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// packages that TinyGo cannot compile (or that are too heavy for it) must not be imported
+func TestDependencies(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("Skipping go toolchain test in short mode")
+	}
+
+	output, err := exec.Command("go", "list", "-deps", ".").CombinedOutput()
+	if err != nil {
+		t.Skipf("go list failed: %v", err)
+	}
+
+	for _, pkg := range strings.Fields(string(output)) {
+		switch pkg {
+		case "log/slog", "encoding/json", "github.com/jpillora/backoff", "github.com/PrivateCaptcha/private-captcha-go":
+			t.Errorf("Unexpected dependency: %v", pkg)
+		}
+	}
+}
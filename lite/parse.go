@@ -0,0 +1,172 @@
+package lite
+
+import (
+	"strconv"
+
+	"github.com/PrivateCaptcha/private-captcha-go/codes"
+)
+
+// scanner is a minimal JSON scanner, which is enough to decode verify response without reflection
+type scanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\n', '\r':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (s *scanner) consume(c byte) bool {
+	if (s.pos < len(s.data)) && (s.data[s.pos] == c) {
+		s.pos++
+		return true
+	}
+
+	return false
+}
+
+// str returns raw (not unescaped) contents of JSON string
+func (s *scanner) str() (string, bool) {
+	if !s.consume('"') {
+		return "", false
+	}
+
+	start := s.pos
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '"':
+			value := string(s.data[start:s.pos])
+			s.pos++
+			return value, true
+		default:
+			s.pos++
+		}
+	}
+
+	return "", false
+}
+
+// scalar returns number or literal (true, false, null)
+func (s *scanner) scalar() (string, bool) {
+	start := s.pos
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ',', '}', ']', ' ', '\t', '\n', '\r':
+			return string(s.data[start:s.pos]), s.pos > start
+		default:
+			s.pos++
+		}
+	}
+
+	return string(s.data[start:s.pos]), s.pos > start
+}
+
+// skipValue skips any JSON value, including nested objects and arrays
+func (s *scanner) skipValue() bool {
+	if s.pos >= len(s.data) {
+		return false
+	}
+
+	switch s.data[s.pos] {
+	case '"':
+		_, ok := s.str()
+		return ok
+	case '{', '[':
+		depth := 0
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case '"':
+				if _, ok := s.str(); !ok {
+					return false
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+			s.pos++
+			if depth == 0 {
+				return true
+			}
+		}
+		return false
+	default:
+		_, ok := s.scalar()
+		return ok
+	}
+}
+
+// parseResult decodes "success" and "code" fields of the verify response, ignoring the rest
+func parseResult(data []byte) (Result, error) {
+	var result Result
+	s := &scanner{data: data}
+
+	s.skipSpace()
+	if !s.consume('{') {
+		return Result{}, errResponse
+	}
+
+	s.skipSpace()
+	if s.consume('}') {
+		return result, nil
+	}
+
+	for {
+		s.skipSpace()
+		key, ok := s.str()
+		if !ok {
+			return Result{}, errResponse
+		}
+
+		s.skipSpace()
+		if !s.consume(':') {
+			return Result{}, errResponse
+		}
+		s.skipSpace()
+
+		switch key {
+		case "success":
+			value, _ := s.scalar()
+			switch value {
+			case "true":
+				result.Success = true
+			case "false":
+				result.Success = false
+			default:
+				return Result{}, errResponse
+			}
+		case "code":
+			value, _ := s.scalar()
+			code, err := strconv.Atoi(value)
+			if err != nil {
+				return Result{}, errResponse
+			}
+			result.Code = codes.Code(code)
+		default:
+			if !s.skipValue() {
+				return Result{}, errResponse
+			}
+		}
+
+		s.skipSpace()
+		if s.consume(',') {
+			continue
+		}
+
+		if s.consume('}') {
+			return result, nil
+		}
+
+		return Result{}, errResponse
+	}
+}
@@ -8,6 +8,9 @@ const (
 	levelTrace = slog.Level(-8)
 )
 
+// discardLogger is the default logger of the client
+var discardLogger = slog.New(slog.DiscardHandler)
+
 func errAttr(err error) slog.Attr {
 	return slog.Any("error", err)
 }
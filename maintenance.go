@@ -0,0 +1,116 @@
+package privatecaptcha
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// MaintenanceWindow is a known period of planned maintenance of the captcha service (e.g. of a self-hosted deployment)
+type MaintenanceWindow struct {
+	Start time.Time
+	End   time.Time
+	// (optional) How Middleware and VerifyFunc handle requests that failed verification during the window, e.g.
+	// FailureMonitor to let users through while the service is unavailable and there is no Configuration.Fallback.
+	// FailureMonitor takes precedence over WithFailurePolicy(), enforcing rules and modulators
+	// (defaults to FailureReject, which keeps the policy of the middleware)
+	FailurePolicy FailurePolicy
+}
+
+// Contains returns true if t is within [Start, End)
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+// maintenance keeps scheduled maintenance windows of the primary provider
+type maintenance struct {
+	mu      sync.RWMutex
+	windows []MaintenanceWindow
+}
+
+func (m *maintenance) active(now time.Time) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, w := range m.windows {
+		if w.Contains(now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// policy returns failure policy of the active maintenance window (FailureMonitor wins if windows overlap)
+func (m *maintenance) policy(now time.Time) (FailurePolicy, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	policy, active := FailureReject, false
+	for _, w := range m.windows {
+		if w.Contains(now) {
+			active = true
+			if w.FailurePolicy == FailureMonitor {
+				policy = FailureMonitor
+			}
+		}
+	}
+
+	return policy, active
+}
+
+func (m *maintenance) add(windows ...MaintenanceWindow) {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// drop windows that are already over
+	current := m.windows[:0]
+	for _, w := range m.windows {
+		if w.End.After(now) {
+			current = append(current, w)
+		}
+	}
+
+	for _, w := range windows {
+		if w.End.After(w.Start) && w.End.After(now) {
+			current = append(current, w)
+		}
+	}
+
+	m.windows = current
+}
+
+// AddMaintenanceWindow schedules a maintenance window of the captcha service. During the window, verifications
+// go directly to Configuration.Fallback (if configured) or are attempted only once without retries, OnSlowCall is not
+// invoked, failed verifications are handled according to MaintenanceWindow.FailurePolicy and error logs of the
+// verification path are downgraded to slog.LevelInfo, so that they do not trigger alerts. Client returns to the
+// normal mode automatically after the window ends.
+func (c *Client) AddMaintenanceWindow(w MaintenanceWindow) {
+	c.maintenance.add(w)
+}
+
+// InMaintenance returns true if one of the scheduled maintenance windows is active right now. It can be used
+// to suppress custom alerting (e.g. in OnResult hook) during planned maintenance.
+func (c *Client) InMaintenance() bool {
+	return c.maintenance.active(time.Now())
+}
+
+// maintenanceFailurePolicy returns FailureMonitor during maintenance windows configured with it or fallback otherwise
+func (c *Client) maintenanceFailurePolicy(fallback FailurePolicy) FailurePolicy {
+	if policy, ok := c.maintenance.policy(time.Now()); ok && (policy == FailureMonitor) {
+		return policy
+	}
+
+	return fallback
+}
+
+// alertLevel returns level of logs that usually trigger alerts, downgraded to slog.LevelInfo during maintenance windows
+func (c *Client) alertLevel(level slog.Level) slog.Level {
+	if (level > slog.LevelInfo) && c.InMaintenance() {
+		return slog.LevelInfo
+	}
+
+	return level
+}
@@ -0,0 +1,169 @@
+package privatecaptcha
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindowFallback(t *testing.T) {
+	t.Parallel()
+
+	var primaryCalls atomic.Int32
+	fallback := &stubProvider{}
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls.Add(1)
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{Fallback: fallback})
+
+	input := VerifyInput{Solution: "asdf", Attempts: 1}
+
+	if _, err := client.Verify(context.TODO(), input); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	client.AddMaintenanceWindow(MaintenanceWindow{Start: now.Add(-time.Minute), End: now.Add(200 * time.Millisecond)})
+	if !client.InMaintenance() {
+		t.Fatal("Client should be in maintenance")
+	}
+
+	if _, err := client.Verify(context.TODO(), input); err != nil {
+		t.Fatal(err)
+	}
+
+	if (primaryCalls.Load() != 1) || (fallback.calls.Load() != 1) {
+		t.Errorf("Unexpected calls during maintenance: primary=%v fallback=%v", primaryCalls.Load(), fallback.calls.Load())
+	}
+
+	time.Sleep(250 * time.Millisecond)
+
+	if client.InMaintenance() {
+		t.Fatal("Maintenance window should be over")
+	}
+
+	if _, err := client.Verify(context.TODO(), input); err != nil {
+		t.Fatal(err)
+	}
+
+	if (primaryCalls.Load() != 2) || (fallback.calls.Load() != 1) {
+		t.Errorf("Unexpected calls after maintenance: primary=%v fallback=%v", primaryCalls.Load(), fallback.calls.Load())
+	}
+}
+
+func TestMaintenanceWindowPrune(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	m := &maintenance{}
+	m.add(MaintenanceWindow{Start: now.Add(-time.Hour), End: now.Add(-time.Minute)},
+		MaintenanceWindow{Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)},
+		MaintenanceWindow{Start: now.Add(time.Hour), End: now})
+
+	if len(m.windows) != 1 {
+		t.Errorf("Unexpected windows count: %v", len(m.windows))
+	}
+
+	if m.active(now) || !m.active(now.Add(90*time.Minute)) {
+		t.Error("Unexpected maintenance state")
+	}
+}
+
+func TestMaintenanceWindowFailurePolicy(t *testing.T) {
+	t.Parallel()
+
+	var primaryCalls atomic.Int32
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, Configuration{})
+
+	var passed, verified atomic.Int32
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		passed.Add(1)
+		if IsVerified(r.Context()) {
+			verified.Add(1)
+		}
+	})
+
+	handlers := []http.Handler{
+		client.Middleware(MiddlewareOptions{})(next),
+		client.VerifyFunc(next),
+	}
+
+	serve := func(h http.Handler) int {
+		req := httptest.NewRequest(http.MethodPost, "/test", nil)
+		req.PostForm = url.Values{DefaultFormField: []string{"asdf"}}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	now := time.Now()
+	client.AddMaintenanceWindow(MaintenanceWindow{Start: now.Add(-time.Minute), End: now.Add(time.Hour), FailurePolicy: FailureMonitor})
+
+	for i, h := range handlers {
+		if code := serve(h); code != http.StatusOK {
+			t.Errorf("Unexpected status code of handler %d during maintenance: %v", i, code)
+		}
+	}
+
+	if (passed.Load() != 2) || (verified.Load() != 0) {
+		t.Errorf("Unexpected requests during maintenance: passed=%v verified=%v", passed.Load(), verified.Load())
+	}
+
+	// verifications are not retried during maintenance
+	if primaryCalls.Load() != 2 {
+		t.Errorf("Unexpected calls during maintenance: %v", primaryCalls.Load())
+	}
+
+	// windows without the policy keep the policy of the middleware
+	client.maintenance = &maintenance{}
+	client.AddMaintenanceWindow(MaintenanceWindow{Start: now.Add(-time.Minute), End: now.Add(time.Hour)})
+
+	if code := serve(handlers[0]); code != http.StatusForbidden {
+		t.Errorf("Unexpected status code during maintenance without policy: %v", code)
+	}
+}
+
+func TestMaintenanceWindowLogs(t *testing.T) {
+	t.Parallel()
+
+	handler := &recordingHandler{}
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}, Configuration{Logger: slog.New(handler)})
+
+	maxLevel := func() slog.Level {
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+
+		level := slog.LevelDebug
+		for _, l := range handler.levels {
+			level = max(level, l)
+		}
+		handler.levels = nil
+		return level
+	}
+
+	input := VerifyInput{Solution: "asdf", Attempts: 1}
+
+	client.Verify(context.TODO(), input)
+	if level := maxLevel(); level != slog.LevelError {
+		t.Errorf("Unexpected log level outside of maintenance: %v", level)
+	}
+
+	now := time.Now()
+	client.AddMaintenanceWindow(MaintenanceWindow{Start: now.Add(-time.Minute), End: now.Add(time.Hour)})
+
+	client.Verify(context.TODO(), input)
+	if level := maxLevel(); level > slog.LevelInfo {
+		t.Errorf("Unexpected log level during maintenance: %v", level)
+	}
+}
@@ -0,0 +1,82 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	// MessageMetaSitekey is the metadata key used by VerifyMessage to read the sitekey
+	MessageMetaSitekey = "sitekey"
+)
+
+// MessageError is returned by VerifyMessage and tells the consumer what to do with the message.
+// Retriable errors should cause the message to be redelivered later (nack), while permanent ones
+// will never succeed and the message should be dropped or sent to a dead-letter queue.
+type MessageError struct {
+	Err       error
+	Retriable bool
+}
+
+func (e MessageError) Error() string {
+	if e.Retriable {
+		return fmt.Sprintf("privatecaptcha: retriable message error: %v", e.Err)
+	}
+
+	return fmt.Sprintf("privatecaptcha: permanent message error: %v", e.Err)
+}
+
+func (e MessageError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetriable returns true if the error returned from VerifyMessage is worth retrying
+func IsRetriable(err error) bool {
+	var msgErr MessageError
+	if errors.As(err, &msgErr) {
+		return msgErr.Retriable
+	}
+
+	return false
+}
+
+// isPermanentError returns true for errors of local validation of the input, configuration or the response,
+// which will be the same when the message is redelivered
+func isPermanentError(err error) bool {
+	if errors.Is(err, errEmtpySolution) || errors.Is(err, ErrSolutionTooLong) || errors.Is(err, errVerifyPath) ||
+		errors.Is(err, ErrResponseSignature) {
+		return true
+	}
+
+	var originErr *OriginError
+	return errors.As(err, &originErr)
+}
+
+// VerifyMessage verifies solution that arrived as a payload of a queue message (Kafka, SQS etc.) as part
+// of asynchronous form processing. Metadata can contain the sitekey (MessageMetaSitekey).
+// Returned errors are always of MessageError type and should be used to decide whether to ack or nack the message.
+func (c *Client) VerifyMessage(ctx context.Context, payload []byte, meta map[string]string) (*VerifyOutput, error) {
+	if len(payload) == 0 {
+		return nil, MessageError{Err: errEmtpySolution}
+	}
+
+	output, err := c.Verify(ctx, VerifyInput{Solution: string(payload), Sitekey: meta[MessageMetaSitekey]})
+	if err != nil {
+		retriable := !isPermanentError(err)
+		if code, ok := GetStatusCode(err); ok {
+			retriable = isRetriableStatus(code)
+		}
+
+		return output, MessageError{Err: err, Retriable: retriable}
+	}
+
+	if !output.OK() {
+		return output, MessageError{
+			Err:       fmt.Errorf("captcha verification failed: %v", output.Error()),
+			Retriable: output.Code == MaintenanceModeError,
+		}
+	}
+
+	return output, nil
+}
@@ -0,0 +1,332 @@
+package privatecaptcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxBodySize is the default limit of request body size accepted by Middleware. Forms with file uploads
+	// may need a larger limit set with MiddlewareOptions.MaxBodySize
+	DefaultMaxBodySize = 1 << 20
+	// DefaultMaxFormMemory is the default limit of memory used to parse multipart forms in Middleware,
+	// larger parts are stored in temporary files (net/http uses 32 MB by default)
+	DefaultMaxFormMemory = 1 << 20
+)
+
+var (
+	headerHXRequest      = http.CanonicalHeaderKey("HX-Request")
+	headerXRequestedWith = http.CanonicalHeaderKey("X-Requested-With")
+	headerAccept         = http.CanonicalHeaderKey("Accept")
+)
+
+// MiddlewareOptions customizes a single middleware instance created with Client.Middleware()
+type MiddlewareOptions struct {
+	// (optional) http status to return for failed verifications of regular requests (defaults to Configuration.FailedStatusCode)
+	FailedStatusCode int
+	// (optional) http status to return for failed verifications of HTMX and fetch/XHR requests (defaults to http.StatusUnprocessableEntity)
+	AsyncFailedStatusCode int
+	// (optional) HTML partial to respond with to HTMX requests on failure (JSON response is used if empty)
+	HTMXFailureHTML string
+	// (optional) Respond to HTMX and fetch/XHR requests the same way as to regular requests
+	DisableAsyncDetection bool
+	// (optional) Do not recover from panics in the verification path (they are converted to 500 by default)
+	DisableRecovery bool
+	// (optional) Where to read the solution from, e.g. Extractors{FromForm("field"), FromHeader("X-Captcha")}
+	// (defaults to the form field configured for the client)
+	Extractor Extractor
+	// (optional) Accept signed "verified human" cookie, issued after successful verification, instead of a new solution
+	GraceCookie *GraceCookie
+	// (optional) Let trusted devices (e.g. kiosks) with signed device tokens bypass verification
+	Devices *DeviceAllowlist
+	// (optional) Maximum request body size, larger requests are rejected with http.StatusRequestEntityTooLarge
+	// (defaults to DefaultMaxBodySize, negative value disables the limit)
+	MaxBodySize int64
+	// (optional) Maximum memory used to parse multipart forms (defaults to DefaultMaxFormMemory)
+	MaxFormMemory int64
+	// (optional) Select client, sitekey and failure handling per tenant at request time (e.g. TenantRegistry.Resolve)
+	TenantResolver TenantResolver
+	// (optional) How to handle requests that failed verification (defaults to FailureReject), it can be overridden
+	// per request with WithFailurePolicy()
+	FailurePolicy FailurePolicy
+	// (optional) Minimum time to respond to failed verifications, counted from the start of the request, so that
+	// local rejections (e.g. missing solution) and rejections by the API take the same time and timing does not tell
+	// which step rejected the request. It should exceed typical latency of the API (e.g. 1 second)
+	FailureDelay time.Duration
+	// (optional) Maximum random delay added to FailureDelay to smooth out the remaining latency differences
+	FailureJitter time.Duration
+	// (optional) Hook to tighten or relax enforcement per request at runtime
+	Modulator PolicyModulator
+	// (optional) Request header with the country code of the client IP set by upstream CDN or load balancer,
+	// e.g. "CF-IPCountry" (passed to Modulator)
+	CountryHeader string
+}
+
+// panicError is returned from the verification path when it panicked
+type panicError struct {
+	value any
+}
+
+func (e panicError) Error() string {
+	return fmt.Sprintf("privatecaptcha: panic during verification: %v", e.value)
+}
+
+type verifyRequestFunc func(ctx context.Context, r *http.Request) error
+
+func safeVerifyRequest(ctx context.Context, logger *slog.Logger, r *http.Request, verify verifyRequestFunc) (err error) {
+	defer func() {
+		if rvr := recover(); rvr != nil {
+			if rvr == http.ErrAbortHandler {
+				panic(rvr)
+			}
+
+			logger.Log(ctx, slog.LevelError, "Recovered from panic during verification", "panic", rvr, "stack", string(debug.Stack()))
+			err = panicError{value: rvr}
+		}
+	}()
+
+	return verify(ctx, r)
+}
+
+type requestKind int
+
+const (
+	requestRegular requestKind = iota
+	requestHTMX
+	requestFetch
+)
+
+func detectRequestKind(r *http.Request) requestKind {
+	if r.Header.Get(headerHXRequest) == "true" {
+		return requestHTMX
+	}
+
+	if strings.EqualFold(r.Header.Get(headerXRequestedWith), "XMLHttpRequest") {
+		return requestFetch
+	}
+
+	if accept := r.Header.Get(headerAccept); strings.HasPrefix(accept, "application/json") {
+		return requestFetch
+	}
+
+	return requestRegular
+}
+
+type failureResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+func (c *Client) writeFailure(w http.ResponseWriter, r *http.Request, opts *MiddlewareOptions) {
+	kind := requestRegular
+	if !opts.DisableAsyncDetection {
+		kind = detectRequestKind(r)
+	}
+
+	switch kind {
+	case requestHTMX:
+		if len(opts.HTMXFailureHTML) > 0 {
+			w.Header().Set(headerContentType, "text/html; charset=utf-8")
+			w.WriteHeader(opts.AsyncFailedStatusCode)
+			w.Write([]byte(opts.HTMXFailureHTML))
+			return
+		}
+		fallthrough
+	case requestFetch:
+		w.Header().Set(headerContentType, "application/json")
+		w.WriteHeader(opts.AsyncFailedStatusCode)
+		json.NewEncoder(w).Encode(&failureResponse{
+			Success: false,
+			Error:   http.StatusText(opts.FailedStatusCode),
+		})
+	default:
+		http.Error(w, http.StatusText(opts.FailedStatusCode), opts.FailedStatusCode)
+	}
+}
+
+func (c *Client) middlewareDefaults(opts *MiddlewareOptions) {
+	if opts.FailedStatusCode == 0 {
+		opts.FailedStatusCode = c.failedStatusCode
+	}
+
+	if opts.AsyncFailedStatusCode == 0 {
+		opts.AsyncFailedStatusCode = http.StatusUnprocessableEntity
+	}
+
+	if opts.MaxBodySize == 0 {
+		opts.MaxBodySize = DefaultMaxBodySize
+	}
+
+	if opts.MaxFormMemory <= 0 {
+		opts.MaxFormMemory = DefaultMaxFormMemory
+	}
+}
+
+// parseForm parses request form within memory limits of the middleware, so that extractors do not parse it
+// with net/http defaults (r.FormValue() uses up to 32 MB of memory for multipart forms)
+func parseForm(w http.ResponseWriter, r *http.Request, opts *MiddlewareOptions) error {
+	if (opts.MaxBodySize > 0) && (r.Body != nil) {
+		// reject without reading the body if the size is known upfront
+		if r.ContentLength > opts.MaxBodySize {
+			return &http.MaxBytesError{Limit: opts.MaxBodySize}
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, opts.MaxBodySize)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(headerContentType))
+	if mediaType == "multipart/form-data" {
+		return r.ParseMultipartForm(opts.MaxFormMemory)
+	}
+
+	return r.ParseForm()
+}
+
+// delayFailure waits until FailureDelay (plus random FailureJitter) passes since the start of the request
+func (o *MiddlewareOptions) delayFailure(ctx context.Context, start time.Time) {
+	delay := o.FailureDelay - time.Since(start)
+	if o.FailureJitter > 0 {
+		delay += rand.N(o.FailureJitter)
+	}
+
+	if delay > 0 {
+		sleepContext(ctx, delay)
+	}
+}
+
+// Middleware creates http middleware that verifies captcha solution sent via form. Unlike VerifyFunc,
+// failures of HTMX and fetch-based requests are reported with HTML partial or JSON instead of a full-page error.
+//
+// Memory used by the middleware per request is bounded: request body is limited to MaxBodySize, multipart forms
+// use at most MaxFormMemory (plus net/http overhead for non-file parts), FromJSON reads at most DefaultMaxJSONBodySize,
+// headers are bounded by http.Server.MaxHeaderBytes and the outgoing verify request is limited by
+// Configuration.MaxSolutionLength. No goroutines are started per request.
+func (c *Client) Middleware(opts MiddlewareOptions) func(http.Handler) http.Handler {
+	c.middlewareDefaults(&opts)
+
+	verifyWith := func(client *Client) verifyRequestFunc {
+		if opts.Extractor != nil {
+			return func(ctx context.Context, r *http.Request) error {
+				return client.verifyRequestWith(ctx, r, opts.Extractor)
+			}
+		}
+		return client.VerifyRequest
+	}
+	defaultVerify := verifyWith(c)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ctx := r.Context()
+			verify := defaultVerify
+			failureOpts := &opts
+
+			if opts.TenantResolver != nil {
+				tenant := opts.TenantResolver(r)
+				if tenant.Disabled {
+					c.logger.Log(ctx, levelTrace, "Skipping verification for disabled tenant")
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				if tenant.Client != nil {
+					verify = verifyWith(tenant.Client)
+				}
+
+				if len(tenant.Sitekey) > 0 {
+					ctx = withSitekey(ctx, tenant.Sitekey)
+				}
+
+				if tenant.FailedStatusCode != 0 {
+					tenantOpts := opts
+					tenantOpts.FailedStatusCode = tenant.FailedStatusCode
+					failureOpts = &tenantOpts
+				}
+			}
+
+			if opts.Modulator != nil {
+				var ok bool
+				if ctx, ok = c.modulate(ctx, r, &opts, start); !ok {
+					c.logger.Log(ctx, levelTrace, "Skipping verification by policy modulator")
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			if (opts.Devices != nil) && c.bypassDevice(ctx, r, opts.Devices) {
+				next.ServeHTTP(w, r.WithContext(WithVerified(r.Context())))
+				return
+			}
+
+			if (opts.GraceCookie != nil) && opts.GraceCookie.valid(r) {
+				c.logger.Log(r.Context(), levelTrace, "Accepted grace cookie instead of solution")
+				next.ServeHTTP(w, r.WithContext(WithVerified(r.Context())))
+				return
+			}
+
+			if err := parseForm(w, r, &opts); err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					c.logger.Log(r.Context(), levelTrace, "Request body is too large", "limit", maxBytesErr.Limit)
+					opts.delayFailure(ctx, start)
+					http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+					return
+				}
+				c.logger.Log(r.Context(), levelTrace, "Failed to parse form", errAttr(err))
+			}
+
+			if c.faults != nil {
+				// chaos testing only flips results of verifications in monitor-only mode
+				ctx = WithFailurePolicy(ctx, c.maintenanceFailurePolicy(failurePolicy(ctx, opts.FailurePolicy)))
+			}
+
+			var err error
+			if opts.DisableRecovery {
+				err = verify(ctx, r)
+			} else {
+				err = safeVerifyRequest(ctx, c.logger, r, verify)
+			}
+
+			if err != nil {
+				if _, ok := err.(panicError); ok {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+
+				if (opts.GraceCookie != nil) && isMaintenanceMode(err) {
+					if expired, ok := opts.GraceCookie.stale(r, time.Now()); ok {
+						c.staleGrace(ctx, expired)
+						next.ServeHTTP(w, r.WithContext(WithVerified(r.Context())))
+						return
+					}
+				}
+
+				if policy := c.maintenanceFailurePolicy(failurePolicy(ctx, opts.FailurePolicy)); policy == FailureMonitor {
+					c.logger.Log(ctx, slog.LevelInfo, "Passing request that failed verification", "policy", policy.String(), errAttr(err))
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				opts.delayFailure(ctx, start)
+				c.writeFailure(w, r, failureOpts)
+				return
+			}
+
+			if opts.GraceCookie != nil {
+				if err := opts.GraceCookie.issue(w, r); err != nil {
+					c.logger.Log(r.Context(), slog.LevelError, "Failed to issue grace cookie", errAttr(err))
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithVerified(r.Context())))
+		})
+	}
+}
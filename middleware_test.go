@@ -0,0 +1,229 @@
+package privatecaptcha
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareAsyncFailures(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(Configuration{APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const partial = `<div class="error">Captcha failed</div>`
+	handler := client.Middleware(MiddlewareOptions{HTMXFailureHTML: partial})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Handler should not be called")
+	}))
+
+	testCases := []struct {
+		header      string
+		value       string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"", "", http.StatusForbidden, "text/plain; charset=utf-8", "Forbidden"},
+		{headerHXRequest, "true", http.StatusUnprocessableEntity, "text/html; charset=utf-8", partial},
+		{headerXRequestedWith, "XMLHttpRequest", http.StatusUnprocessableEntity, "application/json", `"success":false`},
+		{headerAccept, "application/json", http.StatusUnprocessableEntity, "application/json", `"error":"Forbidden"`},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodPost, "/test", nil)
+		req.PostForm = url.Values{}
+		if len(tc.header) > 0 {
+			req.Header.Set(tc.header, tc.value)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != tc.status {
+			t.Errorf("Unexpected status code %d for %v", recorder.Code, tc.header)
+		}
+
+		if ct := recorder.Header().Get(headerContentType); ct != tc.contentType {
+			t.Errorf("Unexpected content type %v for %v", ct, tc.header)
+		}
+
+		if !strings.Contains(recorder.Body.String(), tc.body) {
+			t.Errorf("Unexpected body %v for %v", recorder.Body.String(), tc.header)
+		}
+	}
+}
+
+type panicTransport struct{}
+
+func (panicTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	panic("unexpected")
+}
+
+func TestMiddlewarePanicRecovery(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(Configuration{
+		APIKey: "test-api-key",
+		Client: &http.Client{Transport: panicTransport{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := client.Middleware(MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Handler should not be called")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/test", nil)
+	req.PostForm = url.Values{DefaultFormField: []string{"asdf"}}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Unexpected status code: %d", recorder.Code)
+	}
+}
+
+// staticTransport responds to all requests with the same body without network access
+type staticTransport struct {
+	body string
+}
+
+func (t staticTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(t.body)),
+		Request:    req,
+	}, nil
+}
+
+func newStaticClient(tb testing.TB) *Client {
+	client, err := NewClient(Configuration{
+		APIKey: "test-api-key",
+		Client: &http.Client{Transport: staticTransport{body: `{"success":true,"code":0}`}},
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return client
+}
+
+func TestMiddlewareBodyLimit(t *testing.T) {
+	t.Parallel()
+
+	client := newStaticClient(t)
+	handler := client.Middleware(MiddlewareOptions{MaxBodySize: 1024})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	body := DefaultFormField + "=asdf&junk=" + strings.Repeat("a", 2048)
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+	req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Unexpected status code: %d", recorder.Code)
+	}
+
+	// body size is not known upfront (chunked encoding)
+	req = httptest.NewRequest(http.MethodPost, "/test", io.MultiReader(strings.NewReader(body)))
+	req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+	req.ContentLength = -1
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Unexpected status code of chunked request: %d", recorder.Code)
+	}
+}
+
+func TestMiddlewareMultipartForm(t *testing.T) {
+	t.Parallel()
+
+	client := newStaticClient(t)
+	handler := client.Middleware(MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField(DefaultFormField, "asdf")
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/test", &buf)
+	req.Header.Set(headerContentType, writer.FormDataContentType())
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Unexpected status code: %d", recorder.Code)
+	}
+}
+
+func TestMiddlewareFailureDelay(t *testing.T) {
+	t.Parallel()
+
+	const delay = 100 * time.Millisecond
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":false,"code":3}`))
+	}, Configuration{})
+
+	handler := client.Middleware(MiddlewareOptions{FailureDelay: delay})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Handler should not be called")
+	}))
+
+	// local rejection (empty solution) and rejection by the API take at least the same time
+	for _, form := range []url.Values{{}, {DefaultFormField: {"asdf"}}} {
+		req := httptest.NewRequest(http.MethodPost, "/test", nil)
+		req.PostForm = form
+
+		start := time.Now()
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if elapsed := time.Since(start); (recorder.Code != http.StatusForbidden) || (elapsed < delay) {
+			t.Errorf("Unexpected response for %v: %v after %v", form, recorder.Code, elapsed)
+		}
+	}
+}
+
+func benchmarkMiddleware(b *testing.B, body string) {
+	client := newStaticClient(b)
+	handler := client.Middleware(MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+		req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func BenchmarkMiddlewareValidForm(b *testing.B) {
+	benchmarkMiddleware(b, DefaultFormField+"="+strings.Repeat("a", 1024))
+}
+
+func BenchmarkMiddlewareJunkForm(b *testing.B) {
+	// junk posts larger than the body limit must be rejected without buffering them
+	benchmarkMiddleware(b, "junk="+strings.Repeat("a", 10*DefaultMaxBodySize))
+}
@@ -0,0 +1,73 @@
+// Package hcaptcha mirrors API of popular Go hCaptcha library (github.com/kataras/hcaptcha) over Private Captcha,
+// see package migrate. Solutions are read from privatecaptcha.DefaultFormField instead of "h-captcha-response".
+package hcaptcha
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+	"github.com/PrivateCaptcha/private-captcha-go/migrate"
+)
+
+// Response is siteverify response
+type Response = migrate.Response
+
+// ResponseFormValue is the form field with the solution
+const ResponseFormValue = pc.DefaultFormField
+
+// DefaultFailureHandler responds with http.StatusTooManyRequests like the original library
+var DefaultFailureHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+})
+
+// Client verifies solutions of requests with the secret
+type Client struct {
+	// FailureHandler is called by Handler when verification fails
+	FailureHandler http.Handler
+
+	secret string
+}
+
+// New creates Client with the secret (Private Captcha API key)
+func New(secret string) *Client {
+	return &Client{FailureHandler: DefaultFailureHandler, secret: secret}
+}
+
+// Verify verifies the response with the secret
+func Verify(secret, response, remoteip string) (Response, error) {
+	return migrate.Verify(context.Background(), secret, response, remoteip)
+}
+
+// SiteVerify verifies solution of the request. Failures to verify are reported in Response.ErrorCodes
+func (c *Client) SiteVerify(r *http.Request) (response Response) {
+	remoteip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteip = r.RemoteAddr
+	}
+
+	response, err = migrate.Verify(r.Context(), c.secret, r.FormValue(ResponseFormValue), remoteip)
+	if err != nil {
+		response.ErrorCodes = append(response.ErrorCodes, err.Error())
+	}
+
+	return response
+}
+
+// Handler verifies requests before calling next handler, failures are handled with FailureHandler
+func (c *Client) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.SiteVerify(r).Success {
+			c.FailureHandler.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(pc.WithVerified(r.Context())))
+	})
+}
+
+// HandlerFunc is like Handler for http.HandlerFunc
+func (c *Client) HandlerFunc(next func(http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return c.Handler(http.HandlerFunc(next)).ServeHTTP
+}
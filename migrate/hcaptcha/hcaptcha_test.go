@@ -0,0 +1,44 @@
+package hcaptcha
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+	"github.com/PrivateCaptcha/private-captcha-go/migrate"
+)
+
+func TestHandler(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if solution, _ := io.ReadAll(r.Body); string(solution) != "good" {
+			w.Write([]byte(`{"success":false,"code":3}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	migrate.SetConfiguration(pc.Configuration{Domain: srv.URL, Client: srv.Client()})
+
+	client := New("secret")
+	handler := client.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !pc.IsVerified(r.Context()) {
+			t.Errorf("Request is not marked as verified")
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for solution, expected := range map[string]int{"good": http.StatusOK, "bad": http.StatusTooManyRequests, "": http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(ResponseFormValue+"="+solution))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		if w.Code != expected {
+			t.Errorf("Unexpected status for %q: %v", solution, w.Code)
+		}
+	}
+}
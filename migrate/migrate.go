@@ -0,0 +1,104 @@
+// Package migrate implements Private Captcha verification behind function signatures of popular Go reCAPTCHA
+// and hCaptcha libraries, so that switching providers in a large codebase is mostly a change of import paths.
+// Secrets of the original libraries are Private Captcha API keys.
+//
+// Subpackages recaptcha and hcaptcha mirror the APIs of the respective libraries, while this package contains
+// the shared siteverify-like Response and the clients they use.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+// Error codes of Response in siteverify format, codes of Private Captcha are reported as is (e.g. "solution-invalid")
+const (
+	ErrorMissingInputResponse = "missing-input-response"
+	ErrorInvalidInputSecret   = "invalid-input-secret"
+)
+
+// Response mirrors siteverify response of reCAPTCHA and hCaptcha
+type Response struct {
+	Success     bool      `json:"success"`
+	ChallengeTS time.Time `json:"challenge_ts"`
+	Hostname    string    `json:"hostname"`
+	ErrorCodes  []string  `json:"error-codes,omitempty"`
+}
+
+var (
+	mu      sync.Mutex
+	base    pc.Configuration
+	clients = make(map[string]*pc.Client)
+)
+
+// SetConfiguration sets configuration of clients created by shims (e.g. Domain of EU or self-hosted deployment),
+// APIKey of it is ignored in favor of secrets passed to shims
+func SetConfiguration(cfg pc.Configuration) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	base = cfg
+	clients = make(map[string]*pc.Client)
+}
+
+// Client returns client with the secret as API key, clients are reused between calls
+func Client(secret string) (*pc.Client, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if client, ok := clients[secret]; ok {
+		return client, nil
+	}
+
+	cfg := base
+	cfg.APIKey = secret
+	client, err := pc.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	clients[secret] = client
+
+	return client, nil
+}
+
+// Verify verifies the solution and converts the result to siteverify Response. Unsuccessful verifications are not
+// errors, only failures to verify are (with the exception of invalid secret, reported as ErrorInvalidInputSecret)
+func Verify(ctx context.Context, secret, response, remoteip string) (Response, error) {
+	if len(response) == 0 {
+		return Response{ErrorCodes: []string{ErrorMissingInputResponse}}, nil
+	}
+
+	client, err := Client(secret)
+	if err != nil {
+		return Response{}, err
+	}
+
+	input := pc.VerifyInput{Solution: response}
+	if len(remoteip) > 0 {
+		input.ClientHints = map[string]string{pc.HintRemoteIP: remoteip}
+	}
+
+	output, err := client.Verify(ctx, input)
+	if err != nil {
+		if errors.Is(err, pc.ErrInvalidAPIKey) {
+			return Response{ErrorCodes: []string{ErrorInvalidInputSecret}}, nil
+		}
+		return Response{}, err
+	}
+
+	result := Response{Success: output.OK(), Hostname: output.Origin}
+	if ts, err := time.Parse(time.RFC3339, output.Timestamp); err == nil {
+		result.ChallengeTS = ts
+	}
+
+	if !result.Success {
+		result.ErrorCodes = []string{output.Error()}
+	}
+
+	return result, nil
+}
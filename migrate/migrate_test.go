@@ -0,0 +1,50 @@
+package migrate
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+func TestVerify(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if solution, _ := io.ReadAll(r.Body); string(solution) != "good" {
+			w.Write([]byte(`{"success":false,"code":3}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0,"origin":"example.com","timestamp":"2025-01-02T03:04:05Z"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	SetConfiguration(pc.Configuration{Domain: srv.URL, Client: srv.Client()})
+
+	resp, err := Verify(context.TODO(), "secret", "good", "192.0.2.1")
+	if (err != nil) || !resp.Success || (resp.Hostname != "example.com") || (resp.ChallengeTS.Year() != 2025) {
+		t.Errorf("Unexpected response: %+v (%v)", resp, err)
+	}
+
+	testCases := []struct {
+		secret   string
+		response string
+		code     string
+	}{
+		{"secret", "bad", pc.InvalidSolutionError.String()},
+		{"secret", "", ErrorMissingInputResponse},
+		{"wrong", "good", ErrorInvalidInputSecret},
+	}
+
+	for _, tc := range testCases {
+		resp, err := Verify(context.TODO(), tc.secret, tc.response, "")
+		if (err != nil) || resp.Success || (len(resp.ErrorCodes) != 1) || (resp.ErrorCodes[0] != tc.code) {
+			t.Errorf("Unexpected response: %+v (%v), expected %v", resp, err, tc.code)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Package recaptcha mirrors APIs of popular Go reCAPTCHA libraries (github.com/dpapathanasiou/go-recaptcha and
+// github.com/ezzarghili/recaptcha-go) over Private Captcha, see package migrate
+package recaptcha
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PrivateCaptcha/private-captcha-go/migrate"
+)
+
+// Response is siteverify response
+type Response = migrate.Response
+
+var (
+	mu         sync.RWMutex
+	privateKey string
+)
+
+// Init sets the secret (Private Captcha API key) used by Confirm()
+func Init(key string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	privateKey = key
+}
+
+// Confirm verifies the response with the secret set by Init()
+func Confirm(remoteip, response string) (result bool, err error) {
+	mu.RLock()
+	key := privateKey
+	mu.RUnlock()
+
+	resp, err := migrate.Verify(context.Background(), key, response, remoteip)
+	return resp.Success, err
+}
+
+// Verify verifies the response with the secret
+func Verify(secret, response, remoteip string) (Response, error) {
+	return migrate.Verify(context.Background(), secret, response, remoteip)
+}
+
+// VERSION is reCAPTCHA version, it is accepted for compatibility and is ignored
+type VERSION int8
+
+const (
+	V2 VERSION = iota
+	V3
+)
+
+// ReCAPTCHA verifies responses with the secret
+type ReCAPTCHA struct {
+	Secret  string
+	Timeout time.Duration
+}
+
+// NewReCAPTCHA creates ReCAPTCHA with the secret, timeout limits every verification (including retries)
+func NewReCAPTCHA(secret string, version VERSION, timeout time.Duration) (ReCAPTCHA, error) {
+	if len(secret) == 0 {
+		return ReCAPTCHA{}, fmt.Errorf("recaptcha secret cannot be blank")
+	}
+
+	if _, err := migrate.Client(secret); err != nil {
+		return ReCAPTCHA{}, err
+	}
+
+	return ReCAPTCHA{Secret: secret, Timeout: timeout}, nil
+}
+
+// Verify returns nil if the response was verified successfully and an error with error codes otherwise
+func (r *ReCAPTCHA) Verify(challengeResponse string) error {
+	ctx := context.Background()
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	resp, err := migrate.Verify(ctx, r.Secret, challengeResponse, "")
+	if err != nil {
+		return err
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("remote error codes: %v", resp.ErrorCodes)
+	}
+
+	return nil
+}
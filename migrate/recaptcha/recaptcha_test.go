@@ -0,0 +1,51 @@
+package recaptcha
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+	"github.com/PrivateCaptcha/private-captcha-go/migrate"
+)
+
+func TestShims(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if solution, _ := io.ReadAll(r.Body); string(solution) != "good" {
+			w.Write([]byte(`{"success":false,"code":3}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	migrate.SetConfiguration(pc.Configuration{Domain: srv.URL, Client: srv.Client()})
+
+	Init("secret")
+	if ok, err := Confirm("192.0.2.1", "good"); !ok || (err != nil) {
+		t.Errorf("Unexpected result of Confirm: %v (%v)", ok, err)
+	}
+
+	if ok, err := Confirm("192.0.2.1", "bad"); ok || (err != nil) {
+		t.Errorf("Unexpected result of Confirm: %v (%v)", ok, err)
+	}
+
+	captcha, err := NewReCAPTCHA("secret", V2, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := captcha.Verify("good"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := captcha.Verify("bad"); err == nil {
+		t.Errorf("Expected verification error")
+	}
+
+	if _, err := NewReCAPTCHA("", V3, 0); err == nil {
+		t.Errorf("Expected error for blank secret")
+	}
+}
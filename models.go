@@ -1,66 +1,82 @@
 package privatecaptcha
 
-type VerifyCode int
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/PrivateCaptcha/private-captcha-go/codes"
+)
+
+// VerifyCode is the verification result code. See package codes for details.
+type VerifyCode = codes.Code
 
 const (
-	VerifyNoError           VerifyCode = 0
-	VerifyErrorOther        VerifyCode = 1
-	DuplicateSolutionsError VerifyCode = 2
-	InvalidSolutionError    VerifyCode = 3
-	ParseResponseError      VerifyCode = 4
-	PuzzleExpiredError      VerifyCode = 5
-	InvalidPropertyError    VerifyCode = 6
-	WrongOwnerError         VerifyCode = 7
-	VerifiedBeforeError     VerifyCode = 8
-	MaintenanceModeError    VerifyCode = 9
-	TestPropertyError       VerifyCode = 10
-	IntegrityError          VerifyCode = 11
-	OrgScopeError           VerifyCode = 12
-	// Add new fields _above_
-	VERIFY_CODES_COUNT
+	VerifyNoError           = codes.NoError
+	VerifyErrorOther        = codes.ErrorOther
+	DuplicateSolutionsError = codes.DuplicateSolutions
+	InvalidSolutionError    = codes.InvalidSolution
+	ParseResponseError      = codes.ParseResponse
+	PuzzleExpiredError      = codes.PuzzleExpired
+	InvalidPropertyError    = codes.InvalidProperty
+	WrongOwnerError         = codes.WrongOwner
+	VerifiedBeforeError     = codes.VerifiedBefore
+	MaintenanceModeError    = codes.MaintenanceMode
+	TestPropertyError       = codes.TestProperty
+	IntegrityError          = codes.Integrity
+	OrgScopeError           = codes.OrgScope
+	VERIFY_CODES_COUNT      = codes.Count
 )
 
-func (verr VerifyCode) String() string {
-	switch verr {
-	case VerifyNoError:
-		return ""
-	case VerifyErrorOther:
-		return "error-other"
-	case DuplicateSolutionsError:
-		return "solution-duplicates"
-	case InvalidSolutionError:
-		return "solution-invalid"
-	case ParseResponseError:
-		return "solution-bad-format"
-	case PuzzleExpiredError:
-		return "puzzle-expired"
-	case InvalidPropertyError:
-		return "property-invalid"
-	case WrongOwnerError:
-		return "property-owner-mismatch"
-	case VerifiedBeforeError:
-		return "solution-verified-before"
-	case MaintenanceModeError:
-		return "maintenance-mode"
-	case TestPropertyError:
-		return "property-test"
-	case IntegrityError:
-		return "integrity-error"
-	case OrgScopeError:
-		return "property-org-scope"
-	default:
-		return "error"
-	}
+type VerifyOutput struct {
+	Success     bool                       `json:"success"`
+	Code        VerifyCode                 `json:"code"`
+	Origin      string                     `json:"origin,omitempty"`
+	Timestamp   string                     `json:"timestamp,omitempty"`
+	requestID   string                     `json:"-"`
+	attempt     int                        `json:"-"`
+	attempts    int                        `json:"-"`
+	metadata    map[string]string          `json:"-"`
+	region      string                     `json:"-"`
+	signals     map[string]json.RawMessage `json:"-"`
+	bucket      string                     `json:"-"`
+	timings     *Timings                   `json:"-"`
+	override    PolicyOutcome              `json:"-"`
+	reason      string                     `json:"-"`
+	decisions   []Decision                 `json:"-"`
+	digest      string                     `json:"-"`
+	attestation string                     `json:"-"`
+	flipped     bool                       `json:"-"`
 }
 
-type VerifyOutput struct {
-	Success   bool              `json:"success"`
-	Code      VerifyCode        `json:"code"`
-	Origin    string            `json:"origin,omitempty"`
-	Timestamp string            `json:"timestamp,omitempty"`
-	requestID string            `json:"-"`
-	attempt   int               `json:"-"`
-	metadata  map[string]string `json:"-"`
+var knownOutputFields = map[string]struct{}{
+	"success":   {},
+	"code":      {},
+	"origin":    {},
+	"timestamp": {},
+}
+
+// parseSignals returns fields of the verify response that are not modelled by VerifyOutput
+func parseSignals(data []byte) map[string]json.RawMessage {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	for k := range fields {
+		if _, ok := knownOutputFields[k]; ok {
+			delete(fields, k)
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return fields
 }
 
 func (vr *VerifyOutput) OK() bool {
@@ -68,7 +84,115 @@ func (vr *VerifyOutput) OK() bool {
 		return false
 	}
 
-	return vr.Success && (vr.Code == VerifyNoError)
+	switch vr.override {
+	case PolicyAccept:
+		return true
+	case PolicyReject:
+		return false
+	default:
+		return vr.Success && (vr.Code == VerifyNoError)
+	}
+}
+
+// Flipped returns true if the reported outcome was flipped by Configuration.UnsafeChaos, see ReportedOK()
+func (vr *VerifyOutput) Flipped() bool {
+	if vr == nil {
+		return false
+	}
+
+	return vr.flipped
+}
+
+// ReportedOK returns the outcome of verification for statistics and monitoring: it equals OK(), unless it was
+// flipped by Configuration.UnsafeChaos. It must not be used for access decisions
+func (vr *VerifyOutput) ReportedOK() bool {
+	return vr.OK() != vr.Flipped()
+}
+
+func (vr *VerifyOutput) applyPolicy(r PolicyResult) {
+	vr.override = r.Outcome
+	vr.reason = r.Reason
+}
+
+// Decisions returns evaluations of Configuration.Policies in order, explaining why verification
+// was ultimately accepted or rejected
+func (vr *VerifyOutput) Decisions() []Decision {
+	if vr == nil {
+		return nil
+	}
+
+	return slices.Clone(vr.decisions)
+}
+
+// PolicyReason returns the reason of the last policy that overrode the outcome (empty if none did)
+func (vr *VerifyOutput) PolicyReason() string {
+	if vr == nil {
+		return ""
+	}
+
+	return vr.reason
+}
+
+// SolutionDigest returns a short stable hash of the verified solution (empty for VerifyInput.SolutionReader streamed
+// with DisableBodyBuffering), which can be logged or stored to correlate repeated and replayed solutions without keeping the solution itself
+func (vr *VerifyOutput) SolutionDigest() string {
+	if vr == nil {
+		return ""
+	}
+
+	return vr.digest
+}
+
+// Attestation returns signed JWT attesting successful verification (only if Configuration.Attestation is set),
+// which can be passed to downstream services and validated there with ParseAttestation()
+func (vr *VerifyOutput) Attestation() string {
+	if vr == nil {
+		return ""
+	}
+
+	return vr.attestation
+}
+
+// solutionDigest returns first 8 bytes of SHA-256 of the solution, hex-encoded
+func solutionDigest(solution string) string {
+	if len(solution) == 0 {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(solution))
+	return hex.EncodeToString(hash[:8])
+}
+
+// readerDigest returns solutionDigest() of the reader contents and rewinds it
+func readerDigest(rs io.ReadSeeker) (string, error) {
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(hash, rs)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	if n == 0 {
+		return "", nil
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)[:8]), nil
+}
+
+// Attempts returns the number of verify requests sent to the API to get this result
+func (vr *VerifyOutput) Attempts() int {
+	if vr == nil {
+		return 0
+	}
+
+	return vr.attempts
 }
 
 func (vr *VerifyOutput) RequestID() string {
@@ -79,14 +203,68 @@ func (vr *VerifyOutput) RequestID() string {
 	return vr.requestID
 }
 
+// Region returns the region of the API endpoint that served the verification
+func (vr *VerifyOutput) Region() string {
+	if vr == nil {
+		return ""
+	}
+
+	return vr.region
+}
+
+// Bucket returns experiment bucket the verification was assigned to (empty if not part of an Experiment)
+func (vr *VerifyOutput) Bucket() string {
+	if vr == nil {
+		return ""
+	}
+
+	return vr.bucket
+}
+
+// Timings returns per-phase timings of the last verify attempt (only if Configuration.TraceTimings is set)
+func (vr *VerifyOutput) Timings() (Timings, bool) {
+	if (vr == nil) || (vr.timings == nil) {
+		return Timings{}, false
+	}
+
+	return *vr.timings, true
+}
+
 func (vr *VerifyOutput) Error() string {
 	if vr == nil {
 		return ""
 	}
 
+	if (vr.override == PolicyReject) && (len(vr.reason) > 0) {
+		return vr.reason
+	}
+
 	return vr.Code.String()
 }
 
+// Signal returns raw JSON value of the response field not modelled by VerifyOutput (e.g. bot-detection signals)
+func (vr *VerifyOutput) Signal(key string) json.RawMessage {
+	if (vr == nil) || (vr.signals == nil) {
+		return nil
+	}
+
+	return vr.signals[key]
+}
+
+// Signals returns names of all response fields not modelled by VerifyOutput
+func (vr *VerifyOutput) Signals() []string {
+	if vr == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(vr.signals))
+	for k := range vr.signals {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
 func (vr *VerifyOutput) Metadata(key string) string {
 	if (vr == nil) || (vr.metadata == nil) {
 		return ""
@@ -98,3 +276,101 @@ func (vr *VerifyOutput) Metadata(key string) string {
 
 	return ""
 }
+
+const outputFormatVersion = 1
+
+var (
+	errOutputFormat = errors.New("privatecaptcha: unsupported serialized output format")
+	errNilOutput    = errors.New("privatecaptcha: output is nil")
+)
+
+// verifyOutputData is a compact serialized form of VerifyOutput, including private fields
+type verifyOutputData struct {
+	Success     bool                       `json:"s"`
+	Code        VerifyCode                 `json:"c"`
+	Origin      string                     `json:"o,omitempty"`
+	Timestamp   string                     `json:"t,omitempty"`
+	RequestID   string                     `json:"r,omitempty"`
+	Attempt     int                        `json:"a,omitempty"`
+	Attempts    int                        `json:"n,omitempty"`
+	Metadata    map[string]string          `json:"m,omitempty"`
+	Region      string                     `json:"g,omitempty"`
+	Signals     map[string]json.RawMessage `json:"x,omitempty"`
+	Bucket      string                     `json:"b,omitempty"`
+	Override    PolicyOutcome              `json:"p,omitempty"`
+	Reason      string                     `json:"pr,omitempty"`
+	Decisions   []Decision                 `json:"d,omitempty"`
+	Digest      string                     `json:"sd,omitempty"`
+	Attestation string                     `json:"at,omitempty"`
+	Timings     *Timings                   `json:"tm,omitempty"`
+	Flipped     bool                       `json:"f,omitempty"`
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler so that verification result can be persisted
+// (e.g. in session or cookie store) and loaded later for audit or multi-step flows
+func (vr *VerifyOutput) MarshalBinary() ([]byte, error) {
+	if vr == nil {
+		return nil, errNilOutput
+	}
+
+	data, err := json.Marshal(&verifyOutputData{
+		Success:     vr.Success,
+		Code:        vr.Code,
+		Origin:      vr.Origin,
+		Timestamp:   vr.Timestamp,
+		RequestID:   vr.requestID,
+		Attempt:     vr.attempt,
+		Attempts:    vr.attempts,
+		Metadata:    vr.metadata,
+		Region:      vr.region,
+		Signals:     vr.signals,
+		Bucket:      vr.bucket,
+		Override:    vr.override,
+		Reason:      vr.reason,
+		Decisions:   vr.decisions,
+		Digest:      vr.digest,
+		Attestation: vr.attestation,
+		Timings:     vr.timings,
+		Flipped:     vr.flipped,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{outputFormatVersion}, data...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func (vr *VerifyOutput) UnmarshalBinary(data []byte) error {
+	if (len(data) == 0) || (data[0] != outputFormatVersion) {
+		return errOutputFormat
+	}
+
+	var d verifyOutputData
+	if err := json.Unmarshal(data[1:], &d); err != nil {
+		return err
+	}
+
+	*vr = VerifyOutput{
+		Success:     d.Success,
+		Code:        d.Code,
+		Origin:      d.Origin,
+		Timestamp:   d.Timestamp,
+		requestID:   d.RequestID,
+		attempt:     d.Attempt,
+		attempts:    d.Attempts,
+		metadata:    d.Metadata,
+		region:      d.Region,
+		signals:     d.Signals,
+		bucket:      d.Bucket,
+		override:    d.Override,
+		reason:      d.Reason,
+		decisions:   d.Decisions,
+		digest:      d.Digest,
+		attestation: d.Attestation,
+		timings:     d.Timings,
+		flipped:     d.Flipped,
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package privatecaptcha
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVerifyOutputBinary(t *testing.T) {
+	t.Parallel()
+
+	output := &VerifyOutput{
+		Success:   true,
+		Code:      TestPropertyError,
+		Origin:    "example.com",
+		Timestamp: "2025-01-01T00:00:00Z",
+		requestID: "trace-id",
+		attempt:   2,
+		metadata:  map[string]string{"X-Header": "value"},
+		region:    RegionEU,
+		signals:   map[string]json.RawMessage{"score": json.RawMessage("0.9")},
+		override:  PolicyReject,
+		reason:    "origin-not-allowed",
+		decisions: []Decision{{Policy: "origin", Before: true, After: false, Outcome: PolicyReject, Reason: "origin-not-allowed"}},
+		digest:    solutionDigest("solution"),
+		attempts:  3,
+		timings:   &Timings{DNS: time.Millisecond, TTFB: 20 * time.Millisecond},
+		flipped:   true,
+	}
+
+	// gob uses encoding.BinaryMarshaler, as most session stores do
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(output); err != nil {
+		t.Fatal(err)
+	}
+
+	var loaded VerifyOutput
+	if err := gob.NewDecoder(&buf).Decode(&loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(output, &loaded) {
+		t.Errorf("Loaded output differs: %+v", loaded)
+	}
+
+	if (loaded.ReportedOK() != output.ReportedOK()) || (loaded.Attempts() != 3) {
+		t.Errorf("Unexpected accessors of loaded output: %v %v", loaded.ReportedOK(), loaded.Attempts())
+	}
+
+	if _, err := (*VerifyOutput)(nil).MarshalBinary(); err != errNilOutput {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := loaded.UnmarshalBinary([]byte(`{}`)); err != errOutputFormat {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func FuzzDecodeVerifyOutput(f *testing.F) {
+	f.Add([]byte(`{"success":true,"code":0,"origin":"example.com","timestamp":"2024-01-01T00:00:00Z"}`))
+	f.Add([]byte(`{"success":false,"code":3,"score":0.5,"tags":["a"]}`))
+	f.Add([]byte(`{"code":99999999999999999999}`))
+	f.Add([]byte("\x01{\"s\":true}"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		output := &VerifyOutput{}
+		if err := json.Unmarshal(data, output); err == nil {
+			output.signals = parseSignals(data)
+			_ = output.Error()
+			_ = output.OK()
+			_ = output.Signals()
+			_ = output.Signal("score")
+		}
+
+		restored := &VerifyOutput{}
+		if err := restored.UnmarshalBinary(data); err == nil {
+			if _, err := restored.MarshalBinary(); err != nil {
+				t.Errorf("Failed to marshal restored output: %v", err)
+			}
+		}
+	})
+}
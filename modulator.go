@@ -0,0 +1,49 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ModulationInfo is request metadata passed to PolicyModulator
+type ModulationInfo struct {
+	Request *http.Request
+	// IP is the client IP address, see Client.RealIP()
+	IP string
+	// Country is the country code of the client IP provided by upstream (see MiddlewareOptions.CountryHeader),
+	// empty if unknown
+	Country string
+	// Time is the time the request was received
+	Time time.Time
+}
+
+// PolicyModulator tightens or relaxes enforcement of Middleware per request at runtime, e.g. switches to monitor-only
+// during an incident or enforces verification for some countries at night. ActionEnforce rejects failed requests
+// even when FailurePolicy is FailureMonitor, ActionMonitor passes them through and ActionSkip does not verify
+// requests at all. Empty action keeps the configured behavior.
+type PolicyModulator func(ctx context.Context, info ModulationInfo) RuleAction
+
+// modulate applies MiddlewareOptions.Modulator to the request context, it returns false if verification is skipped
+func (c *Client) modulate(ctx context.Context, r *http.Request, opts *MiddlewareOptions, now time.Time) (context.Context, bool) {
+	info := ModulationInfo{Request: r, IP: c.RealIP(r), Time: now}
+	if len(opts.CountryHeader) > 0 {
+		info.Country = r.Header.Get(opts.CountryHeader)
+	}
+
+	action := opts.Modulator(ctx, info)
+	if len(action) > 0 {
+		c.logger.Log(ctx, levelTrace, "Modulated verification policy", "action", string(action), "country", info.Country)
+	}
+
+	switch action {
+	case ActionSkip:
+		return ctx, false
+	case ActionMonitor:
+		return WithFailurePolicy(ctx, FailureMonitor), true
+	case ActionEnforce:
+		return WithFailurePolicy(ctx, FailureReject), true
+	default:
+		return ctx, true
+	}
+}
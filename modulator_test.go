@@ -0,0 +1,64 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPolicyModulator(t *testing.T) {
+	t.Parallel()
+
+	var verifications atomic.Int32
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		verifications.Add(1)
+		w.Write([]byte(`{"success":false,"code":3}`))
+	}, Configuration{TrustedProxies: []string{"192.0.2.0/24"}})
+
+	actions := map[string]RuleAction{"DE": ActionEnforce, "FR": ActionMonitor, "NL": ActionSkip}
+
+	handler := client.Middleware(MiddlewareOptions{
+		FailurePolicy: FailureMonitor,
+		CountryHeader: "CF-IPCountry",
+		Modulator: func(ctx context.Context, info ModulationInfo) RuleAction {
+			if (info.IP != "198.51.100.1") || info.Time.IsZero() {
+				t.Errorf("Unexpected modulation info: %+v", info)
+			}
+			return actions[info.Country]
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		country string
+		status  int
+	}{
+		{"DE", http.StatusForbidden},
+		{"FR", http.StatusOK},
+		{"NL", http.StatusOK},
+		// configured FailureMonitor is kept
+		{"", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(DefaultFormField+"=asdf"))
+		req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+		req.Header.Set("X-Forwarded-For", "198.51.100.1")
+		req.Header.Set("CF-IPCountry", tc.country)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tc.status {
+			t.Errorf("Unexpected status for %q: %v (expected %v)", tc.country, w.Code, tc.status)
+		}
+	}
+
+	// skipped request is not verified
+	if verifications.Load() != 3 {
+		t.Errorf("Unexpected verifications: %v", verifications.Load())
+	}
+}
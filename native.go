@@ -0,0 +1,79 @@
+package privatecaptcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// NativeSolutionHeader is the request header native apps can use to send the solution
+	NativeSolutionHeader = "X-Captcha-Solution"
+	// NativeDeviceHeader is the request header native apps can use to send the device identifier
+	NativeDeviceHeader = "X-Device-ID"
+	// HintRemoteIP is the client hint key used to forward client IP address
+	HintRemoteIP = "remote_ip"
+	// HintDeviceID is the client hint key used to forward device identifier
+	HintDeviceID = "device_id"
+	// maxNativeBodySize limits JSON body read by ParseNativeRequest
+	maxNativeBodySize = 64 * 1024
+)
+
+var (
+	errNativeContentType = errors.New("privatecaptcha: unsupported content type of native request")
+)
+
+// NativeInput is the input for verifications of solutions coming from native (mobile) apps,
+// where there are no forms and browser Origin
+type NativeInput struct {
+	Solution string `json:"solution"`
+	DeviceID string `json:"device_id,omitempty"`
+	// RemoteIP is the IP address of the app (not read from JSON)
+	RemoteIP string `json:"-"`
+	Sitekey  string `json:"-"`
+}
+
+// ParseNativeRequest reads NativeInput from the request sent by a native app: either from NativeSolutionHeader
+// and NativeDeviceHeader headers or from JSON body ({"solution": "...", "device_id": "..."}).
+// RemoteIP is populated from the connection address (use Client.ParseNativeRequest() behind reverse proxies).
+func ParseNativeRequest(r *http.Request) (NativeInput, error) {
+	var input NativeInput
+
+	if solution := r.Header.Get(NativeSolutionHeader); len(solution) > 0 {
+		input.Solution = solution
+		input.DeviceID = r.Header.Get(NativeDeviceHeader)
+	} else {
+		if ct := r.Header.Get(headerContentType); !strings.HasPrefix(ct, "application/json") {
+			return input, errNativeContentType
+		}
+
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxNativeBodySize)).Decode(&input); err != nil {
+			return input, err
+		}
+	}
+
+	input.RemoteIP = remoteAddr(r)
+
+	return input, nil
+}
+
+// VerifyNative verifies solution sent by a native app, forwarding remote IP and device identifier
+// to the API as client hints for binding
+func (c *Client) VerifyNative(ctx context.Context, input NativeInput) (*VerifyOutput, error) {
+	hints := make(map[string]string)
+	if len(input.RemoteIP) > 0 {
+		hints[HintRemoteIP] = input.RemoteIP
+	}
+	if len(input.DeviceID) > 0 {
+		hints[HintDeviceID] = input.DeviceID
+	}
+
+	return c.Verify(ctx, VerifyInput{
+		Solution:    input.Solution,
+		Sitekey:     input.Sitekey,
+		ClientHints: hints,
+	})
+}
@@ -0,0 +1,71 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestVerifyNative(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hints, _ := url.ParseQuery(r.Header.Get(headerClientHints))
+		if (hints.Get(HintRemoteIP) != "192.0.2.1") || (hints.Get(HintDeviceID) != "device-1") {
+			t.Errorf("Unexpected client hints: %v", hints)
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{})
+
+	jsonReq := httptest.NewRequest(http.MethodPost, "/native", strings.NewReader(`{"solution":"asdf","device_id":"device-1"}`))
+	jsonReq.Header.Set(headerContentType, "application/json")
+
+	headerReq := httptest.NewRequest(http.MethodPost, "/native", nil)
+	headerReq.Header.Set(NativeSolutionHeader, "asdf")
+	headerReq.Header.Set(NativeDeviceHeader, "device-1")
+
+	for _, req := range []*http.Request{jsonReq, headerReq} {
+		input, err := ParseNativeRequest(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if (input.Solution != "asdf") || (input.RemoteIP != "192.0.2.1") {
+			t.Errorf("Unexpected input: %+v", input)
+		}
+
+		output, err := client.VerifyNative(context.TODO(), input)
+		if err != nil || !output.OK() {
+			t.Errorf("Unexpected result: %v (%v)", output.Error(), err)
+		}
+	}
+
+	if _, err := ParseNativeRequest(httptest.NewRequest(http.MethodPost, "/native", nil)); err != errNativeContentType {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestParseNativeRequestTrustedProxy(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(Configuration{APIKey: "test-api-key", TrustedProxies: []string{"192.0.2.0/24"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/native", nil)
+	req.Header.Set(NativeSolutionHeader, "asdf")
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+
+	input, err := client.ParseNativeRequest(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if input.RemoteIP != "198.51.100.1" {
+		t.Errorf("Unexpected remote IP: %v", input.RemoteIP)
+	}
+}
@@ -0,0 +1,185 @@
+package privatecaptcha
+
+import (
+	"strconv"
+)
+
+const (
+	openAPISolutionDescription = "Private Captcha solution"
+)
+
+// OpenAPISchema is a subset of OpenAPI 3 Schema Object
+type OpenAPISchema struct {
+	Type        string                    `json:"type"`
+	Description string                    `json:"description,omitempty"`
+	MaxLength   int                       `json:"maxLength,omitempty"`
+	Properties  map[string]*OpenAPISchema `json:"properties,omitempty"`
+}
+
+// OpenAPIParameter is a subset of OpenAPI 3 Parameter Object
+type OpenAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIMediaType is a subset of OpenAPI 3 Media Type Object
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema,omitempty"`
+}
+
+// OpenAPIRequestBody is a subset of OpenAPI 3 Request Body Object
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse is a subset of OpenAPI 3 Response Object
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIOperation contains parts of OpenAPI 3 Operation Object describing captcha requirements of an endpoint
+// protected with Client.Middleware(). It can be marshaled to JSON (or YAML) and merged into generated API docs.
+type OpenAPIOperation struct {
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+// flattenExtractors returns built-in extractors in the order they are tried (custom extractors are skipped)
+func flattenExtractors(extractor Extractor) []sourceExtractor {
+	switch e := extractor.(type) {
+	case sourceExtractor:
+		return []sourceExtractor{e}
+	case Extractors:
+		var result []sourceExtractor
+		for _, child := range e {
+			result = append(result, flattenExtractors(child)...)
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// setSchemaPath adds leaf schema to the object schema by dot-separated path, creating nested objects
+func setSchemaPath(root *OpenAPISchema, path string, leaf *OpenAPISchema) {
+	parts := splitJSONPath(path)
+	for _, part := range parts[:len(parts)-1] {
+		if root.Properties == nil {
+			root.Properties = make(map[string]*OpenAPISchema)
+		}
+
+		child, ok := root.Properties[part]
+		if !ok || (child.Type != "object") {
+			child = &OpenAPISchema{Type: "object"}
+			root.Properties[part] = child
+		}
+		root = child
+	}
+
+	if root.Properties == nil {
+		root.Properties = make(map[string]*OpenAPISchema)
+	}
+	root.Properties[parts[len(parts)-1]] = leaf
+}
+
+func addResponse(responses map[string]*OpenAPIResponse, status int, contentType string, schema *OpenAPISchema) {
+	key := strconv.Itoa(status)
+	response, ok := responses[key]
+	if !ok {
+		response = &OpenAPIResponse{Description: "Captcha verification failed", Content: make(map[string]*OpenAPIMediaType)}
+		responses[key] = response
+	}
+
+	response.Content[contentType] = &OpenAPIMediaType{Schema: schema}
+}
+
+// OpenAPI describes captcha requirements of endpoints protected with Middleware(opts) as parts of OpenAPI operation:
+// where the solution is read from and which failure responses can be returned. Custom extractors (not created with
+// FromForm, FromHeader, FromCookie, FromQuery or FromJSON) are not described.
+func (c *Client) OpenAPI(opts MiddlewareOptions) *OpenAPIOperation {
+	c.middlewareDefaults(&opts)
+
+	extractor := opts.Extractor
+	if extractor == nil {
+		extractor = c.extractor
+	}
+	if extractor == nil {
+		extractor = c.formExtractor(FromForm)
+	}
+
+	sources := flattenExtractors(extractor)
+	// solution is required only if there's no alternative way to pass verification
+	required := (len(sources) == 1) && (opts.GraceCookie == nil)
+
+	operation := &OpenAPIOperation{Responses: make(map[string]*OpenAPIResponse)}
+
+	var formSchema, jsonSchema *OpenAPISchema
+	for _, source := range sources {
+		solution := &OpenAPISchema{Type: "string", Description: openAPISolutionDescription, MaxLength: c.maxSolutionLen}
+
+		switch source.source {
+		case sourceForm:
+			if formSchema == nil {
+				formSchema = &OpenAPISchema{Type: "object"}
+			}
+			setSchemaPath(formSchema, source.name, solution)
+		case sourceJSON:
+			if jsonSchema == nil {
+				jsonSchema = &OpenAPISchema{Type: "object"}
+			}
+			setSchemaPath(jsonSchema, source.name, solution)
+		default:
+			operation.Parameters = append(operation.Parameters, &OpenAPIParameter{
+				Name:        source.name,
+				In:          source.source,
+				Description: openAPISolutionDescription,
+				Required:    required,
+				Schema:      solution,
+			})
+		}
+	}
+
+	if (formSchema != nil) || (jsonSchema != nil) {
+		operation.RequestBody = &OpenAPIRequestBody{Required: required, Content: make(map[string]*OpenAPIMediaType)}
+		if formSchema != nil {
+			operation.RequestBody.Content["application/x-www-form-urlencoded"] = &OpenAPIMediaType{Schema: formSchema}
+			operation.RequestBody.Content["multipart/form-data"] = &OpenAPIMediaType{Schema: formSchema}
+		}
+		if jsonSchema != nil {
+			operation.RequestBody.Content["application/json"] = &OpenAPIMediaType{Schema: jsonSchema}
+		}
+	}
+
+	if opts.GraceCookie != nil {
+		operation.Parameters = append(operation.Parameters, &OpenAPIParameter{
+			Name:        opts.GraceCookie.name(),
+			In:          sourceCookie,
+			Description: "Signed grace period cookie, accepted instead of a new captcha solution",
+			Schema:      &OpenAPISchema{Type: "string"},
+		})
+	}
+
+	addResponse(operation.Responses, opts.FailedStatusCode, "text/plain", &OpenAPISchema{Type: "string"})
+
+	if !opts.DisableAsyncDetection {
+		addResponse(operation.Responses, opts.AsyncFailedStatusCode, "application/json", &OpenAPISchema{
+			Type: "object",
+			Properties: map[string]*OpenAPISchema{
+				"success": {Type: "boolean"},
+				"error":   {Type: "string"},
+			},
+		})
+
+		if len(opts.HTMXFailureHTML) > 0 {
+			addResponse(operation.Responses, opts.AsyncFailedStatusCode, "text/html", &OpenAPISchema{Type: "string"})
+		}
+	}
+
+	return operation
+}
@@ -0,0 +1,62 @@
+package privatecaptcha
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIDefaultForm(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(Configuration{APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operation := client.OpenAPI(MiddlewareOptions{})
+
+	if (operation.RequestBody == nil) || !operation.RequestBody.Required {
+		t.Fatal("Request body should be required")
+	}
+
+	form := operation.RequestBody.Content["application/x-www-form-urlencoded"]
+	if (form == nil) || (form.Schema.Properties[DefaultFormField] == nil) {
+		t.Errorf("Form field is not described")
+	}
+
+	if (operation.Responses["403"] == nil) || (operation.Responses["422"] == nil) {
+		t.Errorf("Unexpected responses: %v", operation.Responses)
+	}
+}
+
+func TestOpenAPIExtractors(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(Configuration{APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operation := client.OpenAPI(MiddlewareOptions{
+		Extractor:             Extractors{FromHeader("X-Captcha"), FromJSON("captcha.solution"), ExtractorFunc(nil)},
+		DisableAsyncDetection: true,
+	})
+
+	if (len(operation.Parameters) != 1) || (operation.Parameters[0].In != "header") || operation.Parameters[0].Required {
+		t.Errorf("Unexpected parameters: %+v", operation.Parameters)
+	}
+
+	data, err := json.Marshal(operation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `"application/json":{"schema":{"type":"object","properties":{"captcha":{"type":"object","properties":{"solution":`) {
+		t.Errorf("JSON path is not described: %s", data)
+	}
+
+	if len(operation.Responses) != 1 {
+		t.Errorf("Unexpected responses: %s", data)
+	}
+}
@@ -0,0 +1,91 @@
+package privatecaptcha
+
+import (
+	"net/http"
+)
+
+// Option configures the client created with New(). Options modify Configuration, so any of its fields can be set
+// with a custom Option if there's no dedicated one.
+type Option func(cfg *Configuration)
+
+// New creates a new instance of Private Captcha API client from options, e.g.
+// New(WithAPIKey(key), WithDomain("api.eu.privatecaptcha.com")). It is equivalent to NewClient() with the
+// Configuration produced by the options, applied in order.
+func New(opts ...Option) (*Client, error) {
+	var cfg Configuration
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return NewClient(cfg)
+}
+
+// WithConfiguration uses cfg as the base configuration, following options override its fields
+func WithConfiguration(cfg Configuration) Option {
+	return func(c *Configuration) {
+		*c = cfg
+	}
+}
+
+// WithAPIKey sets Configuration.APIKey
+func WithAPIKey(apiKey string) Option {
+	return func(cfg *Configuration) {
+		cfg.APIKey = apiKey
+	}
+}
+
+// WithDomain sets Configuration.Domain
+func WithDomain(domain string) Option {
+	return func(cfg *Configuration) {
+		cfg.Domain = domain
+	}
+}
+
+// WithVerifyPath sets Configuration.VerifyPath
+func WithVerifyPath(path string) Option {
+	return func(cfg *Configuration) {
+		cfg.VerifyPath = path
+	}
+}
+
+// WithHTTPClient sets Configuration.Client
+func WithHTTPClient(client *http.Client) Option {
+	return func(cfg *Configuration) {
+		cfg.Client = client
+	}
+}
+
+// WithDoer sets Configuration.Doer
+func WithDoer(doer Doer) Option {
+	return func(cfg *Configuration) {
+		cfg.Doer = doer
+	}
+}
+
+// WithFormField sets Configuration.FormField
+func WithFormField(field string) Option {
+	return func(cfg *Configuration) {
+		cfg.FormField = field
+	}
+}
+
+// WithFailedStatusCode sets Configuration.FailedStatusCode
+func WithFailedStatusCode(code int) Option {
+	return func(cfg *Configuration) {
+		cfg.FailedStatusCode = code
+	}
+}
+
+// WithFallback sets Configuration.Fallback
+func WithFallback(fallback Provider) Option {
+	return func(cfg *Configuration) {
+		cfg.Fallback = fallback
+	}
+}
+
+// WithPolicies appends policies to Configuration.Policies
+func WithPolicies(policies ...Policy) Option {
+	return func(cfg *Configuration) {
+		cfg.Policies = append(cfg.Policies, policies...)
+	}
+}
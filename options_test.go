@@ -0,0 +1,48 @@
+package privatecaptcha
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	t.Parallel()
+
+	if _, err := New(); err != errEmptyAPIKey {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	client, err := New(
+		WithConfiguration(Configuration{APIKey: "base-api-key", FormField: "base-field"}),
+		WithAPIKey("test-api-key"),
+		WithDomain("https://api.example.com/"),
+		WithFailedStatusCode(http.StatusTeapot),
+		WithPolicies(OriginPolicy("example.com")),
+		WithPolicies(MaxAgePolicy(0)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (client.apiKey != "test-api-key") || (client.formField != "base-field") || (client.failedStatusCode != http.StatusTeapot) {
+		t.Errorf("Unexpected client configuration: %v %v %v", client.apiKey, client.formField, client.failedStatusCode)
+	}
+
+	if endpoint := client.Endpoint(); endpoint != "https://api.example.com/verify" {
+		t.Errorf("Unexpected endpoint: %v", endpoint)
+	}
+
+	if len(client.policies) != 2 {
+		t.Errorf("Unexpected policies: %v", len(client.policies))
+	}
+
+	// custom options can set any field
+	client, err = New(WithAPIKey("test-api-key"), func(cfg *Configuration) { cfg.Region = "eu" })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.region != "eu" {
+		t.Errorf("Unexpected region: %v", client.region)
+	}
+}
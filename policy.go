@@ -0,0 +1,188 @@
+package privatecaptcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	errPolicyOutcome = errors.New("privatecaptcha: unknown policy outcome")
+)
+
+// PolicyOutcome is a decision of the Policy about verification result
+type PolicyOutcome int
+
+const (
+	// PolicyKeep leaves the current outcome unchanged
+	PolicyKeep PolicyOutcome = iota
+	// PolicyAccept overrides the outcome to success (e.g. for allowlisted users)
+	PolicyAccept
+	// PolicyReject overrides the outcome to failure
+	PolicyReject
+)
+
+func (o PolicyOutcome) String() string {
+	switch o {
+	case PolicyAccept:
+		return "accept"
+	case PolicyReject:
+		return "reject"
+	default:
+		return "keep"
+	}
+}
+
+func (o PolicyOutcome) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o *PolicyOutcome) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "accept":
+		*o = PolicyAccept
+	case "reject":
+		*o = PolicyReject
+	case "keep":
+		*o = PolicyKeep
+	default:
+		return errPolicyOutcome
+	}
+
+	return nil
+}
+
+// PolicyResult is the result of a single policy evaluation
+type PolicyResult struct {
+	Outcome PolicyOutcome
+	// Reason explains the outcome (e.g. "origin-mismatch"), it is reported by VerifyOutput.Error() for rejections
+	Reason string
+}
+
+// Policy post-processes verification result before the final decision (VerifyOutput.OK()). Policies are applied
+// in order to every successful Verify() call (without error) and each of them can override the outcome.
+type Policy interface {
+	Evaluate(ctx context.Context, output *VerifyOutput) PolicyResult
+}
+
+// PolicyFunc is an adapter to use ordinary functions as Policy
+type PolicyFunc func(ctx context.Context, output *VerifyOutput) PolicyResult
+
+func (f PolicyFunc) Evaluate(ctx context.Context, output *VerifyOutput) PolicyResult {
+	return f(ctx, output)
+}
+
+// Decision records evaluation of a single policy, see VerifyOutput.Decisions()
+type Decision struct {
+	// Policy is the name of the policy (see NamedPolicy) or its position in the chain (e.g. "#2")
+	Policy string `json:"policy"`
+	// Before is the outcome (VerifyOutput.OK()) before the policy was evaluated
+	Before bool `json:"before"`
+	// After is the outcome (VerifyOutput.OK()) after the policy was evaluated
+	After   bool          `json:"after"`
+	Outcome PolicyOutcome `json:"outcome"`
+	Reason  string        `json:"reason,omitempty"`
+}
+
+type namedPolicy struct {
+	Policy
+	name string
+}
+
+// NamedPolicy gives the policy a name, which is recorded in decisions of verification results
+func NamedPolicy(name string, policy Policy) Policy {
+	return namedPolicy{Policy: policy, name: name}
+}
+
+// Policies is a chain of policies evaluated in order, the last override wins. Evaluation of every policy
+// is recorded in VerifyOutput.Decisions().
+type Policies []Policy
+
+func (p Policies) Evaluate(ctx context.Context, output *VerifyOutput) PolicyResult {
+	var result PolicyResult
+
+	for i, policy := range p {
+		name := fmt.Sprintf("#%d", i)
+		if named, ok := policy.(namedPolicy); ok {
+			name = named.name
+		}
+
+		before := output.OK()
+		r := policy.Evaluate(ctx, output)
+		if r.Outcome != PolicyKeep {
+			output.applyPolicy(r)
+			result = r
+		}
+
+		output.decisions = append(output.decisions, Decision{
+			Policy:  name,
+			Before:  before,
+			After:   output.OK(),
+			Outcome: r.Outcome,
+			Reason:  r.Reason,
+		})
+	}
+
+	return result
+}
+
+// OriginPolicy rejects successful verifications of solutions obtained on origins other than allowed
+func OriginPolicy(origins ...string) Policy {
+	return NamedPolicy("origin", PolicyFunc(func(ctx context.Context, output *VerifyOutput) PolicyResult {
+		if !output.OK() || (len(output.Origin) == 0) {
+			return PolicyResult{}
+		}
+
+		for _, origin := range origins {
+			if sameOrigin(origin, output.Origin) {
+				return PolicyResult{}
+			}
+		}
+
+		return PolicyResult{Outcome: PolicyReject, Reason: "origin-not-allowed"}
+	}))
+}
+
+// MaxAgePolicy rejects successful verifications of solutions older than maxAge (by VerifyOutput.Timestamp)
+func MaxAgePolicy(maxAge time.Duration) Policy {
+	return NamedPolicy("max-age", PolicyFunc(func(ctx context.Context, output *VerifyOutput) PolicyResult {
+		if !output.OK() || (len(output.Timestamp) == 0) {
+			return PolicyResult{}
+		}
+
+		timestamp, err := time.Parse(time.RFC3339, output.Timestamp)
+		if err != nil {
+			return PolicyResult{Outcome: PolicyReject, Reason: "timestamp-invalid"}
+		}
+
+		if time.Since(timestamp) > maxAge {
+			return PolicyResult{Outcome: PolicyReject, Reason: "solution-too-old"}
+		}
+
+		return PolicyResult{}
+	}))
+}
+
+// ScoreThresholdPolicy rejects successful verifications with numeric signal (see VerifyOutput.Signal) below threshold.
+// Verifications without the signal are not affected.
+func ScoreThresholdPolicy(signal string, threshold float64) Policy {
+	return NamedPolicy("score-threshold", PolicyFunc(func(ctx context.Context, output *VerifyOutput) PolicyResult {
+		raw := output.Signal(signal)
+		if !output.OK() || (raw == nil) {
+			return PolicyResult{}
+		}
+
+		var score float64
+		if err := json.Unmarshal(raw, &score); err != nil {
+			return PolicyResult{Outcome: PolicyReject, Reason: fmt.Sprintf("%s-invalid", signal)}
+		}
+
+		if score < threshold {
+			return PolicyResult{Outcome: PolicyReject, Reason: fmt.Sprintf("%s-below-threshold", signal)}
+		}
+
+		return PolicyResult{}
+	}))
+}
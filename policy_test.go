@@ -0,0 +1,95 @@
+package privatecaptcha
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPolicies(t *testing.T) {
+	t.Parallel()
+
+	fresh := time.Now().UTC().Format(time.RFC3339)
+	stale := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	testCases := []struct {
+		output *VerifyOutput
+		ok     bool
+		reason string
+	}{
+		{&VerifyOutput{Success: true, Origin: "example.com", Timestamp: fresh}, true, ""},
+		{&VerifyOutput{Success: true, Origin: "evil.com", Timestamp: fresh}, false, "origin-not-allowed"},
+		{&VerifyOutput{Success: true, Origin: "example.com", Timestamp: stale}, false, "solution-too-old"},
+		{&VerifyOutput{Success: true, signals: map[string]json.RawMessage{"score": json.RawMessage("0.2")}}, false, "score-below-threshold"},
+		{&VerifyOutput{Success: true, signals: map[string]json.RawMessage{"score": json.RawMessage("0.9")}}, true, ""},
+		{&VerifyOutput{Success: false, Code: InvalidSolutionError, Origin: "evil.com"}, false, ""},
+	}
+
+	policies := Policies{
+		OriginPolicy("https://example.com"),
+		MaxAgePolicy(time.Minute),
+		ScoreThresholdPolicy("score", 0.5),
+	}
+
+	for i, tc := range testCases {
+		policies.Evaluate(context.TODO(), tc.output)
+
+		if (tc.output.OK() != tc.ok) || (tc.output.PolicyReason() != tc.reason) {
+			t.Errorf("Unexpected result of test case %v: ok=%v reason=%v", i, tc.output.OK(), tc.output.PolicyReason())
+		}
+
+		if (len(tc.reason) > 0) && (tc.output.Error() != tc.reason) {
+			t.Errorf("Unexpected error of test case %v: %v", i, tc.output.Error())
+		}
+	}
+}
+
+func TestClientPolicies(t *testing.T) {
+	t.Parallel()
+
+	allowlist := PolicyFunc(func(ctx context.Context, output *VerifyOutput) PolicyResult {
+		if output.Metadata("X-User") == "admin" {
+			return PolicyResult{Outcome: PolicyAccept, Reason: "allowlisted"}
+		}
+		return PolicyResult{}
+	})
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-User", "admin")
+		w.Write([]byte(`{"success":false,"code":3}`))
+	}, Configuration{Policies: Policies{allowlist}})
+
+	output, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Headers: []string{"X-User"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !output.OK() || (output.PolicyReason() != "allowlisted") {
+		t.Errorf("Unexpected result: ok=%v reason=%v", output.OK(), output.PolicyReason())
+	}
+
+	decisions := output.Decisions()
+	if (len(decisions) != 1) || (decisions[0].Policy != "#0") || decisions[0].Before || !decisions[0].After {
+		t.Errorf("Unexpected decisions: %+v", decisions)
+	}
+}
+
+func TestPolicyDecisions(t *testing.T) {
+	t.Parallel()
+
+	output := &VerifyOutput{Success: true, Origin: "evil.com"}
+	Policies{OriginPolicy("example.com"), MaxAgePolicy(time.Minute)}.Evaluate(context.TODO(), output)
+
+	data, err := json.Marshal(output.Decisions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[{"policy":"origin","before":true,"after":false,"outcome":"reject","reason":"origin-not-allowed"},` +
+		`{"policy":"max-age","before":false,"after":false,"outcome":"keep"}]`
+	if string(data) != expected {
+		t.Errorf("Unexpected decisions: %s", data)
+	}
+}
@@ -0,0 +1,93 @@
+package privatecaptchatest
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+
+	privatecaptcha "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+// ConformanceOptions configures RunConformance
+type ConformanceOptions struct {
+	// (optional) Sitekey of the test property (defaults to TestSitekey, which is the test property of the hosted API)
+	Sitekey string
+	// (optional) Origin of puzzle requests, allowed in property settings (defaults to Configuration.Origin of the
+	// client or "not.empty")
+	Origin string
+}
+
+// RunConformance runs the suite of subtests checking that the API server (e.g. self-hosted deployment after
+// upgrade) is compatible with this release of SDK. It talks to the real server configured for the client and
+// requires a test property, solutions of which are accepted with codes.TestProperty without proof-of-work.
+func RunConformance(t *testing.T, client *privatecaptcha.Client, opts ConformanceOptions) {
+	t.Helper()
+
+	if len(opts.Sitekey) == 0 {
+		opts.Sitekey = TestSitekey
+	}
+
+	if len(opts.Origin) == 0 {
+		opts.Origin = "not.empty"
+	}
+
+	ctx := context.Background()
+
+	var puzzle *privatecaptcha.Puzzle
+	t.Run("Puzzle", func(t *testing.T) {
+		var err error
+		puzzle, err = client.GetPuzzle(ctx, privatecaptcha.GetPuzzleInput{Sitekey: opts.Sitekey, Origin: opts.Origin})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if puzzle.Metadata == nil {
+			t.Fatalf("Unexpected puzzle format: %q", puzzle.Data)
+		}
+
+		if !strings.EqualFold(puzzle.Metadata.Sitekey, opts.Sitekey) {
+			t.Errorf("Unexpected puzzle sitekey: %v", puzzle.Metadata.Sitekey)
+		}
+	})
+
+	// solution payload is "<solutions>.<puzzle>", where solutions of the test property are not checked
+	payload := func(t *testing.T, truncated bool) string {
+		if (puzzle == nil) || (puzzle.Metadata == nil) {
+			t.Skip("Puzzle is not available")
+		}
+
+		solutions := int(puzzle.Metadata.SolutionsCount)
+		if truncated {
+			solutions /= 2
+		}
+
+		data := make([]byte, solutions*SolutionLength)
+		return base64.StdEncoding.EncodeToString(data) + "." + string(puzzle.Data)
+	}
+
+	t.Run("TestProperty", func(t *testing.T) {
+		output, err := client.Verify(ctx, privatecaptcha.VerifyInput{Solution: payload(t, false), Sitekey: opts.Sitekey})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !output.Success || (output.Code != privatecaptcha.TestPropertyError) {
+			t.Errorf("Unexpected result: success=%v code=%v (request ID: %v)", output.Success, output.Code, output.RequestID())
+		}
+	})
+
+	t.Run("MalformedSolution", func(t *testing.T) {
+		output, err := client.Verify(ctx, privatecaptcha.VerifyInput{Solution: payload(t, true), Sitekey: opts.Sitekey, Attempts: 1})
+		if code, ok := privatecaptcha.GetStatusCode(err); !ok || (code != http.StatusBadRequest) {
+			t.Errorf("Unexpected error: %v (request ID: %v)", err, output.RequestID())
+		}
+	})
+
+	t.Run("EmptySolution", func(t *testing.T) {
+		if _, err := client.Verify(ctx, privatecaptcha.VerifyInput{Sitekey: opts.Sitekey}); err == nil {
+			t.Error("Expected error for empty solution")
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package privatecaptchatest
+
+import (
+	"os"
+	"testing"
+
+	privatecaptcha "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+// TestConformance runs the conformance suite against the hosted API (or a self-hosted one with PC_DOMAIN)
+func TestConformance(t *testing.T) {
+	apiKey := os.Getenv("PC_API_KEY")
+	if len(apiKey) == 0 {
+		t.Skip("PC_API_KEY is not set")
+	}
+
+	client, err := privatecaptcha.NewClient(privatecaptcha.Configuration{APIKey: apiKey, Domain: os.Getenv("PC_DOMAIN")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	RunConformance(t, client, ConformanceOptions{Sitekey: os.Getenv("PC_SITEKEY")})
+}
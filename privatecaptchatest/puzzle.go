@@ -0,0 +1,226 @@
+// Package privatecaptchatest provides utilities for testing code that uses Private Captcha without network access.
+//
+// Puzzles and solutions produced by this package are structurally valid (they have the same layout as real ones),
+// but they are signed with a test key and solutions are not proof-of-work, so they are only accepted by VerifySolution
+// of this package and never by the real API.
+package privatecaptchatest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/PrivateCaptcha/private-captcha-go/codes"
+)
+
+const (
+	// TestSitekey is the default sitekey of generated puzzles
+	TestSitekey = "aaaaaaaabbbbccccddddeeeeeeeeeeee"
+	// DefaultDifficulty is the default difficulty of generated puzzles
+	DefaultDifficulty = 65
+	// DefaultSolutionsCount is the default number of solutions of generated puzzles
+	DefaultSolutionsCount = 16
+	// DefaultPuzzleTTL is the default validity period of generated puzzles
+	DefaultPuzzleTTL = 1 * time.Hour
+	// SolutionLength is the size of a single solution in bytes
+	SolutionLength = 8
+
+	puzzleVersion = 1
+	propertyIDLen = 16
+	userDataLen   = 16
+	// version, property ID, puzzle ID, difficulty, solutions count, expiration, user data
+	puzzleLen = 1 + propertyIDLen + 8 + 1 + 1 + 4 + userDataLen
+)
+
+var (
+	errPuzzleFormat  = errors.New("privatecaptchatest: invalid puzzle format")
+	errPuzzleSitekey = errors.New("privatecaptchatest: invalid sitekey")
+	// signingKey is the key of test puzzles (it is not secret and must never be used outside of tests)
+	signingKey = []byte("private-captcha-go test puzzles")
+)
+
+// PuzzleOptions configures generated puzzle. The same options always produce the same puzzle.
+type PuzzleOptions struct {
+	// (optional) Sitekey of the puzzle property, 32 hex characters (defaults to TestSitekey)
+	Sitekey string
+	// (optional) Puzzle expiration (defaults to DefaultPuzzleTTL from now), set it in the past to get expired puzzles
+	Expiration time.Time
+	// (optional) Puzzle difficulty (defaults to DefaultDifficulty)
+	Difficulty uint8
+	// (optional) Number of solutions (defaults to DefaultSolutionsCount)
+	SolutionsCount uint8
+	// (optional) Puzzle identifier
+	ID uint64
+	// (optional) Arbitrary data bound to the puzzle (truncated to 16 bytes)
+	UserData []byte
+}
+
+// Puzzle is a decoded test puzzle
+type Puzzle struct {
+	PropertyID     [propertyIDLen]byte
+	ID             uint64
+	Difficulty     uint8
+	SolutionsCount uint8
+	Expiration     time.Time
+	UserData       [userDataLen]byte
+}
+
+// GeneratePuzzle creates a test puzzle. It panics if opts.Sitekey is not a valid sitekey.
+func GeneratePuzzle(opts PuzzleOptions) *Puzzle {
+	if len(opts.Sitekey) == 0 {
+		opts.Sitekey = TestSitekey
+	}
+
+	if opts.Expiration.IsZero() {
+		opts.Expiration = time.Now().Add(DefaultPuzzleTTL)
+	}
+
+	if opts.Difficulty == 0 {
+		opts.Difficulty = DefaultDifficulty
+	}
+
+	if opts.SolutionsCount == 0 {
+		opts.SolutionsCount = DefaultSolutionsCount
+	}
+
+	p := &Puzzle{
+		ID:             opts.ID,
+		Difficulty:     opts.Difficulty,
+		SolutionsCount: opts.SolutionsCount,
+		Expiration:     opts.Expiration.Truncate(time.Second),
+	}
+
+	propertyID, err := hex.DecodeString(opts.Sitekey)
+	if (err != nil) || (len(propertyID) != propertyIDLen) {
+		panic(errPuzzleSitekey)
+	}
+	copy(p.PropertyID[:], propertyID)
+	copy(p.UserData[:], opts.UserData)
+
+	return p
+}
+
+// Sitekey returns sitekey of the puzzle property
+func (p *Puzzle) Sitekey() string {
+	return hex.EncodeToString(p.PropertyID[:])
+}
+
+func (p *Puzzle) bytes() []byte {
+	data := make([]byte, 0, puzzleLen)
+	data = append(data, puzzleVersion)
+	data = append(data, p.PropertyID[:]...)
+	data = binary.BigEndian.AppendUint64(data, p.ID)
+	data = append(data, p.Difficulty, p.SolutionsCount)
+	data = binary.BigEndian.AppendUint32(data, uint32(p.Expiration.Unix()))
+	data = append(data, p.UserData[:]...)
+	return data
+}
+
+func sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// String returns the puzzle in the form returned by the puzzle endpoint: "<puzzle>.<signature>" (both base64-encoded)
+func (p *Puzzle) String() string {
+	data := p.bytes()
+	return base64.StdEncoding.EncodeToString(data) + "." + base64.StdEncoding.EncodeToString(sign(data))
+}
+
+// ParsePuzzle decodes the puzzle from its string form and checks its signature
+func ParsePuzzle(s string) (*Puzzle, error) {
+	encodedData, encodedSignature, ok := strings.Cut(s, ".")
+	if !ok {
+		return nil, errPuzzleFormat
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encodedData)
+	if (err != nil) || (len(data) != puzzleLen) || (data[0] != puzzleVersion) {
+		return nil, errPuzzleFormat
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+	if (err != nil) || !hmac.Equal(signature, sign(data)) {
+		return nil, errPuzzleFormat
+	}
+
+	p := &Puzzle{}
+	offset := 1
+	offset += copy(p.PropertyID[:], data[offset:])
+	p.ID = binary.BigEndian.Uint64(data[offset:])
+	offset += 8
+	p.Difficulty = data[offset]
+	p.SolutionsCount = data[offset+1]
+	offset += 2
+	p.Expiration = time.Unix(int64(binary.BigEndian.Uint32(data[offset:])), 0)
+	offset += 4
+	copy(p.UserData[:], data[offset:])
+
+	return p, nil
+}
+
+// solutions returns deterministic (fake) solutions of the puzzle
+func (p *Puzzle) solutions() []byte {
+	data := p.bytes()
+	solutions := make([]byte, 0, int(p.SolutionsCount)*SolutionLength)
+	for i := 0; i < int(p.SolutionsCount); i++ {
+		hash := sha256.Sum256(append(data, byte(i)))
+		solutions = append(solutions, hash[:SolutionLength]...)
+	}
+	return solutions
+}
+
+// MatchingSolution returns the solution payload (as sent by the widget in the form field) accepted by VerifySolution
+func MatchingSolution(p *Puzzle) string {
+	return base64.StdEncoding.EncodeToString(p.solutions()) + "." + p.String()
+}
+
+// WrongSolution returns the solution payload that is structurally valid, but is rejected by VerifySolution
+func WrongSolution(p *Puzzle) string {
+	return base64.StdEncoding.EncodeToString(make([]byte, int(p.SolutionsCount)*SolutionLength)) + "." + p.String()
+}
+
+// MalformedSolution returns the solution payload that can't be parsed
+func MalformedSolution(p *Puzzle) string {
+	return "malformed." + p.String()
+}
+
+// VerifySolution checks solution payload offline the same way the API does for real puzzles and returns the result code.
+// If sitekey is not empty, it must match sitekey of the puzzle.
+func VerifySolution(payload string, sitekey string, now time.Time) codes.Code {
+	encodedSolutions, encodedPuzzle, ok := strings.Cut(payload, ".")
+	if !ok {
+		return codes.ParseResponse
+	}
+
+	p, err := ParsePuzzle(encodedPuzzle)
+	if err != nil {
+		return codes.ParseResponse
+	}
+
+	solutions, err := base64.StdEncoding.DecodeString(encodedSolutions)
+	if err != nil {
+		return codes.ParseResponse
+	}
+
+	if (len(sitekey) > 0) && !strings.EqualFold(sitekey, p.Sitekey()) {
+		return codes.InvalidProperty
+	}
+
+	if !now.Before(p.Expiration) {
+		return codes.PuzzleExpired
+	}
+
+	if !bytes.Equal(solutions, p.solutions()) {
+		return codes.InvalidSolution
+	}
+
+	return codes.NoError
+}
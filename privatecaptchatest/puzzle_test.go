@@ -0,0 +1,72 @@
+package privatecaptchatest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PrivateCaptcha/private-captcha-go/codes"
+)
+
+func TestGeneratePuzzleDeterministic(t *testing.T) {
+	t.Parallel()
+
+	expiration := time.Now().Add(time.Minute)
+	opts := PuzzleOptions{Expiration: expiration, ID: 123, UserData: []byte("user")}
+
+	if GeneratePuzzle(opts).String() != GeneratePuzzle(opts).String() {
+		t.Error("Same options should produce the same puzzle")
+	}
+
+	p, err := ParsePuzzle(GeneratePuzzle(opts).String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (p.ID != 123) || (p.Sitekey() != TestSitekey) || (p.Expiration.Unix() != expiration.Unix()) ||
+		(p.Difficulty != DefaultDifficulty) || (p.SolutionsCount != DefaultSolutionsCount) {
+		t.Errorf("Unexpected puzzle: %+v", p)
+	}
+}
+
+func TestVerifySolution(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	puzzle := GeneratePuzzle(PuzzleOptions{})
+	expired := GeneratePuzzle(PuzzleOptions{Expiration: now.Add(-time.Minute)})
+	other := GeneratePuzzle(PuzzleOptions{Sitekey: "00000000111122223333444444444444"})
+
+	testCases := []struct {
+		payload string
+		code    codes.Code
+	}{
+		{MatchingSolution(puzzle), codes.NoError},
+		{WrongSolution(puzzle), codes.InvalidSolution},
+		{MalformedSolution(puzzle), codes.ParseResponse},
+		{MatchingSolution(expired), codes.PuzzleExpired},
+		{MatchingSolution(other), codes.InvalidProperty},
+		{"", codes.ParseResponse},
+		{"a.b.c", codes.ParseResponse},
+	}
+
+	for i, tc := range testCases {
+		if code := VerifySolution(tc.payload, TestSitekey, now); code != tc.code {
+			t.Errorf("Unexpected code %v for test case %v", code, i)
+		}
+	}
+}
+
+func FuzzVerifySolution(f *testing.F) {
+	puzzle := GeneratePuzzle(PuzzleOptions{})
+	f.Add(MatchingSolution(puzzle))
+	f.Add(MalformedSolution(puzzle))
+	f.Add("...")
+	f.Add(puzzle.String())
+
+	f.Fuzz(func(t *testing.T, payload string) {
+		code := VerifySolution(payload, "", time.Now())
+		if !code.IsKnown() {
+			t.Errorf("Unexpected code: %v", code)
+		}
+	})
+}
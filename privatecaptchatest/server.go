@@ -0,0 +1,199 @@
+package privatecaptchatest
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	privatecaptcha "github.com/PrivateCaptcha/private-captcha-go"
+	"github.com/PrivateCaptcha/private-captcha-go/codes"
+)
+
+const (
+	// TestAPIKey is the API key accepted by Server
+	TestAPIKey = "pc_test_api_key"
+	// maxRequestSize limits verify request body read by Server
+	maxRequestSize = 64 * 1024
+)
+
+// Fault is a scripted failure of a single request to Server. It returns true if it has written the response,
+// otherwise the request is served normally (e.g. after a delay).
+type Fault func(w http.ResponseWriter, r *http.Request) bool
+
+// RateLimited responds with http.StatusTooManyRequests and Retry-After header
+func RateLimited(retryAfterSeconds int) Fault {
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+		w.WriteHeader(http.StatusTooManyRequests)
+		return true
+	}
+}
+
+// StatusCode responds with an empty body and the status code (e.g. http.StatusServiceUnavailable)
+func StatusCode(code int) Fault {
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(code)
+		return true
+	}
+}
+
+// DropConnection closes the connection without sending a response
+func DropConnection() Fault {
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		// aborts the handler and closes the connection without writing the response
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// TruncatedJSON responds with http.StatusOK and a response body cut in the middle
+func TruncatedJSON() Fault {
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":tr`))
+		return true
+	}
+}
+
+// Delay delays the response (e.g. past the client timeout), after which the request is served normally
+func Delay(d time.Duration) Fault {
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		select {
+		case <-time.After(d):
+		case <-r.Context().Done():
+			return true
+		}
+		return false
+	}
+}
+
+// Server is a fake Private Captcha API server, which verifies solutions created by this package (see VerifySolution)
+// and can inject faults into responses to test retry and fail-open configuration.
+type Server struct {
+	*httptest.Server
+	mu       sync.Mutex
+	faults   []Fault
+	requests atomic.Int64
+}
+
+// NewServer starts a TLS server. It should be closed with Close() after use.
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Inject schedules faults for the next requests: each fault is used for exactly one request in order,
+// after which requests are served normally.
+func (s *Server) Inject(faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, faults...)
+}
+
+// InjectN schedules the same fault for the next n requests, e.g. InjectN(3, RateLimited(1))
+func (s *Server) InjectN(n int, fault Fault) {
+	for i := 0; i < n; i++ {
+		s.Inject(fault)
+	}
+}
+
+// Requests returns the number of received verify requests
+func (s *Server) Requests() int {
+	return int(s.requests.Load())
+}
+
+// Configuration returns client configuration to use with this server
+func (s *Server) Configuration() privatecaptcha.Configuration {
+	return privatecaptcha.Configuration{
+		Domain: s.URL,
+		APIKey: TestAPIKey,
+		Client: s.Client(),
+	}
+}
+
+func (s *Server) nextFault() Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.faults) == 0 {
+		return nil
+	}
+
+	fault := s.faults[0]
+	s.faults = s.faults[1:]
+
+	return fault
+}
+
+// readSolution reads solution from the request body encoded with any of privatecaptcha.PayloadFormat
+func readSolution(r *http.Request) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		return "", err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(data))
+		if err != nil {
+			return "", err
+		}
+		return values.Get("response"), nil
+	case "application/json":
+		var body struct {
+			Solution string `json:"solution"`
+		}
+		if err := json.Unmarshal(data, &body); err != nil {
+			return "", err
+		}
+		return body.Solution, nil
+	default:
+		return string(data), nil
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if (r.Method != http.MethodPost) || (r.URL.Path != "/verify") {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.requests.Add(1)
+
+	if fault := s.nextFault(); (fault != nil) && fault(w, r) {
+		return
+	}
+
+	if r.Header.Get("X-Api-Key") != TestAPIKey {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	solution, err := readSolution(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	code := VerifySolution(solution, r.Header.Get("X-PC-Sitekey"), time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		Success   bool       `json:"success"`
+		Code      codes.Code `json:"code"`
+		Timestamp string     `json:"timestamp"`
+	}{
+		Success:   code == codes.NoError,
+		Code:      code,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,68 @@
+package privatecaptchatest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	privatecaptcha "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+func newTestClient(t *testing.T, s *Server) *privatecaptcha.Client {
+	t.Helper()
+
+	client, err := privatecaptcha.NewClient(s.Configuration())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestServerVerify(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer()
+	t.Cleanup(s.Close)
+	client := newTestClient(t, s)
+	puzzle := GeneratePuzzle(PuzzleOptions{})
+
+	output, err := client.Verify(context.TODO(), privatecaptcha.VerifyInput{Solution: MatchingSolution(puzzle)})
+	if err != nil || !output.OK() {
+		t.Fatalf("Unexpected result: %v (%v)", output.Error(), err)
+	}
+
+	output, err = client.Verify(context.TODO(), privatecaptcha.VerifyInput{Solution: WrongSolution(puzzle)})
+	if err != nil || output.OK() || (output.Code != privatecaptcha.InvalidSolutionError) {
+		t.Errorf("Unexpected result: %v (%v)", output.Error(), err)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer()
+	t.Cleanup(s.Close)
+	client := newTestClient(t, s)
+	solution := MatchingSolution(GeneratePuzzle(PuzzleOptions{}))
+
+	s.Inject(RateLimited(1), DropConnection(), TruncatedJSON(), StatusCode(http.StatusServiceUnavailable))
+
+	output, err := client.Verify(context.TODO(), privatecaptcha.VerifyInput{Solution: solution, Attempts: 5, MaxBackoffSeconds: 1})
+	if err != nil || !output.OK() {
+		t.Fatalf("Unexpected result: %v (%v)", output.Error(), err)
+	}
+
+	if s.Requests() != 5 {
+		t.Errorf("Unexpected number of requests: %v", s.Requests())
+	}
+
+	s.Inject(Delay(time.Second))
+
+	_, err = client.Verify(context.TODO(), privatecaptcha.VerifyInput{Solution: solution, Attempts: 1, AttemptTimeout: 50 * time.Millisecond})
+	if !errors.Is(err, privatecaptcha.ErrAttemptTimeout) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package privatecaptchatest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	privatecaptcha "github.com/PrivateCaptcha/private-captcha-go"
+	"github.com/PrivateCaptcha/private-captcha-go/codes"
+)
+
+var (
+	errVerificationFailed = errors.New("privatecaptchatest: verification failed")
+)
+
+// Verifier is a fake implementation of privatecaptcha.SolutionVerifier, privatecaptcha.RequestVerifier and
+// privatecaptcha.MiddlewareProvider, which accepts or rejects all solutions. It can be bound instead of
+// *privatecaptcha.Client in dependency injection containers of test or development environments.
+type Verifier struct {
+	// Code is returned for all verifications (codes.NoError accepts all solutions)
+	Code  codes.Code
+	calls atomic.Int64
+}
+
+var (
+	_ privatecaptcha.SolutionVerifier   = (*Verifier)(nil)
+	_ privatecaptcha.RequestVerifier    = (*Verifier)(nil)
+	_ privatecaptcha.MiddlewareProvider = (*Verifier)(nil)
+)
+
+// Calls returns the number of verifications made
+func (v *Verifier) Calls() int {
+	return int(v.calls.Load())
+}
+
+func (v *Verifier) Verify(ctx context.Context, input privatecaptcha.VerifyInput) (*privatecaptcha.VerifyOutput, error) {
+	v.calls.Add(1)
+	return &privatecaptcha.VerifyOutput{Success: v.Code == codes.NoError, Code: v.Code}, nil
+}
+
+func (v *Verifier) VerifyRequest(ctx context.Context, r *http.Request) error {
+	output, _ := v.Verify(ctx, privatecaptcha.VerifyInput{})
+	if !output.OK() {
+		return errVerificationFailed
+	}
+
+	return nil
+}
+
+func (v *Verifier) Middleware(opts privatecaptcha.MiddlewareOptions) func(http.Handler) http.Handler {
+	status := opts.FailedStatusCode
+	if status == 0 {
+		status = http.StatusForbidden
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := v.VerifyRequest(r.Context(), r); err != nil {
+				http.Error(w, http.StatusText(status), status)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(privatecaptcha.WithVerified(r.Context())))
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package privatecaptchatest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	privatecaptcha "github.com/PrivateCaptcha/private-captcha-go"
+	"github.com/PrivateCaptcha/private-captcha-go/codes"
+)
+
+func TestVerifierMiddleware(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		code   codes.Code
+		status int
+	}{
+		{codes.NoError, http.StatusOK},
+		{codes.InvalidSolution, http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		v := &Verifier{Code: tc.code}
+		var provider privatecaptcha.MiddlewareProvider = v
+
+		handler := provider.Middleware(privatecaptcha.MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := privatecaptcha.RequireVerified(r.Context()); err != nil {
+				t.Error(err)
+			}
+		}))
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		if (recorder.Code != tc.status) || (v.Calls() != 1) {
+			t.Errorf("Unexpected status %v for code %v", recorder.Code, tc.code)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFailoverDuration is how long the fallback provider is used after primary failed hard
+	DefaultFailoverDuration = 30 * time.Second
+)
+
+// Provider is a minimal interface of a captcha verification service. *Client implements it, so the
+// fallback can be another Private Captcha deployment or a custom implementation.
+type Provider interface {
+	Verify(ctx context.Context, input VerifyInput) (*VerifyOutput, error)
+}
+
+var _ Provider = (*Client)(nil)
+
+// failover routes verifications to the fallback provider after primary fails hard. Failover is sticky:
+// fallback is used for the configured duration, after which primary is tried again and, if healthy, restored.
+// Only a single verification probes primary after failover, concurrent ones wait for its result instead of
+// probing (and extending the outage) independently.
+type failover struct {
+	fallback Provider
+	duration time.Duration
+	logger   *slog.Logger
+	mu       sync.Mutex
+	failedAt time.Time
+	// probe is closed when in-flight probe of primary completes (nil if there's none)
+	probe chan struct{}
+}
+
+func (f *failover) active() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return !f.failedAt.IsZero() && (time.Since(f.failedAt) < f.duration)
+}
+
+// breaker returns state of the failover to Configuration.Fallback
+func (f *failover) breaker() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case f.failedAt.IsZero():
+		return "closed"
+	case time.Since(f.failedAt) < f.duration:
+		return "open"
+	default:
+		return "half-open"
+	}
+}
+
+func (f *failover) setFailed(failed bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if failed {
+		f.failedAt = time.Now()
+	} else {
+		f.failedAt = time.Time{}
+	}
+}
+
+// isHardFailure returns true if error means that provider is unavailable (as opposed to bad input)
+func isHardFailure(ctx context.Context, err error) bool {
+	if (err == nil) || (ctx.Err() != nil) {
+		return false
+	}
+
+	if errors.Is(err, errEmtpySolution) || errors.Is(err, ErrSolutionTooLong) || errors.Is(err, errVerifyPath) {
+		return false
+	}
+
+	var originErr *OriginError
+	if errors.As(err, &originErr) {
+		return false
+	}
+
+	if code, ok := GetStatusCode(err); ok {
+		return isRetriableStatus(code)
+	}
+
+	return true
+}
+
+type failoverState int
+
+const (
+	failoverPrimary failoverState = iota
+	failoverFallback
+	failoverProbing
+	failoverProbe
+)
+
+// state returns how the verification should proceed. When failover expired and there's no probe in-flight,
+// caller becomes the probe and must call endProbe() afterwards. Otherwise, while probing, it waits on the channel.
+func (f *failover) state() (failoverState, chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case f.failedAt.IsZero():
+		return failoverPrimary, nil
+	case time.Since(f.failedAt) < f.duration:
+		return failoverFallback, nil
+	case f.probe != nil:
+		return failoverProbing, f.probe
+	default:
+		f.probe = make(chan struct{})
+		return failoverProbe, f.probe
+	}
+}
+
+// endProbe releases verifications waiting for the probe, it is safe to call it more than once
+func (f *failover) endProbe(probe chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.probe == probe {
+		close(probe)
+		f.probe = nil
+	}
+}
+
+func (f *failover) verify(ctx context.Context, input VerifyInput, primary func(context.Context, VerifyInput) (*VerifyOutput, error)) (*VerifyOutput, error) {
+	for {
+		state, probe := f.state()
+		switch state {
+		case failoverFallback:
+			f.logger.Log(ctx, levelTrace, "Using fallback provider")
+			return f.fallback.Verify(ctx, input)
+		case failoverProbing:
+			f.logger.Log(ctx, levelTrace, "Waiting for primary provider probe")
+			select {
+			case <-probe:
+				// state is re-evaluated with the probe result
+				continue
+			case <-ctx.Done():
+				return nil, contextError(ctx)
+			}
+		case failoverProbe:
+			f.logger.Log(ctx, levelTrace, "Probing primary provider after failover")
+			defer f.endProbe(probe)
+		}
+
+		output, err := primary(ctx, input)
+		failed := isHardFailure(ctx, err)
+		if failed {
+			f.setFailed(true)
+		} else if (state != failoverProbe) || (ctx.Err() == nil) {
+			// canceled probe tells nothing about primary health, so the next waiting verification probes again
+			f.setFailed(false)
+		}
+
+		if state == failoverProbe {
+			f.endProbe(probe)
+		}
+
+		if failed {
+			f.logger.Log(ctx, levelTrace, "Primary provider failed, switching to fallback", "duration", f.duration.String(), errAttr(err))
+			return f.fallback.Verify(ctx, input)
+		}
+
+		return output, err
+	}
+}
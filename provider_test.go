@@ -0,0 +1,123 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type stubProvider struct {
+	calls atomic.Int32
+}
+
+func (p *stubProvider) Verify(ctx context.Context, input VerifyInput) (*VerifyOutput, error) {
+	p.calls.Add(1)
+	return &VerifyOutput{Success: true, Code: VerifyNoError}, nil
+}
+
+func TestFallbackProvider(t *testing.T) {
+	t.Parallel()
+
+	var primaryCalls atomic.Int32
+	var healthy atomic.Bool
+	fallback := &stubProvider{}
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{Fallback: fallback, FailoverDuration: 200 * time.Millisecond})
+
+	input := VerifyInput{Solution: "asdf", Attempts: 1}
+
+	for i := 0; i < 3; i++ {
+		output, err := client.Verify(context.TODO(), input)
+		if err != nil || !output.OK() {
+			t.Fatalf("Unexpected result: %v (%v)", output.Error(), err)
+		}
+	}
+
+	// failover is sticky: primary is only called once
+	if (primaryCalls.Load() != 1) || (fallback.calls.Load() != 3) {
+		t.Errorf("Unexpected calls: primary=%v fallback=%v", primaryCalls.Load(), fallback.calls.Load())
+	}
+
+	healthy.Store(true)
+	time.Sleep(250 * time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Verify(context.TODO(), input); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if (primaryCalls.Load() != 3) || (fallback.calls.Load() != 3) {
+		t.Errorf("Unexpected calls after recovery: primary=%v fallback=%v", primaryCalls.Load(), fallback.calls.Load())
+	}
+}
+
+func TestFailoverProbeCoalescing(t *testing.T) {
+	t.Parallel()
+
+	const waiters = 5
+
+	for _, healthy := range []bool{false, true} {
+		fallback := &stubProvider{}
+		f := &failover{fallback: fallback, duration: time.Minute, failedAt: time.Now().Add(-time.Hour), logger: discardLogger}
+
+		var primaryCalls atomic.Int32
+		entered := make(chan struct{}, 1)
+		release := make(chan struct{})
+		primary := func(ctx context.Context, input VerifyInput) (*VerifyOutput, error) {
+			if primaryCalls.Add(1) == 1 {
+				entered <- struct{}{}
+				<-release
+			}
+			if !healthy {
+				return nil, HTTPError{StatusCode: http.StatusServiceUnavailable}
+			}
+			return &VerifyOutput{Success: true}, nil
+		}
+
+		var wg sync.WaitGroup
+		verify := func() {
+			defer wg.Done()
+			if output, err := f.verify(context.TODO(), VerifyInput{}, primary); (err != nil) || !output.OK() {
+				t.Errorf("Unexpected result: %v", err)
+			}
+		}
+
+		wg.Add(1)
+		go verify()
+		<-entered
+
+		for i := 0; i < waiters; i++ {
+			wg.Add(1)
+			go verify()
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		if primaryCalls.Load() != 1 {
+			t.Errorf("Unexpected primary calls during probe: %v", primaryCalls.Load())
+		}
+
+		close(release)
+		wg.Wait()
+
+		// failed probe sends waiters to fallback, successful one - to primary
+		expectedPrimary, expectedFallback := int32(1), int32(waiters+1)
+		if healthy {
+			expectedPrimary, expectedFallback = waiters+1, 0
+		}
+
+		if (primaryCalls.Load() != expectedPrimary) || (fallback.calls.Load() != expectedFallback) {
+			t.Errorf("Unexpected calls (healthy=%v): primary=%v fallback=%v", healthy, primaryCalls.Load(), fallback.calls.Load())
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package privatecaptcha
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// puzzlePath is the path of the puzzle endpoint, relative to the verify endpoint
+	puzzlePath = "/puzzle"
+	// maxPuzzleSize limits the size of puzzle response body
+	maxPuzzleSize = 64 << 10
+	puzzleVersion = 1
+	// version, property ID, puzzle ID, difficulty, solutions count, expiration, user data
+	puzzleMinLen = 1 + 16 + 8 + 1 + 1 + 4
+)
+
+var (
+	errEmptySitekey   = errors.New("privatecaptcha: sitekey is empty")
+	errEmptyOrigin    = errors.New("privatecaptcha: puzzle origin is empty")
+	errPuzzleTooLarge = errors.New("privatecaptcha: puzzle is too large")
+	errPuzzleFormat   = errors.New("privatecaptcha: invalid puzzle format")
+)
+
+// GetPuzzleInput describes puzzle request
+type GetPuzzleInput struct {
+	Sitekey string
+	// (optional) Origin of the puzzle request (defaults to Configuration.Origin), the API only serves puzzles for
+	// origins allowed in property settings
+	Origin string
+	// (optional) Additional headers of the puzzle request (e.g. forwarded from the client by PuzzleProxy)
+	Header http.Header
+}
+
+// PuzzleMetadata is the parsed header of the puzzle
+type PuzzleMetadata struct {
+	// Sitekey of the property the puzzle was issued for
+	Sitekey        string
+	ID             uint64
+	Difficulty     uint8
+	SolutionsCount uint8
+	Expiration     time.Time
+}
+
+// Puzzle is the puzzle served by the API
+type Puzzle struct {
+	// Data is the puzzle as-is, it can be served to the widget (e.g. when backend proxies puzzles)
+	Data []byte
+	// Metadata is nil if the puzzle format is not recognized (e.g. it is newer than this release of SDK)
+	Metadata *PuzzleMetadata
+	// ContentType is the content type of the puzzle response
+	ContentType string
+	// RequestID is the trace ID of the puzzle request
+	RequestID string
+}
+
+// parsePuzzleMetadata decodes the puzzle header from "<puzzle>.<signature>" form (both base64-encoded)
+func parsePuzzleMetadata(data []byte) (*PuzzleMetadata, error) {
+	encoded, _, ok := strings.Cut(string(data), ".")
+	if !ok {
+		return nil, errPuzzleFormat
+	}
+
+	puzzle, err := base64.StdEncoding.DecodeString(encoded)
+	if (err != nil) || (len(puzzle) < puzzleMinLen) || (puzzle[0] != puzzleVersion) {
+		return nil, errPuzzleFormat
+	}
+
+	return &PuzzleMetadata{
+		Sitekey:        hex.EncodeToString(puzzle[1:17]),
+		ID:             binary.BigEndian.Uint64(puzzle[17:25]),
+		Difficulty:     puzzle[25],
+		SolutionsCount: puzzle[26],
+		Expiration:     time.Unix(int64(binary.BigEndian.Uint32(puzzle[27:31])), 0),
+	}, nil
+}
+
+// puzzleEndpoint returns the URL of the puzzle endpoint next to the verify endpoint
+func (c *Client) puzzleEndpoint(sitekey string) string {
+	return strings.TrimSuffix(c.Endpoint(), c.verifyPath) + puzzlePath + "?" + url.Values{"sitekey": {sitekey}}.Encode()
+}
+
+// GetPuzzle fetches a new puzzle for the sitekey, e.g. to proxy puzzles via the backend (native mobile apps,
+// strict CSP) or for server-driven flows. Puzzle endpoint is public, so API key is not sent.
+func (c *Client) GetPuzzle(ctx context.Context, input GetPuzzleInput) (*Puzzle, error) {
+	if len(input.Sitekey) == 0 {
+		return nil, errEmptySitekey
+	}
+
+	origin := input.Origin
+	if len(origin) == 0 {
+		origin = c.origin
+	}
+
+	if len(origin) == 0 {
+		return nil, errEmptyOrigin
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.puzzleEndpoint(input.Sitekey), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range input.Header {
+		req.Header[http.CanonicalHeaderKey(name)] = values
+	}
+	req.Header.Set(headerOrigin, origin)
+	req.Header.Set(headerUserAgent, userAgent)
+
+	c.logger.Log(ctx, levelTrace, "About to send puzzle request", "sitekey", input.Sitekey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.logger.Log(ctx, levelTrace, "Failed to send HTTP request", "path", req.URL.Path, "method", req.Method, errAttr(err))
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	traceID := resp.Header.Get(headerTraceID)
+
+	if resp.StatusCode >= 300 {
+		c.logger.Log(ctx, levelTrace, "Puzzle request failed", "status", resp.StatusCode, "traceID", traceID)
+		return nil, HTTPError{StatusCode: resp.StatusCode, TraceID: traceID}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPuzzleSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxPuzzleSize {
+		return nil, errPuzzleTooLarge
+	}
+
+	puzzle := &Puzzle{Data: data, ContentType: resp.Header.Get(headerContentType), RequestID: traceID}
+	if puzzle.Metadata, err = parsePuzzleMetadata(data); err != nil {
+		c.logger.Log(ctx, levelTrace, "Failed to parse puzzle metadata", "traceID", traceID, errAttr(err))
+	}
+
+	c.logger.Log(ctx, levelTrace, "Received puzzle", "puzzle", len(data), "traceID", traceID)
+
+	return puzzle, nil
+}
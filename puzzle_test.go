@@ -0,0 +1,77 @@
+package privatecaptcha
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func testPuzzle(sitekey string, expiration time.Time) string {
+	propertyID, _ := hex.DecodeString(sitekey)
+
+	data := []byte{puzzleVersion}
+	data = append(data, propertyID...)
+	data = binary.BigEndian.AppendUint64(data, 42)
+	data = append(data, 65, 16)
+	data = binary.BigEndian.AppendUint32(data, uint32(expiration.Unix()))
+	data = append(data, make([]byte, 16)...)
+
+	return base64.StdEncoding.EncodeToString(data) + "." + base64.StdEncoding.EncodeToString([]byte("signature"))
+}
+
+func TestGetPuzzle(t *testing.T) {
+	t.Parallel()
+
+	expiration := time.Now().Add(time.Hour).Truncate(time.Second)
+	puzzle := testPuzzle(testSitekey, expiration)
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if (r.Method != http.MethodGet) || (r.URL.Path != puzzlePath) || (len(r.Header.Get(headerApiKey)) > 0) {
+			t.Errorf("Unexpected request: %v %v", r.Method, r.URL)
+		}
+
+		if (r.URL.Query().Get("sitekey") != testSitekey) || (r.Header.Get(headerOrigin) != "example.com") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set(headerTraceID, "trace")
+		w.Write([]byte(puzzle))
+	}, Configuration{Origin: "example.com"})
+
+	output, err := client.GetPuzzle(context.TODO(), GetPuzzleInput{Sitekey: testSitekey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (string(output.Data) != puzzle) || (output.RequestID != "trace") || (output.Metadata == nil) {
+		t.Fatalf("Unexpected puzzle: %+v", output)
+	}
+
+	m := output.Metadata
+	if (m.Sitekey != testSitekey) || (m.ID != 42) || (m.Difficulty != 65) || (m.SolutionsCount != 16) || !m.Expiration.Equal(expiration) {
+		t.Errorf("Unexpected metadata: %+v", output.Metadata)
+	}
+
+	if _, err := client.GetPuzzle(context.TODO(), GetPuzzleInput{Sitekey: testSitekey, Origin: "other.com"}); err == nil {
+		t.Error("Expected error for wrong origin")
+	}
+
+	if _, err := client.GetPuzzle(context.TODO(), GetPuzzleInput{}); err != errEmptySitekey {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestParsePuzzleMetadata(t *testing.T) {
+	t.Parallel()
+
+	for _, data := range []string{"", "no-signature", "!!!.sig", base64.StdEncoding.EncodeToString([]byte{puzzleVersion, 1, 2}) + ".sig"} {
+		if _, err := parsePuzzleMetadata([]byte(data)); err != errPuzzleFormat {
+			t.Errorf("Unexpected error for %q: %v", data, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"slices"
+)
+
+// PuzzleCache caches puzzles served by PuzzleProxy. Cached puzzles are shared between visitors of the same sitekey
+// and origin, so implementations should keep them for a short time only (a few seconds under high load).
+type PuzzleCache interface {
+	Get(ctx context.Context, key string) (*Puzzle, bool)
+	Set(ctx context.Context, key string, puzzle *Puzzle)
+}
+
+// PuzzleProxyOptions configures PuzzleProxy
+type PuzzleProxyOptions struct {
+	// (optional) Sitekeys allowed to be proxied (all sitekeys are allowed if empty)
+	Sitekeys []string
+	// (optional) Returns origin of the puzzle request (defaults to Origin header or, for same-origin requests
+	// where browsers omit it, the Host of the request)
+	Origin func(r *http.Request) string
+	// (optional) Client headers forwarded to the API. Client identifiers (IP address, cookies, User-Agent etc.)
+	// are stripped unless they are listed here
+	ForwardHeaders []string
+	// (optional) Cache of puzzles
+	Cache PuzzleCache
+	// (optional) Rate limiting hook, requests are rejected with http.StatusTooManyRequests if it returns false
+	// (client IP address can be obtained with Client.RealIP())
+	Allow func(r *http.Request) bool
+}
+
+func requestOrigin(r *http.Request) string {
+	if origin := r.Header.Get(headerOrigin); len(origin) > 0 {
+		return origin
+	}
+
+	return r.Host
+}
+
+// PuzzleProxy returns http.Handler that serves puzzles from the API under the first-party domain, for environments
+// with strict CSP or ad-blockers. It is mounted at the path the widget is configured to fetch puzzles from (e.g.
+// "/captcha/puzzle") and expects "sitekey" query parameter, same as the puzzle endpoint of the API.
+func (c *Client) PuzzleProxy(opts PuzzleProxyOptions) http.Handler {
+	if opts.Origin == nil {
+		opts.Origin = requestOrigin
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (r.Method != http.MethodGet) && (r.Method != http.MethodHead) {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		sitekey := r.URL.Query().Get("sitekey")
+		if len(sitekey) == 0 {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		if (len(opts.Sitekeys) > 0) && !slices.Contains(opts.Sitekeys, sitekey) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		if (opts.Allow != nil) && !opts.Allow(r) {
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+
+		ctx := r.Context()
+		origin := opts.Origin(r)
+		key := sitekey + "|" + origin
+
+		var puzzle *Puzzle
+		var cached bool
+		if opts.Cache != nil {
+			puzzle, cached = opts.Cache.Get(ctx, key)
+		}
+
+		if !cached {
+			input := GetPuzzleInput{Sitekey: sitekey, Origin: origin, Header: http.Header{}}
+			for _, name := range opts.ForwardHeaders {
+				if values := r.Header.Values(name); len(values) > 0 {
+					input.Header[http.CanonicalHeaderKey(name)] = values
+				}
+			}
+
+			var err error
+			if puzzle, err = c.GetPuzzle(ctx, input); err != nil {
+				c.logger.Log(ctx, levelTrace, "Failed to proxy puzzle", "sitekey", sitekey, errAttr(err))
+
+				// client errors of the API (e.g. origin is not allowed) are passed through
+				status := http.StatusBadGateway
+				if code, ok := GetStatusCode(err); ok && (code >= 400) && (code < 500) {
+					status = code
+				}
+				http.Error(w, http.StatusText(status), status)
+				return
+			}
+
+			if opts.Cache != nil {
+				opts.Cache.Set(ctx, key, puzzle)
+			}
+		}
+
+		if len(puzzle.ContentType) > 0 {
+			w.Header().Set(headerContentType, puzzle.ContentType)
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+
+		if r.Method != http.MethodHead {
+			w.Write(puzzle.Data)
+		}
+	})
+}
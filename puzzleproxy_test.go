@@ -0,0 +1,104 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type mapPuzzleCache struct {
+	mu      sync.Mutex
+	puzzles map[string]*Puzzle
+}
+
+func (c *mapPuzzleCache) Get(ctx context.Context, key string) (*Puzzle, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.puzzles[key]
+	return p, ok
+}
+
+func (c *mapPuzzleCache) Set(ctx context.Context, key string, puzzle *Puzzle) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.puzzles[key] = puzzle
+}
+
+func TestPuzzleProxy(t *testing.T) {
+	t.Parallel()
+
+	puzzle := testPuzzle(testSitekey, time.Now().Add(time.Hour))
+
+	var calls atomic.Int32
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if (len(r.Header.Get("Cookie")) > 0) || (len(r.Header.Get("X-Forwarded-For")) > 0) {
+			t.Errorf("Unexpected client identifiers: %v", r.Header)
+		}
+		if r.Header.Get(headerOrigin) != "example.com" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set(headerContentType, "text/plain")
+		w.Write([]byte(puzzle))
+	}, Configuration{})
+
+	var limited atomic.Bool
+	handler := client.PuzzleProxy(PuzzleProxyOptions{
+		Sitekeys:       []string{testSitekey},
+		ForwardHeaders: []string{"Accept-Language"},
+		Cache:          &mapPuzzleCache{puzzles: map[string]*Puzzle{}},
+		Allow:          func(r *http.Request) bool { return !limited.Load() },
+	})
+
+	serve := func(method, target, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Host = "example.com"
+		if len(origin) > 0 {
+			req.Header.Set(headerOrigin, origin)
+		}
+		req.Header.Set("Cookie", "session=secret")
+		req.Header.Set("X-Forwarded-For", "192.0.2.1")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// same-origin request without Origin header
+	recorder := serve(http.MethodGet, "/captcha/puzzle?sitekey="+testSitekey, "")
+	if (recorder.Code != http.StatusOK) || (recorder.Body.String() != puzzle) || (recorder.Header().Get(headerContentType) != "text/plain") {
+		t.Fatalf("Unexpected response: %v %v", recorder.Code, recorder.Body.String())
+	}
+
+	// second request is served from cache
+	if recorder := serve(http.MethodGet, "/captcha/puzzle?sitekey="+testSitekey, "example.com"); (recorder.Code != http.StatusOK) || (calls.Load() != 1) {
+		t.Errorf("Unexpected cached response: %v (calls=%v)", recorder.Code, calls.Load())
+	}
+
+	testCases := []struct {
+		method string
+		target string
+		origin string
+		status int
+	}{
+		{http.MethodPost, "/captcha/puzzle?sitekey=" + testSitekey, "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/captcha/puzzle", "", http.StatusBadRequest},
+		{http.MethodGet, "/captcha/puzzle?sitekey=other", "", http.StatusForbidden},
+		{http.MethodGet, "/captcha/puzzle?sitekey=" + testSitekey, "evil.com", http.StatusForbidden},
+	}
+
+	for i, tc := range testCases {
+		if recorder := serve(tc.method, tc.target, tc.origin); recorder.Code != tc.status {
+			t.Errorf("Unexpected status (%v): %v", i, recorder.Code)
+		}
+	}
+
+	limited.Store(true)
+	if recorder := serve(http.MethodGet, "/captcha/puzzle?sitekey="+testSitekey, ""); recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("Unexpected status when rate limited: %v", recorder.Code)
+	}
+}
@@ -0,0 +1,160 @@
+package privatecaptcha
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+var (
+	headerForwarded     = http.CanonicalHeaderKey("Forwarded")
+	headerXForwardedFor = http.CanonicalHeaderKey("X-Forwarded-For")
+	errTrustedProxy     = errors.New("privatecaptcha: invalid trusted proxy")
+)
+
+// parseTrustedProxies parses CIDRs and single IP addresses of Configuration.TrustedProxies
+func parseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+
+		if strings.Contains(p, "/") {
+			prefix, err := netip.ParsePrefix(p)
+			if err != nil {
+				return nil, fmt.Errorf("%w %q: %v", errTrustedProxy, p, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(p)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", errTrustedProxy, p, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return prefixes, nil
+}
+
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseForwardedAddr parses node of Forwarded or X-Forwarded-For header, e.g. 192.0.2.1, "192.0.2.1:443" or
+// "[2001:db8::1]:443" (obfuscated identifiers and "unknown" are not valid addresses)
+func parseForwardedAddr(s string) (netip.Addr, bool) {
+	s = strings.Trim(strings.TrimSpace(s), `"`)
+
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return netip.Addr{}, false
+		}
+		s = s[1:end]
+	} else if strings.Count(s, ":") == 1 {
+		s, _, _ = strings.Cut(s, ":")
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return addr.Unmap(), true
+}
+
+// forwardedChain returns client addresses from Forwarded header (RFC 7239) or, if it's missing, X-Forwarded-For,
+// in the order they were appended by proxies
+func forwardedChain(r *http.Request) []string {
+	var chain []string
+
+	if values := r.Header.Values(headerForwarded); len(values) > 0 {
+		for _, v := range values {
+			for _, element := range strings.Split(v, ",") {
+				for _, pair := range strings.Split(element, ";") {
+					if key, value, ok := strings.Cut(strings.TrimSpace(pair), "="); ok && strings.EqualFold(key, "for") {
+						chain = append(chain, value)
+					}
+				}
+			}
+		}
+
+		return chain
+	}
+
+	for _, v := range r.Header.Values(headerXForwardedFor) {
+		chain = append(chain, strings.Split(v, ",")...)
+	}
+
+	return chain
+}
+
+// remoteAddr returns the IP address of the connection peer
+func remoteAddr(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+
+	return r.RemoteAddr
+}
+
+// realIP walks forwarding headers from the right while the hops are trusted proxies and returns the first
+// untrusted address (headers are ignored if the connection peer itself is not trusted)
+func realIP(r *http.Request, trusted []netip.Prefix) string {
+	peer := remoteAddr(r)
+	if len(trusted) == 0 {
+		return peer
+	}
+
+	addr, err := netip.ParseAddr(peer)
+	if (err != nil) || !isTrusted(addr.Unmap(), trusted) {
+		return peer
+	}
+
+	ip := addr.Unmap()
+	chain := forwardedChain(r)
+	for i := len(chain) - 1; i >= 0; i-- {
+		hop, ok := parseForwardedAddr(chain[i])
+		if !ok {
+			// cannot walk past a hop we cannot identify
+			break
+		}
+
+		ip = hop
+		if !isTrusted(hop, trusted) {
+			break
+		}
+	}
+
+	return ip.String()
+}
+
+// RealIP returns the IP address of the client that sent the request. Forwarded and X-Forwarded-For headers are
+// only honored when they were set by Configuration.TrustedProxies, otherwise the connection address is returned.
+func (c *Client) RealIP(r *http.Request) string {
+	return realIP(r, c.trustedProxies)
+}
+
+// ParseNativeRequest is like the package-level ParseNativeRequest(), but RemoteIP is resolved with RealIP()
+func (c *Client) ParseNativeRequest(r *http.Request) (NativeInput, error) {
+	input, err := ParseNativeRequest(r)
+	if err != nil {
+		return input, err
+	}
+
+	input.RemoteIP = c.RealIP(r)
+
+	return input, nil
+}
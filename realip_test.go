@@ -0,0 +1,69 @@
+package privatecaptcha
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(Configuration{APIKey: "test-api-key", TrustedProxies: []string{"10.0.0.0/8", "2001:db8::1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		expected   string
+	}{
+		{"direct", "192.0.2.1:1234", nil, "192.0.2.1"},
+		{"untrusted peer", "192.0.2.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "192.0.2.1"},
+		{"trusted peer", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"spoofed chain", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "203.0.113.7, 198.51.100.1, 10.0.0.2"}, "198.51.100.1"},
+		{"all trusted", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"invalid hop", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.1, garbage"}, "10.0.0.1"},
+		{"ipv6 peer", "[2001:db8::1]:1234", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"forwarded", "10.0.0.1:1234", map[string]string{
+			"Forwarded":       `for=192.0.2.60;proto=http, for="[2001:db8:cafe::17]:4711";by=10.0.0.1`,
+			"X-Forwarded-For": "203.0.113.7",
+		}, "2001:db8:cafe::17"},
+		{"forwarded port", "10.0.0.1:1234", map[string]string{"Forwarded": `for="198.51.100.1:443"`}, "198.51.100.1"},
+		{"forwarded unknown", "10.0.0.1:1234", map[string]string{"Forwarded": `for=unknown`}, "10.0.0.1"},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.remoteAddr
+		for k, v := range tc.headers {
+			req.Header.Set(k, v)
+		}
+
+		if ip := client.RealIP(req); ip != tc.expected {
+			t.Errorf("Unexpected IP in case %q: %v (expected %v)", tc.name, ip, tc.expected)
+		}
+	}
+
+	// headers are ignored without trusted proxies
+	untrusted, _ := NewClient(Configuration{APIKey: "test-api-key"})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	if ip := untrusted.RealIP(req); ip != "10.0.0.1" {
+		t.Errorf("Unexpected IP without trusted proxies: %v", ip)
+	}
+}
+
+func TestTrustedProxiesInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, invalid := range []string{"10.0.0.0/33", "not-an-ip", ""} {
+		if _, err := NewClient(Configuration{APIKey: "test-api-key", TrustedProxies: []string{invalid}}); !errors.Is(err, errTrustedProxy) {
+			t.Errorf("Unexpected error for %q: %v", invalid, err)
+		}
+	}
+}
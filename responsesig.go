@@ -0,0 +1,198 @@
+package privatecaptcha
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	// DefaultResponseSignatureHeader is the default header with the signature of verify response
+	DefaultResponseSignatureHeader = "X-PC-Signature"
+	// DefaultResponseNonceHeader is the default header with the random nonce of verify request, which is
+	// signed together with the response, so that signed responses cannot be replayed
+	DefaultResponseNonceHeader = "X-PC-Nonce"
+)
+
+var (
+	// ErrResponseSignature is returned when verify response signature is missing or invalid
+	ErrResponseSignature = errors.New("privatecaptcha: verify response signature is invalid")
+	errNoResponseKeys    = errors.New("privatecaptcha: no response signature keys")
+)
+
+// SignatureFormat defines how the verify response is signed
+type SignatureFormat int
+
+const (
+	// SignatureHMAC is hex-encoded HMAC-SHA256 in the header, optionally prefixed with key ID ("<kid>:<hex>")
+	SignatureHMAC SignatureFormat = iota
+	// SignatureJWS is JWS with detached payload and HS256 algorithm ("<protected>..<signature>", RFC 7515 Appendix F)
+	SignatureJWS
+)
+
+// ResponseSignature configures validation of verify responses signed by self-hosted deployments, so that
+// a compromised proxy between the backend and the API cannot forge successful verifications. Signed payload
+// is "<nonce>\n<response body>", where nonce is sent by the client in NonceHeader with every verify attempt.
+type ResponseSignature struct {
+	// (required) Keys accepted for validation (more than one during key rotation)
+	Keys []SigningKey
+	// (optional) Format of the signature (defaults to SignatureHMAC)
+	Format SignatureFormat
+	// (optional) Response header with the signature (defaults to DefaultResponseSignatureHeader)
+	Header string
+	// (optional) Request header with the nonce (defaults to DefaultResponseNonceHeader)
+	NonceHeader string
+}
+
+func (s *ResponseSignature) header() string {
+	if len(s.Header) > 0 {
+		return s.Header
+	}
+
+	return DefaultResponseSignatureHeader
+}
+
+func (s *ResponseSignature) nonceHeader() string {
+	if len(s.NonceHeader) > 0 {
+		return s.NonceHeader
+	}
+
+	return DefaultResponseNonceHeader
+}
+
+// newNonce adds a random nonce to the verify request and returns it
+func (s *ResponseSignature) newNonce(header http.Header) string {
+	nonce := rand.Text()
+	header.Set(s.nonceHeader(), nonce)
+	return nonce
+}
+
+// keys returns keys to check the signature with (all keys if kid is empty)
+func (s *ResponseSignature) keys(kid string) []SigningKey {
+	if len(kid) == 0 {
+		return s.Keys
+	}
+
+	for _, key := range s.Keys {
+		if key.ID == kid {
+			return []SigningKey{key}
+		}
+	}
+
+	return nil
+}
+
+func responseMAC(key []byte, parts ...string) []byte {
+	mac := hmac.New(sha256.New, key)
+	for i, part := range parts {
+		if i > 0 {
+			mac.Write([]byte("."))
+		}
+		mac.Write([]byte(part))
+	}
+	return mac.Sum(nil)
+}
+
+func (s *ResponseSignature) verifyHMAC(signature string, payload string) error {
+	var kid string
+	if i := strings.LastIndexByte(signature, ':'); i != -1 {
+		kid, signature = signature[:i], signature[i+1:]
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrResponseSignature
+	}
+
+	for _, key := range s.keys(kid) {
+		if hmac.Equal(expected, responseMAC(key.Key, payload)) {
+			return nil
+		}
+	}
+
+	return ErrResponseSignature
+}
+
+func (s *ResponseSignature) verifyJWS(signature string, payload string) error {
+	protected, sig, ok := strings.Cut(signature, "..")
+	if !ok {
+		return ErrResponseSignature
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(protected)
+	if err != nil {
+		return ErrResponseSignature
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(data, &header); (err != nil) || (header.Alg != "HS256") {
+		return ErrResponseSignature
+	}
+
+	expected, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return ErrResponseSignature
+	}
+
+	encodedPayload := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	for _, key := range s.keys(header.Kid) {
+		if hmac.Equal(expected, responseMAC(key.Key, protected, encodedPayload)) {
+			return nil
+		}
+	}
+
+	return ErrResponseSignature
+}
+
+// verify checks signature of the verify response body
+func (s *ResponseSignature) verify(header http.Header, nonce string, body []byte) error {
+	signature := header.Get(s.header())
+	if len(signature) == 0 {
+		return ErrResponseSignature
+	}
+
+	payload := nonce + "\n" + string(body)
+
+	if s.Format == SignatureJWS {
+		return s.verifyJWS(signature, payload)
+	}
+
+	return s.verifyHMAC(signature, payload)
+}
+
+// Sign returns the signature of the verify response body with the first of Keys. It is intended for self-hosted
+// deployments (or a trusted proxy next to them) that sign responses for clients with ResponseSignature configured.
+func (s *ResponseSignature) Sign(nonce string, body []byte) (string, error) {
+	if len(s.Keys) == 0 {
+		return "", errNoResponseKeys
+	}
+
+	key := s.Keys[0]
+	payload := nonce + "\n" + string(body)
+
+	if s.Format == SignatureJWS {
+		data, err := json.Marshal(map[string]string{"alg": "HS256", "kid": key.ID})
+		if err != nil {
+			return "", err
+		}
+		protected := base64.RawURLEncoding.EncodeToString(data)
+		mac := responseMAC(key.Key, protected, base64.RawURLEncoding.EncodeToString([]byte(payload)))
+		return protected + ".." + base64.RawURLEncoding.EncodeToString(mac), nil
+	}
+
+	signature := hex.EncodeToString(responseMAC(key.Key, payload))
+	if len(key.ID) > 0 {
+		signature = key.ID + ":" + signature
+	}
+
+	return signature, nil
+}
@@ -0,0 +1,81 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestResponseSignature(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"success":true,"code":0}`)
+	current := SigningKey{ID: "k2", Key: []byte("current-key")}
+	previous := SigningKey{ID: "k1", Key: []byte("previous-key")}
+
+	for _, format := range []SignatureFormat{SignatureHMAC, SignatureJWS} {
+		signer := &ResponseSignature{Keys: []SigningKey{previous}, Format: format}
+		validator := &ResponseSignature{Keys: []SigningKey{current, previous}, Format: format}
+
+		signature, err := signer.Sign("nonce", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		header := http.Header{}
+		header.Set(DefaultResponseSignatureHeader, signature)
+
+		if err := validator.verify(header, "nonce", body); err != nil {
+			t.Errorf("Unexpected error (format %v): %v", format, err)
+		}
+
+		if err := validator.verify(header, "other-nonce", body); err != ErrResponseSignature {
+			t.Errorf("Unexpected error for replayed response (format %v): %v", format, err)
+		}
+
+		if err := validator.verify(header, "nonce", []byte(`{"success":false,"code":0}`)); err != ErrResponseSignature {
+			t.Errorf("Unexpected error for forged response (format %v): %v", format, err)
+		}
+
+		if err := validator.verify(http.Header{}, "nonce", body); err != ErrResponseSignature {
+			t.Errorf("Unexpected error for missing signature (format %v): %v", format, err)
+		}
+
+		unknown := &ResponseSignature{Keys: []SigningKey{current}, Format: format}
+		if err := unknown.verify(header, "nonce", body); err != ErrResponseSignature {
+			t.Errorf("Unexpected error for unknown key (format %v): %v", format, err)
+		}
+	}
+}
+
+func TestClientResponseSignature(t *testing.T) {
+	t.Parallel()
+
+	signature := &ResponseSignature{Keys: []SigningKey{{Key: []byte("secret")}}}
+	body := []byte(`{"success":true,"code":0}`)
+
+	var forge bool
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		nonce := r.Header.Get(DefaultResponseNonceHeader)
+		if forge {
+			nonce = "forged"
+		}
+		value, _ := signature.Sign(nonce, body)
+		w.Header().Set(DefaultResponseSignatureHeader, value)
+		w.Write(body)
+	}, Configuration{ResponseSignature: signature})
+
+	if output, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1}); (err != nil) || !output.OK() {
+		t.Fatalf("Unexpected result: %v", err)
+	}
+
+	forge = true
+	if _, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1}); !errors.Is(err, ErrResponseSignature) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if _, err := NewClient(Configuration{APIKey: "test-api-key", ResponseSignature: &ResponseSignature{}}); err != errNoResponseKeys {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package privatecaptcha
+
+import (
+	"sync"
+	"time"
+)
+
+// RetryBudget is a token bucket that limits the rate of retries (but not first attempts) of verify requests.
+// Share the same instance between all clients in the process to prevent retry amplification storms when
+// the API slows down for all concurrent handlers at once.
+type RetryBudget struct {
+	mu     sync.Mutex
+	tokens float64
+	burst  float64
+	rate   float64
+	last   time.Time
+}
+
+// NewRetryBudget creates a budget allowing ratePerSecond retries on average with bursts of up to burst retries
+func NewRetryBudget(ratePerSecond float64, burst int) *RetryBudget {
+	return &RetryBudget{
+		tokens: float64(burst),
+		burst:  float64(burst),
+		rate:   ratePerSecond,
+		last:   time.Now(),
+	}
+}
+
+// Allow consumes a token if retry is allowed
+func (b *RetryBudget) Allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// Available returns the number of retries that can be made right now
+func (b *RetryBudget) Available() int {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return int(min(b.burst, b.tokens+time.Since(b.last).Seconds()*b.rate))
+}
@@ -0,0 +1,40 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRetryBudget(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	budget := NewRetryBudget(0, 2)
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, Configuration{RetryBudget: budget})
+
+	input := VerifyInput{Solution: "asdf", Attempts: 5, MaxBackoffSeconds: 1}
+
+	if _, err := client.Verify(context.TODO(), input); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	// first attempt + 2 retries from the budget
+	if requests.Load() != 3 {
+		t.Errorf("Unexpected number of requests: %v", requests.Load())
+	}
+
+	if _, err := client.Verify(context.TODO(), input); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	// budget is exhausted: only first attempt
+	if requests.Load() != 4 {
+		t.Errorf("Unexpected number of requests: %v", requests.Load())
+	}
+}
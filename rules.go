@@ -0,0 +1,279 @@
+package privatecaptcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync/atomic"
+)
+
+// RuleAction is what RulesMiddleware does with requests matching the rule (also returned by PolicyModulator)
+type RuleAction string
+
+const (
+	// ActionEnforce verifies requests and rejects those that failed verification
+	ActionEnforce RuleAction = "enforce"
+	// ActionMonitor verifies requests, but passes those that failed verification through (see FailureMonitor)
+	ActionMonitor RuleAction = "monitor"
+	// ActionSkip passes requests through without verification
+	ActionSkip RuleAction = "skip"
+)
+
+var (
+	errRuleAction = errors.New("privatecaptcha: unknown rule action")
+	errRuleStatus = errors.New("privatecaptcha: rule status must be a client or server error status")
+)
+
+// Rule selects requests by path, method and headers. All set predicates have to match.
+type Rule struct {
+	// (optional) Name of the rule for logs
+	Name string `json:"name,omitempty"`
+	// (optional) Glob of request path in path.Match syntax, where trailing "/**" matches the path and any subpath
+	// (request paths are cleaned, so trailing slashes, duplicate slashes and dot segments do not matter),
+	// e.g. "/api/*/signup" or "/auth/**" (matches any path if empty)
+	Path string `json:"path,omitempty"`
+	// (optional) Request methods, e.g. ["POST", "PUT"] (matches any method if empty)
+	Methods []string `json:"methods,omitempty"`
+	// (optional) Request headers with value globs in path.Match syntax, empty value only requires header to be present,
+	// e.g. {"Content-Type": "application/*"}
+	Headers map[string]string `json:"headers,omitempty"`
+	// (required) Action for matching requests
+	Action RuleAction `json:"action"`
+	// (optional) http status to return for failed verifications with ActionEnforce (defaults to MiddlewareOptions.FailedStatusCode)
+	Status int `json:"status,omitempty"`
+}
+
+// RulesConfig is a declarative set of rules, e.g. loaded from JSON configuration file
+type RulesConfig struct {
+	// (optional) Action for requests that do not match any rule (defaults to ActionSkip)
+	Default RuleAction `json:"default,omitempty"`
+	// (optional) Rules evaluated in order, the first matching rule wins
+	Rules []Rule `json:"rules"`
+}
+
+func (a RuleAction) valid() bool {
+	switch a {
+	case ActionEnforce, ActionMonitor, ActionSkip:
+		return true
+	default:
+		return false
+	}
+}
+
+// cleanPath normalizes request path, so that rules cannot be bypassed with equivalent paths (e.g. "/signup/",
+// "//signup" or "/a/../signup" for "/signup")
+func cleanPath(p string) string {
+	if len(p) == 0 {
+		return "/"
+	}
+
+	return path.Clean("/" + p)
+}
+
+// matchPath matches path segment by segment, trailing "**" segment matches the rest of the path
+func matchPath(pattern, p string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+
+	patterns := strings.Split(pattern, "/")
+	segments := strings.Split(p, "/")
+
+	for i, sp := range patterns {
+		if (sp == "**") && (i == len(patterns)-1) {
+			return true
+		}
+
+		if i >= len(segments) {
+			return false
+		}
+
+		if ok, _ := path.Match(sp, segments[i]); !ok {
+			return false
+		}
+	}
+
+	return len(patterns) == len(segments)
+}
+
+func (rule *Rule) validate() error {
+	if !rule.Action.valid() {
+		return fmt.Errorf("%w %q in rule %q", errRuleAction, rule.Action, rule.Name)
+	}
+
+	if (rule.Status != 0) && ((rule.Status < 400) || (rule.Status > 599)) {
+		return fmt.Errorf("%w (%d in rule %q)", errRuleStatus, rule.Status, rule.Name)
+	}
+
+	for _, pattern := range strings.Split(rule.Path, "/") {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("privatecaptcha: invalid path %q of rule %q: %w", rule.Path, rule.Name, err)
+		}
+	}
+
+	for name, pattern := range rule.Headers {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("privatecaptcha: invalid header %q of rule %q: %w", name, rule.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (rule *Rule) matches(r *http.Request) bool {
+	if (len(rule.Methods) > 0) && !containsFold(rule.Methods, r.Method) {
+		return false
+	}
+
+	if !matchPath(rule.Path, cleanPath(r.URL.Path)) {
+		return false
+	}
+
+	for name, pattern := range rule.Headers {
+		values := r.Header.Values(name)
+		if len(values) == 0 {
+			return false
+		}
+
+		if len(pattern) == 0 {
+			continue
+		}
+
+		if ok, _ := path.Match(pattern, values[0]); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RuleEngine evaluates RulesConfig for requests, rules can be replaced at runtime (e.g. when configuration file changes)
+type RuleEngine struct {
+	config atomic.Pointer[RulesConfig]
+}
+
+// NewRuleEngine validates rules and creates RuleEngine with them
+func NewRuleEngine(cfg RulesConfig) (*RuleEngine, error) {
+	e := &RuleEngine{}
+	if err := e.Update(cfg); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Update atomically replaces the rules, invalid rules are rejected and the current ones are kept
+func (e *RuleEngine) Update(cfg RulesConfig) error {
+	if len(cfg.Default) == 0 {
+		cfg.Default = ActionSkip
+	} else if !cfg.Default.valid() {
+		return fmt.Errorf("%w %q", errRuleAction, cfg.Default)
+	}
+
+	rules := make([]Rule, len(cfg.Rules))
+	for i, rule := range cfg.Rules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+		if len(rule.Path) > 0 {
+			rule.Path = cleanPath(rule.Path)
+		}
+		rules[i] = rule
+	}
+	cfg.Rules = rules
+
+	e.config.Store(&cfg)
+
+	return nil
+}
+
+// Load reads RulesConfig in JSON format and replaces the rules with Update()
+func (e *RuleEngine) Load(r io.Reader) error {
+	var cfg RulesConfig
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return err
+	}
+
+	return e.Update(cfg)
+}
+
+// Match returns the first rule matching the request or a rule with the default action if none matched
+func (e *RuleEngine) Match(r *http.Request) (Rule, bool) {
+	cfg := e.config.Load()
+
+	for i := range cfg.Rules {
+		if cfg.Rules[i].matches(r) {
+			return cfg.Rules[i], true
+		}
+	}
+
+	return Rule{Action: cfg.Default}, false
+}
+
+type ruleContextKey struct{}
+
+func ruleFromContext(ctx context.Context) (Rule, bool) {
+	rule, ok := ctx.Value(ruleContextKey{}).(Rule)
+	return rule, ok
+}
+
+// RulesMiddleware creates a single http middleware protecting all endpoints according to the rules, instead of
+// wrapping every handler with its own Middleware. Requests are verified with Middleware(opts).
+func (c *Client) RulesMiddleware(engine *RuleEngine, opts MiddlewareOptions) func(http.Handler) http.Handler {
+	resolver := opts.TenantResolver
+	opts.TenantResolver = func(r *http.Request) TenantConfig {
+		var tenant TenantConfig
+		if resolver != nil {
+			tenant = resolver(r)
+		}
+
+		if rule, ok := ruleFromContext(r.Context()); ok && (rule.Status != 0) {
+			tenant.FailedStatusCode = rule.Status
+		}
+
+		return tenant
+	}
+
+	middleware := c.Middleware(opts)
+
+	return func(next http.Handler) http.Handler {
+		verified := middleware(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rule, matched := engine.Match(r)
+			ctx := r.Context()
+
+			c.logger.Log(ctx, levelTrace, "Evaluated protection rules", "rule", rule.Name, "matched", matched, "action", string(rule.Action))
+
+			switch rule.Action {
+			case ActionSkip:
+				next.ServeHTTP(w, r)
+				return
+			case ActionMonitor:
+				ctx = WithFailurePolicy(ctx, FailureMonitor)
+			case ActionEnforce:
+				ctx = WithFailurePolicy(ctx, FailureReject)
+			}
+
+			verified.ServeHTTP(w, r.WithContext(context.WithValue(ctx, ruleContextKey{}, rule)))
+		})
+	}
+}
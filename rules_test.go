@@ -0,0 +1,205 @@
+package privatecaptcha
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		pattern string
+		path    string
+		matches bool
+	}{
+		{"", "/anything", true},
+		{"/signup", "/signup", true},
+		{"/signup", "/signup/", false},
+		{"/api/*/signup", "/api/v1/signup", true},
+		{"/api/*/signup", "/api/v1/v2/signup", false},
+		{"/auth/**", "/auth", true},
+		{"/auth/**", "/auth/", true},
+		{"/auth/**", "/auth/login/otp", true},
+		{"/auth/**", "/authz/login", false},
+	}
+
+	for _, tc := range testCases {
+		if matchPath(tc.pattern, tc.path) != tc.matches {
+			t.Errorf("Unexpected match of %q with %q: expected %v", tc.path, tc.pattern, tc.matches)
+		}
+	}
+}
+
+func TestRulesMiddleware(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := io.ReadAll(r.Body); string(body) != "good" {
+			w.Write([]byte(`{"success":false,"code":3}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{})
+
+	engine, err := NewRuleEngine(RulesConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const config = `{
+		"default": "skip",
+		"rules": [
+			{"name": "health", "path": "/healthz", "action": "skip"},
+			{"name": "signup", "path": "/signup", "methods": ["POST"], "action": "enforce", "status": 429},
+			{"name": "api", "path": "/api/**", "headers": {"X-Client": "beta-*"}, "action": "monitor"},
+			{"name": "auth", "path": "/auth/**", "action": "enforce"}
+		]
+	}`
+	if err := engine.Load(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := client.RulesMiddleware(engine, MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		method   string
+		path     string
+		header   string
+		solution string
+		status   int
+	}{
+		{http.MethodPost, "/healthz", "", "", http.StatusOK},
+		{http.MethodGet, "/signup", "", "", http.StatusOK},
+		{http.MethodPost, "/signup", "", "bad", http.StatusTooManyRequests},
+		{http.MethodPost, "/signup", "", "good", http.StatusOK},
+		{http.MethodPost, "/api/v1/orders", "beta-1", "bad", http.StatusOK},
+		{http.MethodPost, "/api/v1/orders", "", "bad", http.StatusOK},
+		{http.MethodPost, "/auth/login", "", "bad", http.StatusForbidden},
+		{http.MethodPost, "/other", "", "", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(DefaultFormField+"="+tc.solution))
+		req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+		if len(tc.header) > 0 {
+			req.Header.Set("X-Client", tc.header)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tc.status {
+			t.Errorf("Unexpected status of %v %v: %v (expected %v)", tc.method, tc.path, w.Code, tc.status)
+		}
+	}
+
+	// rules are reloaded atomically and invalid updates are rejected
+	if err := engine.Load(strings.NewReader(`{"default": "enforce", "rules": [{"path": "/x", "action": "block"}]}`)); !errors.Is(err, errRuleAction) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := engine.Update(RulesConfig{Default: ActionEnforce}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Unexpected status after reload: %v", w.Code)
+	}
+}
+
+func TestRulesPathBypass(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":false,"code":3}`))
+	}, Configuration{})
+
+	engine, err := NewRuleEngine(RulesConfig{Rules: []Rule{
+		{Path: "/signup", Action: ActionEnforce},
+		{Path: "/auth/**/", Action: ActionEnforce},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// enforce rules are not downgraded by monitor-only middleware
+	handler := client.RulesMiddleware(engine, MiddlewareOptions{FailurePolicy: FailureMonitor})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, p := range []string{"/signup", "/signup/", "//signup", "/a/../signup", "/./signup", "/auth", "/auth//login/"} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(DefaultFormField+"=bad"))
+		req.URL.Path = p
+		req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Unexpected status of %v: %v", p, w.Code)
+		}
+	}
+}
+
+func TestRulesMaintenanceWindow(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, Configuration{})
+
+	engine, err := NewRuleEngine(RulesConfig{Rules: []Rule{{Path: "/signup", Action: ActionEnforce}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := client.RulesMiddleware(engine, MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(DefaultFormField+"=asdf"))
+		req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	now := time.Now()
+	client.AddMaintenanceWindow(MaintenanceWindow{Start: now.Add(-time.Minute), End: now.Add(time.Hour)})
+	if code := serve(); code != http.StatusForbidden {
+		t.Errorf("Unexpected status during maintenance without policy: %v", code)
+	}
+
+	// maintenance policy is not overridden by enforcing rules
+	client.AddMaintenanceWindow(MaintenanceWindow{Start: now.Add(-time.Minute), End: now.Add(time.Hour), FailurePolicy: FailureMonitor})
+	if code := serve(); code != http.StatusOK {
+		t.Errorf("Unexpected status during maintenance with monitor policy: %v", code)
+	}
+}
+
+func TestRuleValidation(t *testing.T) {
+	t.Parallel()
+
+	invalid := []Rule{
+		{Action: "block"},
+		{Action: ActionEnforce, Status: 200},
+		{Action: ActionEnforce, Path: "/[a"},
+		{Action: ActionEnforce, Headers: map[string]string{"X-A": "[a"}},
+	}
+
+	for _, rule := range invalid {
+		if _, err := NewRuleEngine(RulesConfig{Rules: []Rule{rule}}); err == nil {
+			t.Errorf("Expected error for rule %+v", rule)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxSolutions is the default limit of solutions verified per request (see Configuration.MaxSolutions)
+const DefaultMaxSolutions = 4
+
+// ErrTooManySolutions is returned when request contains more solutions than Configuration.MaxSolutions
+var ErrTooManySolutions = errors.New("privatecaptcha: too many solutions")
+
+// SolutionsPolicy defines how VerifyRequest treats multiple solutions sent in the same form field,
+// e.g. from pages with several protected forms
+type SolutionsPolicy int
+
+const (
+	// SolutionsFirst verifies only the first solution (default)
+	SolutionsFirst SolutionsPolicy = iota
+	// SolutionsAll requires all solutions to be verified successfully
+	SolutionsAll
+	// SolutionsAny requires at least one solution to be verified successfully
+	SolutionsAny
+)
+
+// MultiVerifyError reports aggregated result of verifying multiple solutions from one request
+type MultiVerifyError struct {
+	Policy SolutionsPolicy
+	Total  int
+	Errors []error
+}
+
+func (e *MultiVerifyError) Error() string {
+	return fmt.Sprintf("privatecaptcha: %d of %d solutions failed verification", len(e.Errors), e.Total)
+}
+
+func (e *MultiVerifyError) Unwrap() []error {
+	return e.Errors
+}
+
+func (c *Client) verifySolutions(ctx context.Context, solutions []string) error {
+	if len(solutions) > c.maxSolutions {
+		c.logger.Log(ctx, levelTrace, "Too many solutions in request", "count", len(solutions), "max", c.maxSolutions)
+		return ErrTooManySolutions
+	}
+
+	var errs []error
+
+	for _, solution := range solutions {
+		err := c.verifySolution(ctx, solution)
+		if err == nil {
+			if c.solutionsPolicy == SolutionsAny {
+				return nil
+			}
+			continue
+		}
+
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &MultiVerifyError{Policy: c.solutionsPolicy, Total: len(solutions), Errors: errs}
+}
@@ -0,0 +1,81 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func newSolutionsClient(t *testing.T, policy SolutionsPolicy) *Client {
+	return newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "good" {
+			w.Write([]byte(`{"success":true,"code":0}`))
+		} else {
+			w.Write([]byte(`{"success":false,"code":3}`))
+		}
+	}, Configuration{SolutionsPolicy: policy})
+}
+
+func TestMultipleSolutionsPolicy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.TODO(), traceIDContextKey, t.Name())
+
+	testCases := []struct {
+		policy    SolutionsPolicy
+		solutions []string
+		failed    int
+	}{
+		{SolutionsFirst, []string{"good", "bad"}, 0},
+		{SolutionsAll, []string{"good", "good"}, 0},
+		{SolutionsAll, []string{"good", "bad", "bad"}, 2},
+		{SolutionsAny, []string{"bad", "good"}, 0},
+		{SolutionsAny, []string{"bad", "bad"}, 2},
+	}
+
+	for i, tc := range testCases {
+		client := newSolutionsClient(t, tc.policy)
+
+		req := httptest.NewRequest(http.MethodPost, "/test", nil)
+		req.PostForm = url.Values{DefaultFormField: tc.solutions}
+
+		err := client.VerifyRequest(ctx, req)
+		if tc.failed == 0 {
+			if err != nil {
+				t.Errorf("Unexpected error in test case %d: %v", i, err)
+			}
+			continue
+		}
+
+		var multiErr *MultiVerifyError
+		if !errors.As(err, &multiErr) {
+			t.Fatalf("Unexpected error type in test case %d: %v", i, err)
+		}
+
+		if (len(multiErr.Errors) != tc.failed) || (multiErr.Total != len(tc.solutions)) {
+			t.Errorf("Unexpected aggregated result in test case %d: %v", i, multiErr)
+		}
+	}
+}
+
+func TestMaxSolutions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.TODO(), traceIDContextKey, t.Name())
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Request should not be sent")
+	}, Configuration{SolutionsPolicy: SolutionsAny, MaxSolutions: 2})
+
+	req := httptest.NewRequest(http.MethodPost, "/test", nil)
+	req.PostForm = url.Values{DefaultFormField: []string{"a", "b", "c"}}
+
+	if err := client.VerifyRequest(ctx, req); err != ErrTooManySolutions {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultStandbyCheckInterval is how often standby health is checked in background and how long the result
+	// of the check is reused
+	DefaultStandbyCheckInterval = 10 * time.Second
+)
+
+var (
+	errNoStandby = errors.New("privatecaptcha: standby endpoint is not configured")
+)
+
+// StandbyHealthCheck checks that the verify endpoint is able to serve requests
+type StandbyHealthCheck func(ctx context.Context, client Doer, endpoint string) error
+
+// defaultStandbyHealthCheck considers endpoint healthy if it responds to HEAD request without server error
+func defaultStandbyHealthCheck(ctx context.Context, client Doer, endpoint string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(headerUserAgent, userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return HTTPError{StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// standby is a warm standby verify endpoint (e.g. "green" deployment of self-hosted version during blue/green upgrade)
+type standby struct {
+	check       StandbyHealthCheck
+	interval    time.Duration
+	autoPromote bool
+	mu          sync.Mutex
+	endpoint    string
+	checkedAt   time.Time
+	lastErr     error
+	// probing is closed when the health check in progress finishes, so that concurrent callers share it
+	probing chan struct{}
+}
+
+// health returns the result of the last health check of standby endpoint, rechecking it when it's stale
+func (s *standby) health(ctx context.Context, client Doer) error {
+	s.mu.Lock()
+	if !s.checkedAt.IsZero() && (time.Since(s.checkedAt) < s.interval) {
+		defer s.mu.Unlock()
+		return s.lastErr
+	}
+	s.mu.Unlock()
+
+	return s.probe(ctx, client)
+}
+
+// probe checks health of standby endpoint without holding the lock during the network call. If the check is already
+// in progress, its result is awaited instead of starting another one.
+func (s *standby) probe(ctx context.Context, client Doer) error {
+	s.mu.Lock()
+	if probing := s.probing; probing != nil {
+		s.mu.Unlock()
+
+		select {
+		case <-probing:
+		case <-ctx.Done():
+			return contextError(ctx)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.lastErr
+	}
+
+	probing := make(chan struct{})
+	s.probing = probing
+	endpoint := s.endpoint
+	s.mu.Unlock()
+
+	err := s.check(ctx, client, endpoint)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// standby could be swapped with the current endpoint while checking
+	if s.endpoint == endpoint {
+		s.lastErr = err
+		s.checkedAt = time.Now()
+	}
+	s.probing = nil
+	close(probing)
+
+	return err
+}
+
+// runStandbyChecks periodically checks health of standby endpoint until ctx is done, so that its health is known
+// before it's promoted
+func (c *Client) runStandbyChecks(ctx context.Context) {
+	ticker := time.NewTicker(c.standby.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.standby.probe(ctx, c.client); (err != nil) && (ctx.Err() == nil) {
+			c.logger.Log(ctx, levelTrace, "Standby endpoint is not healthy", "endpoint", c.standby.current(), errAttr(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *standby) current() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.endpoint
+}
+
+// status returns the result of the last health check of standby endpoint
+func (s *standby) status() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.checkedAt.IsZero():
+		return "unknown"
+	case s.lastErr != nil:
+		return "failing"
+	default:
+		return "ok"
+	}
+}
+
+func newStandby(cfg *Configuration) *standby {
+	s := &standby{
+		check:       cfg.StandbyHealthCheck,
+		interval:    cfg.StandbyCheckInterval,
+		autoPromote: cfg.AutoPromoteStandby,
+		endpoint:    fmt.Sprintf("https://%s%s", strings.Trim(trimScheme(cfg.StandbyDomain), "/"), cfg.VerifyPath),
+	}
+
+	if s.check == nil {
+		s.check = defaultStandbyHealthCheck
+	}
+
+	if s.interval <= 0 {
+		s.interval = DefaultStandbyCheckInterval
+	}
+
+	return s
+}
+
+// Promote switches the client to the standby endpoint, while the current endpoint becomes the standby one
+// (so it can be promoted back if needed). Health of the standby endpoint is checked before switching.
+func (c *Client) Promote(ctx context.Context) error {
+	return c.promote(ctx, "")
+}
+
+// promote switches to standby endpoint. If from is not empty, endpoint is only switched if it's still current,
+// so that concurrent failures of the same endpoint promote standby only once.
+func (c *Client) promote(ctx context.Context, from string) error {
+	if c.standby == nil {
+		return errNoStandby
+	}
+
+	if err := c.standby.health(ctx, c.client); err != nil {
+		return fmt.Errorf("privatecaptcha: standby endpoint is not healthy: %w", err)
+	}
+
+	c.standby.mu.Lock()
+	defer c.standby.mu.Unlock()
+
+	current, next := c.Endpoint(), c.standby.endpoint
+	if (len(from) > 0) && (from != current) {
+		return nil
+	}
+
+	c.endpoint.Store(&next)
+	c.standby.endpoint = current
+	// health of the former primary endpoint is unknown
+	c.standby.checkedAt = time.Time{}
+
+	c.logger.Log(ctx, levelTrace, "Promoted standby endpoint", "endpoint", c.Endpoint(), "standby", current)
+
+	return nil
+}
+
+// verifyPrimary verifies solution with the current endpoint, promoting standby endpoint on hard failures
+// if it is configured with AutoPromoteStandby
+func (c *Client) verifyPrimary(ctx context.Context, input VerifyInput) (*VerifyOutput, error) {
+	endpoint := c.Endpoint()
+	output, err := c.verifyScoped(ctx, input)
+	if (c.standby == nil) || !c.standby.autoPromote || !isHardFailure(ctx, err) {
+		return output, err
+	}
+
+	if perr := c.promote(ctx, endpoint); perr != nil {
+		c.logger.Log(ctx, levelTrace, "Failed to promote standby endpoint", errAttr(perr))
+		return output, err
+	}
+
+	return c.verifyScoped(ctx, input)
+}
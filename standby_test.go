@@ -0,0 +1,141 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStandbyAutoPromote(t *testing.T) {
+	t.Parallel()
+
+	var standbyCalls atomic.Int32
+	standbySrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			standbyCalls.Add(1)
+			w.Write([]byte(`{"success":true,"code":0}`))
+		}
+	}))
+	t.Cleanup(standbySrv.Close)
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, Configuration{StandbyDomain: standbySrv.URL, AutoPromoteStandby: true})
+
+	primary := client.Endpoint()
+
+	output, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1})
+	if err != nil || !output.OK() {
+		t.Fatalf("Unexpected result: %v (%v)", output.Error(), err)
+	}
+
+	if client.Endpoint() != standbySrv.URL+"/verify" {
+		t.Errorf("Unexpected endpoint after promotion: %v", client.Endpoint())
+	}
+
+	if standbyCalls.Load() != 1 {
+		t.Errorf("Unexpected standby calls: %v", standbyCalls.Load())
+	}
+
+	// former primary is now the standby and it's unhealthy
+	if err := client.Promote(context.TODO()); err == nil {
+		t.Error("Unhealthy standby should not be promoted")
+	}
+
+	if client.standby.endpoint != primary {
+		t.Errorf("Unexpected standby endpoint: %v", client.standby.endpoint)
+	}
+}
+
+func TestPromoteWithoutStandby(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(Configuration{APIKey: "test-api-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Promote(context.TODO()); err != errNoStandby {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestStandbyBackgroundChecks(t *testing.T) {
+	t.Parallel()
+
+	checks := make(chan struct{}, 10)
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{
+		StandbyDomain:        "standby.example.com",
+		StandbyCheckInterval: 10 * time.Millisecond,
+		StandbyHealthCheck: func(ctx context.Context, client Doer, endpoint string) error {
+			select {
+			case checks <- struct{}{}:
+			default:
+			}
+			return nil
+		},
+	})
+
+	if err := client.Start(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	// health is checked periodically without verifications or promotions
+	for i := 0; i < 2; i++ {
+		select {
+		case <-checks:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Standby health was not checked in background")
+		}
+	}
+
+	if err := client.Close(context.TODO()); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestStandbyConcurrentChecks(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	s := newStandby(&Configuration{
+		StandbyDomain: "standby.example.com",
+		StandbyHealthCheck: func(ctx context.Context, client Doer, endpoint string) error {
+			if calls.Add(1) == 1 {
+				close(started)
+			}
+			<-release
+			return errNoStandby
+		},
+	})
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			errs <- s.health(context.TODO(), nil)
+		}()
+	}
+
+	<-started
+	// the lock is not held during the check
+	if s.current() != "https://standby.example.com" {
+		t.Errorf("Unexpected endpoint: %v", s.current())
+	}
+	close(release)
+
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != errNoStandby {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("Unexpected number of health checks: %v", calls.Load())
+	}
+}
@@ -0,0 +1,138 @@
+package privatecaptcha
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultStatsWindow is the default window of verification statistics, see Client.Stats()
+	DefaultStatsWindow = 5 * time.Minute
+	// DefaultSuccessRateMinSamples is the default number of verifications required to evaluate success rate threshold
+	DefaultSuccessRateMinSamples = 20
+)
+
+// Stats contains verification statistics of the client over a sliding window. It is intended for metrics
+// exporters (e.g. as Prometheus GaugeFunc) and dashboards.
+type Stats struct {
+	Window time.Duration
+	// Succeeded is the number of successful verifications (VerifyOutput.ReportedOK())
+	Succeeded int
+	// Failed is the number of unsuccessful verifications (e.g. invalid or expired solutions)
+	Failed int
+	// Errors is the number of verifications that could not be done (e.g. API is unavailable)
+	Errors int
+	// SuccessRate is Succeeded / (Succeeded + Failed), it is 1 if there were no verifications
+	SuccessRate float64
+}
+
+// SuccessRateInfo describes success rate that dropped below Configuration.SuccessRateThreshold
+type SuccessRateInfo struct {
+	Stats
+	Threshold float64
+}
+
+type statsBucket struct {
+	succeeded int
+	failed    int
+	errors    int
+}
+
+// successTracker aggregates verification results over a sliding window
+type successTracker struct {
+	threshold  float64
+	minSamples int
+	callback   func(ctx context.Context, info SuccessRateInfo)
+	mu         sync.Mutex
+	window     *slidingWindow[statsBucket]
+	// below is true while success rate is below threshold, so that callback is called once per drop
+	below bool
+}
+
+func newSuccessTracker(cfg *Configuration) *successTracker {
+	window := cfg.StatsWindow
+	if window <= 0 {
+		window = DefaultStatsWindow
+	}
+
+	minSamples := cfg.SuccessRateMinSamples
+	if minSamples <= 0 {
+		minSamples = DefaultSuccessRateMinSamples
+	}
+
+	return &successTracker{
+		threshold:  cfg.SuccessRateThreshold,
+		minSamples: minSamples,
+		callback:   cfg.OnLowSuccessRate,
+		window:     newSlidingWindow[statsBucket](window),
+	}
+}
+
+func (t *successTracker) stats(now time.Time) Stats {
+	stats := Stats{Window: t.window.window()}
+	t.window.each(now, func(b *statsBucket) {
+		stats.Succeeded += b.succeeded
+		stats.Failed += b.failed
+		stats.Errors += b.errors
+	})
+
+	stats.SuccessRate = 1
+	if total := stats.Succeeded + stats.Failed; total > 0 {
+		stats.SuccessRate = float64(stats.Succeeded) / float64(total)
+	}
+
+	return stats
+}
+
+// record accounts verification result and returns the info if success rate dropped below threshold
+func (t *successTracker) record(output *VerifyOutput, err error, now time.Time) (SuccessRateInfo, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := t.window.current(now)
+	switch {
+	case err != nil:
+		b.errors++
+	case output.ReportedOK():
+		b.succeeded++
+	default:
+		b.failed++
+	}
+
+	if (t.callback == nil) || (t.threshold <= 0) {
+		return SuccessRateInfo{}, false
+	}
+
+	stats := t.stats(now)
+	if stats.Succeeded+stats.Failed < t.minSamples {
+		return SuccessRateInfo{}, false
+	}
+
+	if stats.SuccessRate >= t.threshold {
+		t.below = false
+		return SuccessRateInfo{}, false
+	}
+
+	if t.below {
+		return SuccessRateInfo{}, false
+	}
+
+	t.below = true
+
+	return SuccessRateInfo{Stats: stats, Threshold: t.threshold}, true
+}
+
+func (c *Client) trackSuccess(ctx context.Context, output *VerifyOutput, err error) {
+	if info, ok := c.success.record(output, err, time.Now()); ok {
+		c.success.callback(ctx, info)
+	}
+}
+
+// Stats returns verification statistics over the last Configuration.StatsWindow
+func (c *Client) Stats() Stats {
+	c.success.mu.Lock()
+	defer c.success.mu.Unlock()
+
+	return c.success.stats(time.Now())
+}
@@ -0,0 +1,91 @@
+package privatecaptcha
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSuccessTracker(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	tr := newSuccessTracker(&Configuration{
+		StatsWindow:           10 * time.Second,
+		SuccessRateThreshold:  0.5,
+		SuccessRateMinSamples: 4,
+		OnLowSuccessRate:      func(ctx context.Context, info SuccessRateInfo) { calls++ },
+	})
+	now := time.Now()
+
+	ok := &VerifyOutput{Success: true, Code: VerifyNoError}
+	failed := &VerifyOutput{Code: VerifiedBeforeError}
+
+	// not enough samples (errors are not counted)
+	for i, output := range []*VerifyOutput{failed, failed, failed, nil} {
+		var err error
+		if output == nil {
+			err = errors.New("network error")
+		}
+		if _, fired := tr.record(output, err, now); fired {
+			t.Fatalf("Unexpected callback before min samples (%v)", i)
+		}
+	}
+
+	info, fired := tr.record(ok, nil, now.Add(time.Second))
+	if !fired {
+		t.Fatal("Expected callback after success rate dropped")
+	}
+
+	if (info.Succeeded != 1) || (info.Failed != 3) || (info.Errors != 1) || (info.SuccessRate != 0.25) || (info.Threshold != 0.5) {
+		t.Errorf("Unexpected info: %+v", info)
+	}
+
+	// callback is called once per drop
+	if _, fired := tr.record(failed, nil, now.Add(2*time.Second)); fired {
+		t.Error("Unexpected repeated callback")
+	}
+
+	// recovery re-arms the callback
+	for i := 0; i < 10; i++ {
+		tr.record(ok, nil, now.Add(3*time.Second))
+	}
+	for i := 0; i < 20; i++ {
+		if _, fired = tr.record(failed, nil, now.Add(4*time.Second)); fired {
+			break
+		}
+	}
+	if !fired {
+		t.Error("Expected callback after recovery and another drop")
+	}
+
+	// old results slide out of the window
+	if stats := tr.stats(now.Add(30 * time.Second)); (stats.Succeeded+stats.Failed+stats.Errors != 0) || (stats.SuccessRate != 1) {
+		t.Errorf("Unexpected stats after window: %+v", stats)
+	}
+}
+
+func TestClientStats(t *testing.T) {
+	t.Parallel()
+
+	var fail bool
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.Write([]byte(`{"success":false,"code":3}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{})
+
+	input := VerifyInput{Solution: "asdf", Attempts: 1}
+	client.Verify(context.TODO(), input)
+	fail = true
+	client.Verify(context.TODO(), input)
+
+	stats := client.Stats()
+	if (stats.Window != DefaultStatsWindow) || (stats.Succeeded != 1) || (stats.Failed != 1) || (stats.SuccessRate != 0.5) {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,112 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// TenantConfig is the verification configuration of a single tenant (e.g. a customer of a white-label platform)
+type TenantConfig struct {
+	// (optional) Client used to verify solutions of the tenant, created with the tenant API key, policies and
+	// failure handling (Fallback etc.) (defaults to the client of the middleware)
+	Client *Client
+	// (optional) Sitekey of the tenant property, sent with verify requests
+	Sitekey string
+	// (optional) http status to return for failed verifications of the tenant (defaults to MiddlewareOptions.FailedStatusCode)
+	FailedStatusCode int
+	// (optional) Do not verify requests of the tenant (e.g. captcha is turned off in tenant settings), such requests
+	// are passed through without being marked as verified (see IsVerified)
+	Disabled bool
+}
+
+// TenantResolver selects tenant configuration for the request, zero TenantConfig means middleware defaults
+type TenantResolver func(r *http.Request) TenantConfig
+
+// TenantKeyFunc returns the key of the request tenant in TenantRegistry
+type TenantKeyFunc func(r *http.Request) string
+
+// TenantByHost uses request host (without port, lower-cased) as the tenant key
+func TenantByHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.ToLower(host)
+}
+
+// TenantRegistry is a concurrency-safe set of tenant configurations, which can be updated at runtime
+// (e.g. when tenants are added or rotate their API keys). Use its Resolve method as MiddlewareOptions.TenantResolver.
+type TenantRegistry struct {
+	key     TenantKeyFunc
+	mu      sync.RWMutex
+	tenants map[string]TenantConfig
+}
+
+// NewTenantRegistry creates an empty registry, key selects tenant of the request (defaults to TenantByHost)
+func NewTenantRegistry(key TenantKeyFunc) *TenantRegistry {
+	if key == nil {
+		key = TenantByHost
+	}
+
+	return &TenantRegistry{key: key, tenants: make(map[string]TenantConfig)}
+}
+
+// Set adds or replaces configuration of the tenant
+func (tr *TenantRegistry) Set(tenant string, cfg TenantConfig) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.tenants[tenant] = cfg
+}
+
+// Delete removes configuration of the tenant, its requests use middleware defaults afterwards
+func (tr *TenantRegistry) Delete(tenant string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	delete(tr.tenants, tenant)
+}
+
+// Replace atomically replaces all tenant configurations (e.g. after reloading them from the database)
+func (tr *TenantRegistry) Replace(tenants map[string]TenantConfig) {
+	copied := make(map[string]TenantConfig, len(tenants))
+	for k, v := range tenants {
+		copied[k] = v
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	tr.tenants = copied
+}
+
+// Get returns configuration of the tenant
+func (tr *TenantRegistry) Get(tenant string) (TenantConfig, bool) {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	cfg, ok := tr.tenants[tenant]
+	return cfg, ok
+}
+
+// Resolve implements TenantResolver, unknown tenants get zero TenantConfig
+func (tr *TenantRegistry) Resolve(r *http.Request) TenantConfig {
+	cfg, _ := tr.Get(tr.key(r))
+	return cfg
+}
+
+type sitekeyContextKey struct{}
+
+// withSitekey sets sitekey for verifications made from within middleware (e.g. of the tenant)
+func withSitekey(ctx context.Context, sitekey string) context.Context {
+	return context.WithValue(ctx, sitekeyContextKey{}, sitekey)
+}
+
+func sitekeyFromContext(ctx context.Context) string {
+	sitekey, _ := ctx.Value(sitekeyContextKey{}).(string)
+	return sitekey
+}
@@ -0,0 +1,63 @@
+package privatecaptcha
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newTenantClient(t *testing.T, apiKey string) *Client {
+	return newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		ok := (r.Header.Get(headerApiKey) == apiKey) && (r.Header.Get(headerSitekey) == apiKey+"-sitekey")
+		json.NewEncoder(w).Encode(&VerifyOutput{Success: ok})
+	}, Configuration{APIKey: apiKey})
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	t.Parallel()
+
+	registry := NewTenantRegistry(nil)
+	registry.Set("a.example.com", TenantConfig{Client: newTenantClient(t, "a"), Sitekey: "a-sitekey"})
+	registry.Set("b.example.com", TenantConfig{Client: newTenantClient(t, "b"), Sitekey: "wrong", FailedStatusCode: http.StatusTeapot})
+	registry.Set("c.example.com", TenantConfig{Disabled: true})
+
+	// requests of unknown tenants are verified by the default client, which always fails
+	defaultClient := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&VerifyOutput{Success: false})
+	}, Configuration{})
+
+	handler := defaultClient.Middleware(MiddlewareOptions{TenantResolver: registry.Resolve})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	testCases := []struct {
+		host   string
+		status int
+	}{
+		{"a.example.com", http.StatusOK},
+		{"A.example.com:8443", http.StatusOK},
+		{"b.example.com", http.StatusTeapot},
+		{"c.example.com", http.StatusOK},
+		{"d.example.com", http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		form := url.Values{DefaultFormField: []string{"solution"}}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+		req.Host = tc.host
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		if recorder.Code != tc.status {
+			t.Errorf("Unexpected status %v for host %v (expected %v)", recorder.Code, tc.host, tc.status)
+		}
+	}
+
+	registry.Replace(map[string]TenantConfig{"d.example.com": {Disabled: true}})
+	if _, ok := registry.Get("a.example.com"); ok {
+		t.Error("Unexpected tenant after replace")
+	}
+}
@@ -0,0 +1,146 @@
+package privatecaptcha
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// IPFamily is the address family used to connect to the API
+type IPFamily int
+
+const (
+	// IPFamilyAny connects over IPv4 or IPv6, racing them as described in RFC 6555 ("Happy Eyeballs")
+	IPFamilyAny IPFamily = iota
+	// IPFamilyIPv4 connects only over IPv4
+	IPFamilyIPv4
+	// IPFamilyIPv6 connects only over IPv6
+	IPFamilyIPv6
+)
+
+func (f IPFamily) String() string {
+	switch f {
+	case IPFamilyIPv4:
+		return "ipv4"
+	case IPFamilyIPv6:
+		return "ipv6"
+	default:
+		return "any"
+	}
+}
+
+// network restricts dialed network (e.g. "tcp") to the address family
+func (f IPFamily) network(network string) string {
+	if network != "tcp" {
+		return network
+	}
+
+	switch f {
+	case IPFamilyIPv4:
+		return "tcp4"
+	case IPFamilyIPv6:
+		return "tcp6"
+	default:
+		return network
+	}
+}
+
+// ProxySelector chooses a proxy for the verify request. Returning nil URL means direct connection.
+// Supported schemes are the same as for http.Transport (http, https and socks5).
+type ProxySelector func(ctx context.Context, r *http.Request) (*url.URL, error)
+
+var (
+	errNoCertificates      = errors.New("privatecaptcha: no certificates found")
+	errTransportWithClient = errors.New("privatecaptcha: transport options cannot be used with custom http.Client")
+	errDoerWithClient      = errors.New("privatecaptcha: Doer cannot be used together with http.Client")
+)
+
+// fipsCipherSuites are TLS 1.2 cipher suites approved by FIPS 140 (TLS 1.3 suites are not configurable)
+var fipsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+var fipsCurves = []tls.CurveID{tls.CurveP256, tls.CurveP384}
+
+func (cfg *Configuration) hasTransportOptions() bool {
+	return (cfg.TLSMinVersion != 0) || (len(cfg.TLSCipherSuites) > 0) || cfg.TLSPreferFIPS ||
+		(cfg.ProxySelector != nil) || (cfg.IPFamily != IPFamilyAny) || (cfg.DialFallbackDelay != 0) ||
+		(cfg.RootCAs != nil)
+}
+
+func (cfg *Configuration) tlsConfig() *tls.Config {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cfg.TLSMinVersion != 0 {
+		tlsConfig.MinVersion = cfg.TLSMinVersion
+	}
+
+	if cfg.TLSPreferFIPS {
+		tlsConfig.CipherSuites = fipsCipherSuites
+		tlsConfig.CurvePreferences = fipsCurves
+	}
+
+	if len(cfg.TLSCipherSuites) > 0 {
+		tlsConfig.CipherSuites = cfg.TLSCipherSuites
+	}
+
+	if cfg.RootCAs != nil {
+		tlsConfig.RootCAs = cfg.RootCAs
+	}
+
+	return tlsConfig
+}
+
+// doer returns HTTP client of the configuration, Doer takes precedence over Client
+func (cfg *Configuration) doer() Doer {
+	if cfg.Doer != nil {
+		return cfg.Doer
+	}
+
+	return cfg.Client
+}
+
+// newDefaultClient creates http.Client owned by the SDK, configured with transport options
+func newDefaultClient(cfg *Configuration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = cfg.tlsConfig()
+
+	setDialer(transport, cfg)
+
+	if selector := cfg.ProxySelector; selector != nil {
+		transport.Proxy = func(r *http.Request) (*url.URL, error) {
+			return selector(r.Context(), r)
+		}
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+// LoadRootCAs loads PEM-encoded root certificates (e.g. a CA bundle mounted into a scratch or distroless container,
+// which lacks system certificates) to use as Configuration.RootCAs. To embed Mozilla roots into the binary instead,
+// import contrib/mozillaroots.
+func LoadRootCAs(files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("%w in %s", errNoCertificates, file)
+		}
+	}
+
+	return pool, nil
+}
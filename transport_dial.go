@@ -0,0 +1,29 @@
+//go:build !js && !wasip1
+
+package privatecaptcha
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	// same as net/http.DefaultTransport
+	dialTimeout   = 30 * time.Second
+	dialKeepAlive = 30 * time.Second
+)
+
+// setDialer configures dialer of the transport with Configuration.IPFamily and Configuration.DialFallbackDelay
+func setDialer(transport *http.Transport, cfg *Configuration) {
+	dialer := &net.Dialer{
+		Timeout:       dialTimeout,
+		KeepAlive:     dialKeepAlive,
+		FallbackDelay: cfg.DialFallbackDelay,
+	}
+	family := cfg.IPFamily
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, family.network(network), addr)
+	}
+}
@@ -0,0 +1,166 @@
+package privatecaptcha
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTLSPolicy(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(Configuration{
+		APIKey:        "test-api-key",
+		TLSMinVersion: tls.VersionTLS13,
+		TLSPreferFIPS: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport, ok := client.client.(*http.Client).Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Unexpected transport type: %T", client.client.(*http.Client).Transport)
+	}
+
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Unexpected min TLS version: %v", transport.TLSClientConfig.MinVersion)
+	}
+
+	if len(transport.TLSClientConfig.CipherSuites) != len(fipsCipherSuites) {
+		t.Errorf("Unexpected cipher suites: %v", transport.TLSClientConfig.CipherSuites)
+	}
+
+	if _, err := NewClient(Configuration{
+		APIKey:        "test-api-key",
+		Client:        &http.Client{},
+		TLSMinVersion: tls.VersionTLS13,
+	}); err != errTransportWithClient {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestProxySelector(t *testing.T) {
+	t.Parallel()
+
+	type ctxKey struct{}
+	const tenant = "tenant-a"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// plain HTTP proxy receives CONNECT for https targets
+		if r.Method != http.MethodConnect {
+			t.Errorf("Unexpected proxy request method: %v", r.Method)
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	proxyURL, _ := url.Parse(srv.URL)
+
+	var selected atomic.Bool
+	client, err := NewClient(Configuration{
+		APIKey: "test-api-key",
+		ProxySelector: func(ctx context.Context, r *http.Request) (*url.URL, error) {
+			if ctx.Value(ctxKey{}) == tenant {
+				selected.Store(true)
+				return proxyURL, nil
+			}
+			return nil, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.TODO(), ctxKey{}, tenant)
+	if _, err := client.Verify(ctx, VerifyInput{Solution: "asdf", Attempts: 1}); err == nil {
+		t.Error("Expected proxy error")
+	}
+
+	if !selected.Load() {
+		t.Error("Proxy selector was not called")
+	}
+}
+
+func TestIPFamily(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	testCases := []struct {
+		family IPFamily
+		ok     bool
+	}{
+		{IPFamilyIPv4, true},
+		{IPFamilyIPv6, false},
+	}
+
+	for _, tc := range testCases {
+		client, err := NewClient(Configuration{APIKey: "test-api-key", IPFamily: tc.family, DialFallbackDelay: -1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		transport := client.client.(*http.Client).Transport.(*http.Transport)
+		conn, err := transport.DialContext(context.TODO(), "tcp", listener.Addr().String())
+		if conn != nil {
+			conn.Close()
+		}
+
+		if (err == nil) != tc.ok {
+			t.Errorf("Unexpected dial result for %v: %v", tc.family, err)
+		}
+	}
+}
+
+func TestRootCAs(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}))
+	defer srv.Close()
+
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := LoadRootCAs(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// default http client trusts the test server only with loaded roots
+	client, err := NewClient(Configuration{APIKey: "test-api-key", Domain: srv.URL, RootCAs: pool})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if output, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf", Attempts: 1}); (err != nil) || !output.OK() {
+		t.Errorf("Unexpected result: %v", err)
+	}
+
+	if _, err := LoadRootCAs(filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(empty, []byte("not a certificate"), 0o600)
+	if _, err := LoadRootCAs(empty); !errors.Is(err, errNoCertificates) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build js || wasip1
+
+package privatecaptcha
+
+import (
+	"net/http"
+)
+
+// setDialer keeps the default dialer: on js/wasm, http.Transport uses Fetch API only when no custom dialer is set,
+// and on wasip1 there are no sockets (configure Configuration.Doer backed by the host runtime instead), so
+// Configuration.IPFamily and Configuration.DialFallbackDelay are ignored.
+func setDialer(transport *http.Transport, cfg *Configuration) {}
@@ -0,0 +1,166 @@
+package privatecaptcha
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TransportStats contains connection-level statistics of verify requests, useful for sizing
+// http.Transport (e.g. MaxIdleConnsPerHost) for the observed load
+type TransportStats struct {
+	// Requests is the total number of verify requests sent
+	Requests int64
+	// InFlight is the number of verify requests in progress right now
+	InFlight int64
+	// MaxInFlight is the maximum observed concurrency. MaxIdleConnsPerHost lower than this value
+	// causes connections to be closed and re-established under load
+	MaxInFlight int64
+	// NewConns is the number of newly established connections
+	NewConns int64
+	// ReusedConns is the number of requests that reused a pooled connection
+	ReusedConns int64
+	// TLSHandshakes is the number of completed TLS handshakes
+	TLSHandshakes int64
+	// DNSLookups is the number of DNS lookups
+	DNSLookups int64
+	// DNSTime is the total time spent in DNS lookups
+	DNSTime time.Duration
+	// ConnectTime is the total time spent establishing TCP connections
+	ConnectTime time.Duration
+	// TLSTime is the total time spent in TLS handshakes
+	TLSTime time.Duration
+}
+
+type transportStats struct {
+	requests     atomic.Int64
+	inFlight     atomic.Int64
+	maxInFlight  atomic.Int64
+	newConns     atomic.Int64
+	reusedConns  atomic.Int64
+	handshakes   atomic.Int64
+	dnsLookups   atomic.Int64
+	dnsNanos     atomic.Int64
+	connectNanos atomic.Int64
+	tlsNanos     atomic.Int64
+}
+
+func (s *transportStats) begin() {
+	s.requests.Add(1)
+	current := s.inFlight.Add(1)
+	for {
+		maxValue := s.maxInFlight.Load()
+		if (current <= maxValue) || s.maxInFlight.CompareAndSwap(maxValue, current) {
+			break
+		}
+	}
+}
+
+func (s *transportStats) end() {
+	s.inFlight.Add(-1)
+}
+
+func (s *transportStats) snapshot() TransportStats {
+	return TransportStats{
+		Requests:      s.requests.Load(),
+		InFlight:      s.inFlight.Load(),
+		MaxInFlight:   s.maxInFlight.Load(),
+		NewConns:      s.newConns.Load(),
+		ReusedConns:   s.reusedConns.Load(),
+		TLSHandshakes: s.handshakes.Load(),
+		DNSLookups:    s.dnsLookups.Load(),
+		DNSTime:       time.Duration(s.dnsNanos.Load()),
+		ConnectTime:   time.Duration(s.connectNanos.Load()),
+		TLSTime:       time.Duration(s.tlsNanos.Load()),
+	}
+}
+
+// Timings contains per-phase durations of a single verify attempt. Phases that did not happen
+// (e.g. DNS lookup and TLS handshake for a reused connection) are zero.
+type Timings struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	// TTFB is the time from the start of the request until the first response byte
+	TTFB time.Duration
+}
+
+// requestTrace collects timings of a single verify request
+type requestTrace struct {
+	stats *transportStats
+	// with dual-stack dialing connection callbacks can be invoked concurrently
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	timings      Timings
+}
+
+func (rt *requestTrace) result() Timings {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	return rt.timings
+}
+
+func (s *transportStats) withTrace(ctx context.Context) (context.Context, *requestTrace) {
+	rt := &requestTrace{stats: s, start: time.Now()}
+
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				rt.stats.reusedConns.Add(1)
+			} else {
+				rt.stats.newConns.Add(1)
+			}
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			rt.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			duration := time.Since(rt.dnsStart)
+			rt.stats.dnsLookups.Add(1)
+			rt.stats.dnsNanos.Add(int64(duration))
+			rt.mu.Lock()
+			rt.timings.DNS = duration
+			rt.mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			rt.mu.Lock()
+			rt.connectStart = time.Now()
+			rt.mu.Unlock()
+		},
+		ConnectDone: func(string, string, error) {
+			rt.mu.Lock()
+			duration := time.Since(rt.connectStart)
+			rt.stats.connectNanos.Add(int64(duration))
+			rt.timings.Connect = duration
+			rt.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			rt.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			duration := time.Since(rt.tlsStart)
+			rt.stats.handshakes.Add(1)
+			rt.stats.tlsNanos.Add(int64(duration))
+			rt.mu.Lock()
+			rt.timings.TLS = duration
+			rt.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			rt.mu.Lock()
+			rt.timings.TTFB = time.Since(rt.start)
+			rt.mu.Unlock()
+		},
+	}), rt
+}
+
+// TransportStats returns connection-level statistics of verify requests sent by this client
+func (c *Client) TransportStats() TransportStats {
+	return c.stats.snapshot()
+}
@@ -0,0 +1,62 @@
+package privatecaptcha
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestTransportStats(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{})
+
+	const requests = 3
+	for i := 0; i < requests; i++ {
+		if _, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := client.TransportStats()
+	if (stats.Requests != requests) || (stats.InFlight != 0) || (stats.MaxInFlight != 1) {
+		t.Errorf("Unexpected request stats: %+v", stats)
+	}
+
+	if (stats.NewConns != 1) || (stats.ReusedConns != requests-1) || (stats.TLSHandshakes != 1) {
+		t.Errorf("Unexpected connection stats: %+v", stats)
+	}
+}
+
+func TestTraceTimings(t *testing.T) {
+	t.Parallel()
+
+	var results []*VerifyOutput
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"code":0}`))
+	}, Configuration{
+		TraceTimings: true,
+		OnResult: func(ctx context.Context, output *VerifyOutput, err error) {
+			results = append(results, output)
+		},
+	})
+
+	if _, err := client.Verify(context.TODO(), VerifyInput{Solution: "asdf"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("Unexpected number of results: %v", len(results))
+	}
+
+	timings, ok := results[0].Timings()
+	if !ok {
+		t.Fatal("Timings were not recorded")
+	}
+
+	if (timings.Connect <= 0) || (timings.TLS <= 0) || (timings.TTFB < timings.TLS) {
+		t.Errorf("Unexpected timings: %+v", timings)
+	}
+}
@@ -0,0 +1,161 @@
+package privatecaptcha
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWidgetURL is the URL of Private Captcha widget script
+	DefaultWidgetURL = "https://cdn.privatecaptcha.com/widget/js/privatecaptcha.js"
+	// DefaultWidgetTTL is the default period after which widget script is fetched again by WidgetProxy
+	DefaultWidgetTTL = 1 * time.Hour
+	// maxWidgetSize limits the size of widget script
+	maxWidgetSize = 4 << 20
+)
+
+var (
+	errWidgetTooLarge = errors.New("privatecaptcha: widget script is too large")
+)
+
+// WidgetProxyOptions configures WidgetProxy
+type WidgetProxyOptions struct {
+	// (optional) URL of the widget script (defaults to DefaultWidgetURL)
+	URL string
+	// (optional) How long the fetched script is served before it is fetched again (defaults to DefaultWidgetTTL),
+	// it is also max-age of Cache-Control sent to browsers
+	TTL time.Duration
+}
+
+// widgetAsset is the cached widget script
+type widgetAsset struct {
+	data        []byte
+	etag        string
+	contentType string
+	modified    time.Time
+	fetched     time.Time
+}
+
+type widgetProxy struct {
+	client *Client
+	url    string
+	ttl    time.Duration
+	mu     sync.Mutex
+	asset  *widgetAsset
+}
+
+func (p *widgetProxy) fetch(ctx context.Context) (*widgetAsset, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set(headerUserAgent, userAgent)
+
+	resp, err := p.client.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, HTTPError{StatusCode: resp.StatusCode, TraceID: resp.Header.Get(headerTraceID)}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxWidgetSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxWidgetSize {
+		return nil, errWidgetTooLarge
+	}
+
+	hash := sha256.Sum256(data)
+	asset := &widgetAsset{
+		data:        data,
+		etag:        `"` + base64.RawURLEncoding.EncodeToString(hash[:16]) + `"`,
+		contentType: resp.Header.Get(headerContentType),
+		fetched:     time.Now(),
+	}
+
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		asset.modified = modified
+	}
+
+	if len(asset.contentType) == 0 {
+		asset.contentType = "text/javascript; charset=utf-8"
+	}
+
+	return asset, nil
+}
+
+// get returns the cached script, fetching it if it's stale. Stale script is served if it can't be fetched.
+func (p *widgetProxy) get(ctx context.Context) (*widgetAsset, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if (p.asset != nil) && (time.Since(p.asset.fetched) < p.ttl) {
+		return p.asset, nil
+	}
+
+	asset, err := p.fetch(ctx)
+	if err != nil {
+		p.client.logger.Log(ctx, levelTrace, "Failed to fetch widget script", "url", p.url, errAttr(err))
+		if p.asset != nil {
+			return p.asset, nil
+		}
+		return nil, err
+	}
+
+	p.client.logger.Log(ctx, levelTrace, "Fetched widget script", "url", p.url, "size", len(asset.data), "etag", asset.etag)
+	p.asset = asset
+
+	return asset, nil
+}
+
+// WidgetProxy returns http.Handler that serves the widget script from the application origin, so that sites with
+// "script-src 'self'" CSP can embed the widget. Script is fetched from the CDN and cached in memory, and browsers
+// get ETag and Cache-Control headers for conditional requests.
+func (c *Client) WidgetProxy(opts WidgetProxyOptions) http.Handler {
+	p := &widgetProxy{client: c, url: opts.URL, ttl: opts.TTL}
+	if len(p.url) == 0 {
+		p.url = DefaultWidgetURL
+	}
+
+	if p.ttl <= 0 {
+		p.ttl = DefaultWidgetTTL
+	}
+
+	cacheControl := "public, max-age=" + strconv.Itoa(int(p.ttl.Seconds()))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (r.Method != http.MethodGet) && (r.Method != http.MethodHead) {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		asset, err := p.get(r.Context())
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set(headerContentType, asset.contentType)
+		w.Header().Set("ETag", asset.etag)
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		// handles If-None-Match, If-Modified-Since and HEAD requests
+		http.ServeContent(w, r, "", asset.modified, bytes.NewReader(asset.data))
+	})
+}
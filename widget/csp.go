@@ -0,0 +1,128 @@
+package widget
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+const (
+	cspSelf = "'self'"
+	cspNone = "'none'"
+)
+
+// PuzzleEndpoint returns puzzle endpoint of the API domain for Options.PuzzleEndpoint,
+// e.g. PuzzleEndpoint(privatecaptcha.EUDomain) or domain of the self-hosted deployment
+func PuzzleEndpoint(domain string) string {
+	domain = strings.TrimPrefix(domain, "https://")
+	domain = strings.TrimPrefix(domain, "http://")
+	return "https://" + strings.Trim(domain, "/") + "/puzzle"
+}
+
+// cspSource returns the source expression of the URL: 'self' for relative URLs and the origin otherwise
+func cspSource(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if (err != nil) || (len(u.Host) == 0) {
+		return cspSelf
+	}
+
+	scheme := u.Scheme
+	if len(scheme) == 0 {
+		scheme = "https"
+	}
+
+	return scheme + "://" + u.Host
+}
+
+type cspDirective struct {
+	name    string
+	sources []string
+}
+
+// cspDirectives returns sources required by the widget: its script, puzzle endpoint and frames
+func cspDirectives(opts Options) []cspDirective {
+	scriptURL := opts.ScriptURL
+	if len(scriptURL) == 0 {
+		scriptURL = pc.DefaultWidgetURL
+	}
+
+	puzzleEndpoint := opts.PuzzleEndpoint
+	if len(puzzleEndpoint) == 0 {
+		puzzleEndpoint = PuzzleEndpoint(pc.GlobalDomain)
+	}
+
+	script := cspSource(scriptURL)
+	scriptSources := []string{script}
+	if len(opts.Nonce) > 0 {
+		scriptSources = append(scriptSources, "'nonce-"+opts.Nonce+"'")
+	}
+
+	return []cspDirective{
+		{name: "script-src", sources: scriptSources},
+		{name: "connect-src", sources: []string{cspSource(puzzleEndpoint)}},
+		{name: "frame-src", sources: []string{script}},
+	}
+}
+
+// CSP returns Content-Security-Policy directives required by the widget rendered with the same options, e.g.
+// "script-src https://cdn.privatecaptcha.com; connect-src https://api.privatecaptcha.com; frame-src https://cdn.privatecaptcha.com".
+// Widget served with Client.WidgetProxy() and puzzles proxied with Client.PuzzleProxy() (relative ScriptURL and
+// PuzzleEndpoint) only require 'self'.
+func CSP(opts Options) string {
+	directives := cspDirectives(opts)
+
+	parts := make([]string, 0, len(directives))
+	for _, d := range directives {
+		parts = append(parts, d.name+" "+strings.Join(d.sources, " "))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// MergeCSP adds sources required by the widget to the existing Content-Security-Policy header value. Sources of
+// default-src are copied to the added directives, so that the rest of the page is not affected by them.
+func MergeCSP(policy string, opts Options) string {
+	var directives []cspDirective
+	for _, part := range strings.Split(policy, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+
+		directives = append(directives, cspDirective{name: strings.ToLower(fields[0]), sources: fields[1:]})
+	}
+
+	find := func(name string) int {
+		return slices.IndexFunc(directives, func(d cspDirective) bool { return d.name == name })
+	}
+
+	var defaultSources []string
+	if i := find("default-src"); i >= 0 {
+		defaultSources = directives[i].sources
+	}
+
+	for _, required := range cspDirectives(opts) {
+		i := find(required.name)
+		if i < 0 {
+			directives = append(directives, cspDirective{name: required.name, sources: slices.Clone(defaultSources)})
+			i = len(directives) - 1
+		}
+
+		d := &directives[i]
+		d.sources = slices.DeleteFunc(d.sources, func(s string) bool { return strings.EqualFold(s, cspNone) })
+		for _, s := range required.sources {
+			if !slices.Contains(d.sources, s) {
+				d.sources = append(d.sources, s)
+			}
+		}
+	}
+
+	parts := make([]string, 0, len(directives))
+	for _, d := range directives {
+		parts = append(parts, strings.Join(append([]string{d.name}, d.sources...), " "))
+	}
+
+	return strings.Join(parts, "; ")
+}
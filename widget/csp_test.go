@@ -0,0 +1,46 @@
+package widget
+
+import (
+	"testing"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+func TestCSP(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		opts     Options
+		expected string
+	}{
+		{Options{}, "script-src https://cdn.privatecaptcha.com; connect-src https://api.privatecaptcha.com; frame-src https://cdn.privatecaptcha.com"},
+		{Options{PuzzleEndpoint: PuzzleEndpoint(pc.EUDomain)}, "script-src https://cdn.privatecaptcha.com; connect-src https://api.eu.privatecaptcha.com; frame-src https://cdn.privatecaptcha.com"},
+		{Options{ScriptURL: "/widget.js", PuzzleEndpoint: "/puzzle", Nonce: "abc"}, "script-src 'self' 'nonce-abc'; connect-src 'self'; frame-src 'self'"},
+		{Options{ScriptURL: "https://captcha.example.com/widget.js", PuzzleEndpoint: PuzzleEndpoint("https://captcha.example.com/")}, "script-src https://captcha.example.com; connect-src https://captcha.example.com; frame-src https://captcha.example.com"},
+	}
+
+	for _, tc := range testCases {
+		if csp := CSP(tc.opts); csp != tc.expected {
+			t.Errorf("Unexpected CSP: %v (expected %v)", csp, tc.expected)
+		}
+	}
+}
+
+func TestMergeCSP(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		policy   string
+		expected string
+	}{
+		{"", "script-src https://cdn.privatecaptcha.com; connect-src https://api.privatecaptcha.com; frame-src https://cdn.privatecaptcha.com"},
+		{"default-src 'self'; img-src *", "default-src 'self'; img-src *; script-src 'self' https://cdn.privatecaptcha.com; connect-src 'self' https://api.privatecaptcha.com; frame-src 'self' https://cdn.privatecaptcha.com"},
+		{"Script-Src 'self' https://cdn.privatecaptcha.com; frame-src 'none';", "script-src 'self' https://cdn.privatecaptcha.com; frame-src https://cdn.privatecaptcha.com; connect-src https://api.privatecaptcha.com"},
+	}
+
+	for _, tc := range testCases {
+		if csp := MergeCSP(tc.policy, Options{}); csp != tc.expected {
+			t.Errorf("Unexpected CSP for %q: %v (expected %v)", tc.policy, csp, tc.expected)
+		}
+	}
+}
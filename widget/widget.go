@@ -0,0 +1,183 @@
+// Package widget renders the markup embedding Private Captcha widget in server-rendered html/template pages:
+//
+//	tmpl := template.New("page").Funcs(widget.FuncMap(widget.Options{Sitekey: sitekey}))
+//
+//	<head>{{ captchaScript "nonce" .Nonce }}</head>
+//	<form method="POST">
+//		...
+//		{{ captchaWidget "theme" "dark" "lang" .Lang }}
+//	</form>
+//
+// Script and Container can be used directly as well, e.g. with templ or other template engines. CSP and MergeCSP
+// return Content-Security-Policy directives required by the widget rendered with the same Options.
+package widget
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"maps"
+	"slices"
+	"strings"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+const (
+	// ContainerClass is the class of the element the widget is rendered into
+	ContainerClass = "private-captcha"
+)
+
+var (
+	errOddArguments = errors.New("widget: expected key-value pairs of arguments")
+)
+
+// Options are attributes of the widget markup
+type Options struct {
+	// (required) Sitekey of the property
+	Sitekey string
+	// (optional) Widget theme, e.g. "light" or "dark"
+	Theme string
+	// (optional) Widget language, e.g. "en"
+	Lang string
+	// (optional) When to start solving the puzzle, e.g. "auto" or "click"
+	StartMode string
+	// (optional) How the widget is displayed, e.g. "widget", "popup" or "hidden"
+	DisplayMode string
+	// (optional) Custom puzzle endpoint, e.g. path of Client.PuzzleProxy() handler
+	PuzzleEndpoint string
+	// (optional) Extra CSS classes of the container
+	Class string
+	// (optional) Extra attributes of the container, e.g. {"data-debug": "true"}
+	Attributes map[string]string
+	// (optional) URL of the widget script (defaults to privatecaptcha.DefaultWidgetURL), e.g. path of
+	// Client.WidgetProxy() handler
+	ScriptURL string
+	// (optional) CSP nonce of the script tag
+	Nonce string
+}
+
+func writeAttr(b *strings.Builder, name, value string) {
+	if len(value) == 0 {
+		return
+	}
+
+	b.WriteByte(' ')
+	b.WriteString(name)
+	b.WriteString(`="`)
+	b.WriteString(html.EscapeString(value))
+	b.WriteByte('"')
+}
+
+// Script returns the script tag loading the widget
+func Script(opts Options) template.HTML {
+	src := opts.ScriptURL
+	if len(src) == 0 {
+		src = pc.DefaultWidgetURL
+	}
+
+	var b strings.Builder
+	b.WriteString("<script defer")
+	writeAttr(&b, "src", src)
+	writeAttr(&b, "nonce", opts.Nonce)
+	b.WriteString("></script>")
+
+	return template.HTML(b.String())
+}
+
+// Container returns the element the widget is rendered into
+func Container(opts Options) template.HTML {
+	class := ContainerClass
+	if len(opts.Class) > 0 {
+		class += " " + opts.Class
+	}
+
+	var b strings.Builder
+	b.WriteString("<div")
+	writeAttr(&b, "class", class)
+	writeAttr(&b, "data-sitekey", opts.Sitekey)
+	writeAttr(&b, "data-theme", opts.Theme)
+	writeAttr(&b, "data-lang", opts.Lang)
+	writeAttr(&b, "data-start-mode", opts.StartMode)
+	writeAttr(&b, "data-display-mode", opts.DisplayMode)
+	writeAttr(&b, "data-puzzle-endpoint", opts.PuzzleEndpoint)
+	for _, name := range slices.Sorted(maps.Keys(opts.Attributes)) {
+		writeAttr(&b, name, opts.Attributes[name])
+	}
+	b.WriteString("></div>")
+
+	return template.HTML(b.String())
+}
+
+// Embed returns both the script tag and the container
+func Embed(opts Options) template.HTML {
+	return Script(opts) + Container(opts)
+}
+
+// withArgs overrides options with key-value pairs of template arguments, e.g. "theme" "dark"
+func withArgs(opts Options, args []string) (Options, error) {
+	if len(args)%2 != 0 {
+		return opts, errOddArguments
+	}
+
+	if opts.Attributes != nil {
+		opts.Attributes = maps.Clone(opts.Attributes)
+	}
+
+	for i := 0; i < len(args); i += 2 {
+		key, value := args[i], args[i+1]
+		switch key {
+		case "sitekey":
+			opts.Sitekey = value
+		case "theme":
+			opts.Theme = value
+		case "lang":
+			opts.Lang = value
+		case "start-mode":
+			opts.StartMode = value
+		case "display-mode":
+			opts.DisplayMode = value
+		case "puzzle-endpoint":
+			opts.PuzzleEndpoint = value
+		case "class":
+			opts.Class = value
+		case "src":
+			opts.ScriptURL = value
+		case "nonce":
+			opts.Nonce = value
+		default:
+			if !strings.HasPrefix(key, "data-") {
+				return opts, fmt.Errorf("widget: unknown argument %q", key)
+			}
+			if opts.Attributes == nil {
+				opts.Attributes = make(map[string]string)
+			}
+			opts.Attributes[key] = value
+		}
+	}
+
+	return opts, nil
+}
+
+// FuncMap returns template functions "captchaScript", "captchaWidget" and "captchaEmbed" rendering markup
+// with defaults, which can be overridden with key-value arguments: "sitekey", "theme", "lang", "start-mode",
+// "display-mode", "puzzle-endpoint", "class", "src", "nonce" or any "data-" attribute.
+func FuncMap(defaults Options) template.FuncMap {
+	render := func(fn func(Options) template.HTML) func(args ...string) (template.HTML, error) {
+		return func(args ...string) (template.HTML, error) {
+			opts, err := withArgs(defaults, args)
+			if err != nil {
+				return "", err
+			}
+
+			return fn(opts), nil
+		}
+	}
+
+	return template.FuncMap{
+		"captchaScript": render(Script),
+		"captchaWidget": render(Container),
+		"captchaEmbed":  render(Embed),
+	}
+}
@@ -0,0 +1,62 @@
+package widget
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	pc "github.com/PrivateCaptcha/private-captcha-go"
+)
+
+func TestContainer(t *testing.T) {
+	t.Parallel()
+
+	html := Container(Options{
+		Sitekey:    "sitekey",
+		Theme:      "dark",
+		Class:      "my-captcha",
+		Attributes: map[string]string{"data-debug": "true", "data-x": `"><script>`},
+	})
+
+	expected := `<div class="private-captcha my-captcha" data-sitekey="sitekey" data-theme="dark" data-debug="true" data-x="&#34;&gt;&lt;script&gt;"></div>`
+	if string(html) != expected {
+		t.Errorf("Unexpected container: %v", html)
+	}
+}
+
+func TestScript(t *testing.T) {
+	t.Parallel()
+
+	if html := Script(Options{}); string(html) != `<script defer src="`+pc.DefaultWidgetURL+`"></script>` {
+		t.Errorf("Unexpected script: %v", html)
+	}
+
+	if html := Script(Options{ScriptURL: "/widget.js", Nonce: "abc"}); string(html) != `<script defer src="/widget.js" nonce="abc"></script>` {
+		t.Errorf("Unexpected script with nonce: %v", html)
+	}
+}
+
+func TestFuncMap(t *testing.T) {
+	t.Parallel()
+
+	tmpl := template.Must(template.New("page").Funcs(FuncMap(Options{Sitekey: "sitekey", Lang: "en"})).Parse(
+		`<head>{{ captchaScript "nonce" .Nonce }}</head><form>{{ captchaWidget "theme" "dark" "data-debug" "true" }}</form>`))
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, map[string]string{"Nonce": "n0nce"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<head><script defer src="` + pc.DefaultWidgetURL + `" nonce="n0nce"></script></head>` +
+		`<form><div class="private-captcha" data-sitekey="sitekey" data-theme="dark" data-lang="en" data-debug="true"></div></form>`
+	if b.String() != expected {
+		t.Errorf("Unexpected output: %v", b.String())
+	}
+
+	for _, invalid := range []string{`{{ captchaWidget "theme" }}`, `{{ captchaWidget "onclick" "alert(1)" }}`} {
+		tmpl := template.Must(template.New("invalid").Funcs(FuncMap(Options{})).Parse(invalid))
+		if err := tmpl.Execute(&b, nil); err == nil {
+			t.Errorf("Expected error for %v", invalid)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package privatecaptcha
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWidgetProxy(t *testing.T) {
+	t.Parallel()
+
+	const script = `console.log("widget");`
+
+	var calls atomic.Int32
+	var failing atomic.Bool
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/widget.js" {
+			t.Errorf("Unexpected path: %v", r.URL.Path)
+		}
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set(headerContentType, "application/javascript")
+		w.Write([]byte(script))
+	}, Configuration{})
+
+	handler := client.WidgetProxy(WidgetProxyOptions{
+		URL: client.endpointFor("/widget.js"),
+		TTL: 100 * time.Millisecond,
+	})
+
+	serve := func(method, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/widget.js", nil)
+		if len(etag) > 0 {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodGet, "")
+	if (w.Code != http.StatusOK) || (w.Body.String() != script) {
+		t.Fatalf("Unexpected response: %v %q", w.Code, w.Body.String())
+	}
+
+	etag := w.Header().Get("ETag")
+	if (len(etag) == 0) || (w.Header().Get(headerContentType) != "application/javascript") || (len(w.Header().Get("Cache-Control")) == 0) {
+		t.Errorf("Unexpected headers: %v", w.Header())
+	}
+
+	if w := serve(http.MethodGet, etag); w.Code != http.StatusNotModified {
+		t.Errorf("Unexpected conditional response: %v", w.Code)
+	}
+
+	if w := serve(http.MethodHead, ""); (w.Code != http.StatusOK) || (w.Body.Len() != 0) {
+		t.Errorf("Unexpected HEAD response: %v", w.Code)
+	}
+
+	if w := serve(http.MethodPost, ""); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Unexpected POST response: %v", w.Code)
+	}
+
+	if calls.Load() != 1 {
+		t.Errorf("Unexpected upstream calls: %v", calls.Load())
+	}
+
+	// stale script is served if it can't be refreshed
+	failing.Store(true)
+	time.Sleep(150 * time.Millisecond)
+
+	if w := serve(http.MethodGet, ""); (w.Code != http.StatusOK) || (w.Body.String() != script) {
+		t.Errorf("Unexpected stale response: %v", w.Code)
+	}
+
+	if calls.Load() != 2 {
+		t.Errorf("Unexpected upstream calls after expiration: %v", calls.Load())
+	}
+}
+
+func TestWidgetProxyUnavailable(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}, Configuration{})
+
+	handler := client.WidgetProxy(WidgetProxyOptions{URL: client.endpointFor("/widget.js")})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/widget.js", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("Unexpected response: %v", w.Code)
+	}
+}
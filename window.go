@@ -0,0 +1,51 @@
+package privatecaptcha
+
+import (
+	"time"
+)
+
+// windowBuckets is the number of buckets of slidingWindow, window slides with the granularity of 1/windowBuckets
+const windowBuckets = 10
+
+type windowBucket[T any] struct {
+	start time.Time
+	value T
+}
+
+// slidingWindow aggregates values over the last window duration in fixed buckets, so that memory does not depend
+// on the number of recorded events. It is not safe for concurrent use.
+type slidingWindow[T any] struct {
+	width   time.Duration
+	buckets [windowBuckets]windowBucket[T]
+}
+
+func newSlidingWindow[T any](window time.Duration) *slidingWindow[T] {
+	return &slidingWindow[T]{width: max(window/windowBuckets, time.Millisecond)}
+}
+
+// window returns the duration covered by the window
+func (w *slidingWindow[T]) window() time.Duration {
+	return w.width * windowBuckets
+}
+
+// current returns the bucket for now, resetting it if it belongs to an expired period
+func (w *slidingWindow[T]) current(now time.Time) *T {
+	start := now.Truncate(w.width)
+	b := &w.buckets[(start.UnixNano()/int64(w.width))%windowBuckets]
+	if !b.start.Equal(start) {
+		var zero T
+		b.start, b.value = start, zero
+	}
+
+	return &b.value
+}
+
+// each calls fn for every bucket within the window ending at now
+func (w *slidingWindow[T]) each(now time.Time, fn func(value *T)) {
+	oldest := now.Truncate(w.width).Add(-w.width * (windowBuckets - 1))
+	for i := range w.buckets {
+		if b := &w.buckets[i]; !b.start.IsZero() && !b.start.Before(oldest) && !b.start.After(now) {
+			fn(&b.value)
+		}
+	}
+}